@@ -52,6 +52,27 @@ func allocateHostsFocused(h *TestHarness, name, description, vlanID string) {
 	if strings.TrimSpace(vlanID) != "" {
 		h.SelectDropdownOption("VLAN ID", strings.TrimSpace(vlanID))
 	}
+	h.PressTab() // Gateway
+	h.PressTab() // Excluded Ranges
+	h.PressTab() // DHCP Ranges
+	h.PressTab() // Save button
+	h.PressEnter()
+}
+
+// allocateHostPoolFocused allocates the focused network as a Host Pool with a
+// gateway, excluded ranges and DHCP ranges.
+func allocateHostPoolFocused(h *TestHarness, name, gateway, excludedRanges, dhcpRanges string) {
+	h.PressRune('A')
+	h.AssertScreenContains("Host Pool")
+	h.TypeText(name)
+	h.PressTab()
+	h.PressTab() // VLAN dropdown
+	h.PressTab()
+	h.TypeText(gateway)
+	h.PressTab()
+	h.TypeText(excludedRanges)
+	h.PressTab()
+	h.TypeText(dhcpRanges)
 	h.PressTab() // Save button
 	h.PressEnter()
 }
@@ -66,6 +87,11 @@ func updateAllocationFocused(h *TestHarness, nameSuffix, descriptionSuffix, vlan
 	if strings.TrimSpace(vlanID) != "" {
 		h.SelectDropdownOption("VLAN ID", strings.TrimSpace(vlanID))
 	}
+	if h.FocusedNetworkMode() == domain.AllocationModeHosts {
+		h.PressTab() // Gateway
+		h.PressTab() // Excluded Ranges
+		h.PressTab() // DHCP Ranges
+	}
 	h.PressTab() // Save button
 	h.PressEnter()
 }
@@ -288,15 +314,20 @@ func updateFocusedIPReservationWithMAC(h *TestHarness, nameSuffix, mac, descript
 	h.PressEnter()
 }
 
-func addDNSRecordViaDialog(h *TestHarness, fqdn, recordType, recordValue, description string) {
+// addDNSRecordViaDialog adds a record, typing fields into the typed form of
+// recordType in order.
+func addDNSRecordViaDialog(h *TestHarness, fqdn, recordType, description string, fields ...string) {
 	h.PressRune('r')
 	h.AssertScreenContains("Add DNS Record")
 	h.TypeText(fqdn)
 	h.PressTab() // Mode dropdown, default Record
 	h.PressTab() // Record Type
-	h.TypeText(recordType)
-	h.PressTab() // Record Value
-	h.TypeText(recordValue)
+	h.SelectDropdownOption("Record Type", recordType)
+	for _, field := range fields {
+		h.PressTab()
+		h.TypeText(field)
+	}
+	h.PressTab() // TTL
 	h.PressTab() // Description
 	h.TypeText(description)
 	h.PressTab() // Save button
@@ -311,7 +342,8 @@ func addDNSAliasViaDialog(h *TestHarness, fqdn, targetContains, description stri
 	h.SelectDropdownOption("Reserved IP", targetContains)
 	h.PressTab() // Mode
 	h.PressTab() // Reserved IP
-	h.PressTab() // Description
+	h.PressTab() // Also Reserved IP
+	h.PressTab() // TTL
 	h.TypeText(description)
 	for range 6 {
 		h.PressTab()
@@ -351,7 +383,7 @@ func TestVimNavigationAndKeysLineContext(t *testing.T) {
 	h := NewTestHarness(t)
 	h.NavigateToNetworks()
 	menuKeys, _ := h.CurrentKeys()
-	if len(menuKeys) != 1 || menuKeys[0] != "<n> New Network" {
+	if len(menuKeys) != 2 || menuKeys[0] != "<n> New Network" || menuKeys[1] != "<F> Free Space" {
 		t.Fatalf("unexpected menu keys: %#v", menuKeys)
 	}
 
@@ -473,6 +505,78 @@ func TestSplitNetworkBranches(t *testing.T) {
 		h.AssertStatusContains("Split network")
 		h.AssertScreenContains("10.0.0.0/26")
 	})
+
+	t.Run("mixed_sizes", func(t *testing.T) {
+		h := setup(t)
+		splitFocusedNetwork(h, "/26 /25 /27")
+		h.AssertStatusContains("Split network")
+		h.AssertScreenContains("10.0.0.0/25")
+		h.AssertScreenContains("10.0.0.128/26")
+		h.AssertScreenContains("10.0.0.192/27")
+		h.AssertScreenContains("10.0.0.224/27")
+	})
+
+	t.Run("mixed_host_counts", func(t *testing.T) {
+		h := setup(t)
+		splitFocusedNetwork(h, "100h 20h")
+		h.AssertStatusContains("Split network")
+		h.AssertScreenContains("10.0.0.0/25")
+		h.AssertScreenContains("10.0.0.128/27")
+		h.AssertScreenContains("10.0.0.192/26")
+	})
+
+	t.Run("mixed_sizes_do_not_fit", func(t *testing.T) {
+		h := setup(t)
+		splitFocusedNetwork(h, "25 25 26")
+		if !strings.Contains(h.CurrentStatusText(), "requested subnets need 320 addresses") {
+			t.Fatalf("expected capacity error, got %q", h.CurrentStatusText())
+		}
+	})
+}
+
+func TestLargeSplitLeavesFreeSpace(t *testing.T) {
+	t.Run("root_network", func(t *testing.T) {
+		h := NewTestHarness(t)
+		h.NavigateToNetworks()
+		addNetworkViaDialog(h, "10.0.0.0/16")
+		h.MoveFocusToID(t, "10.0.0.0/16")
+		splitFocusedNetwork(h, "28")
+		h.AssertStatusContains("into 4096")
+		h.MoveFocusToID(t, "10.0.0.0/28")
+		h.MoveFocusToID(t, "10.0.0.16/28")
+		h.MoveFocusToID(t, "10.0.128.0/17")
+
+		stored := make(chan int, 1)
+		h.App.TviewApp.QueueUpdateDraw(func() {
+			stored <- len(h.App.Catalog.GetChildren(h.App.CurrentItem))
+		})
+		if got := <-stored; got != 1 {
+			t.Fatalf("stored networks = %d, want only the split /16", got)
+		}
+	})
+
+	t.Run("subnet_container", func(t *testing.T) {
+		h := NewTestHarness(t)
+		h.NavigateToNetworks()
+		addNetworkViaDialog(h, "2001:db8::/48")
+		h.MoveFocusToID(t, "2001:db8::/48")
+		allocateSubnetsFocused(h, "Site", "", "", "64")
+		h.AssertStatusContains("Allocated network")
+		h.PressEnter()
+		h.MoveFocusToID(t, "2001:db8::/64")
+		h.MoveFocusToID(t, "2001:db8:0:8000::/49")
+		allocateHostsFocused(h, "Servers", "", "")
+		h.AssertStatusContains("Allocated network")
+		h.AssertScreenContains("2001:db8:0:8000::/49 (Se")
+
+		stored := make(chan int, 1)
+		h.App.TviewApp.QueueUpdateDraw(func() {
+			stored <- len(h.App.Catalog.GetChildren(h.App.CurrentItem))
+		})
+		if got := <-stored; got != 2 {
+			t.Fatalf("stored children = %d, want the split /49 and the allocated /49", got)
+		}
+	})
 }
 
 func TestSummarizeNetwork(t *testing.T) {
@@ -489,6 +593,19 @@ func TestSummarizeNetwork(t *testing.T) {
 	h.PressEnter()
 	h.AssertStatusContains("Summarized networks into")
 	h.AssertScreenContains("10.0.0.0/24")
+
+	// Subnets listed for a split network are summarized without being stored first.
+	h.MoveFocusToID(t, "10.0.0.0/24")
+	splitFocusedNetwork(h, "26")
+	h.MoveFocusToID(t, "10.0.0.0/26")
+	h.PressRune('S')
+	h.AssertScreenContains("Summarize in Networks")
+	h.PressTab()
+	h.PressTab()
+	h.PressEnter()
+	h.AssertStatusContains("Summarized networks into")
+	h.MoveFocusToID(t, "10.0.0.0/25")
+	h.MoveFocusToID(t, "10.0.0.128/26")
 }
 
 func TestDeleteNetworkBranches(t *testing.T) {
@@ -592,6 +709,313 @@ func TestUpdateAllocationAndDeallocate(t *testing.T) {
 	h.AssertScreenContains("10.0.0.0/24 (*)")
 }
 
+func TestResizeNetwork(t *testing.T) {
+	h := NewTestHarness(t)
+	h.NavigateToNetworks()
+	addNetworkViaDialog(h, "10.0.0.0/25")
+	addNetworkViaDialog(h, "10.0.0.128/25")
+	h.MoveFocusToID(t, "10.0.0.0/25")
+
+	h.PressRune('g')
+	h.AssertStatusContains("Resize is available only for allocated")
+
+	allocateHostsFocused(h, "Web", "", "")
+	h.PressEnter()
+	reserveIPFromCurrentNetwork(h, "10.0.0.10", "web01", "")
+	h.AssertStatusContains("Reserved IP")
+	h.PressBackspace()
+	h.MoveFocusToID(t, "10.0.0.0/25")
+
+	resize := func(prefix string) {
+		h.PressRune('g')
+		h.AssertScreenContains("Resize 10.0.0.0/")
+		for range 3 {
+			h.PressBackspace()
+		}
+		h.TypeText(prefix)
+		h.PressEnter()
+	}
+
+	resize("24")
+	h.AssertStatusContains("Resized network")
+	h.AssertScreenContains("10.0.0.0/24 (Web)")
+	h.AssertScreenNotContains("10.0.0.128/25")
+
+	resize("29")
+	h.AssertStatusContains("reserved IP 10.0.0.10")
+
+	resize("28")
+	h.AssertStatusContains("Resized network")
+	h.AssertScreenContains("10.0.0.0/28 (Web)")
+	h.AssertScreenContains("10.0.0.16/28 (*)")
+	h.AssertScreenContains("10.0.0.128/25 (*)")
+
+	h.MoveFocusToID(t, "10.0.0.0/28")
+	h.PressEnter()
+	h.AssertScreenContains("10.0.0.10 (web01)")
+}
+
+func TestMoveNetwork(t *testing.T) {
+	h := NewTestHarness(t)
+	h.NavigateToNetworks()
+	addNetworkViaDialog(h, "10.0.0.0/25")
+	addNetworkViaDialog(h, "10.1.0.0/24")
+	h.MoveFocusToID(t, "10.0.0.0/25")
+
+	h.PressRune('M')
+	h.AssertStatusContains("Move is available only for allocated")
+
+	allocateHostsFocused(h, "Web", "", "")
+	h.PressEnter()
+	reserveIPFromCurrentNetwork(h, "10.0.0.10", "web01", "")
+	h.AssertStatusContains("Reserved IP")
+	h.PressBackspace()
+	h.MoveFocusToID(t, "10.0.0.0/25")
+
+	h.PressRune('M')
+	h.AssertStatusContains("No other Subnet Container has room")
+
+	h.MoveFocusToID(t, "10.1.0.0/24")
+	allocateSubnetsFocused(h, "Prod", "", "", "25")
+	h.MoveFocusToID(t, "10.0.0.0/25")
+
+	h.PressRune('M')
+	h.AssertScreenContains("Move 10.0.0.0/25")
+	h.PressTab()
+	h.TypeText("10.1.0.128/25")
+	h.PressEnter()
+	h.AssertStatusContains("Moved network")
+	h.AssertScreenContains("10.1.0.128/25 (Web)")
+	h.AssertScreenContains("10.1.0.0/25 (*)")
+
+	h.MoveFocusToID(t, "10.1.0.128/25")
+	h.PressEnter()
+	h.AssertScreenContains("10.1.0.138 (web01)")
+}
+
+func TestRenumberNetwork(t *testing.T) {
+	h := NewTestHarness(t)
+	h.NavigateToNetworks()
+	addNetworkViaDialog(h, "192.168.10.0/24")
+	h.MoveFocusToID(t, "192.168.10.0/24")
+
+	h.PressRune('N')
+	h.AssertStatusContains("Renumber is available only for allocated")
+
+	allocateHostsFocused(h, "LAN", "", "")
+	h.PressEnter()
+	reserveIPFromCurrentNetwork(h, "192.168.10.5", "nas", "")
+	h.AssertStatusContains("Reserved IP")
+	h.PressBackspace()
+	h.MoveFocusToID(t, "192.168.10.0/24")
+
+	h.PressRune('N')
+	h.AssertScreenContains("Renumber 192.168.10.0/24")
+	h.TypeText("10.20.10.0/23")
+	h.PressEnter()
+	h.AssertStatusContains("Error renumbering network")
+
+	h.PressRune('N')
+	h.TypeText("10.20.10.0/24")
+	h.PressEnter()
+	h.AssertScreenContains("2 changes")
+	h.AssertScreenContains("192.168.10.5")
+	h.AssertScreenContains("10.20.10.5")
+
+	h.PressBackspace()
+	h.AssertScreenContains("Renumber 192.168.10.0/24")
+	h.PressEnter()
+	h.AssertScreenContains("2 changes")
+	h.PressEnter()
+	h.AssertStatusContains("Renumbered network")
+	h.AssertScreenContains("10.20.10.0/24 (LAN)")
+	h.AssertScreenNotContains("192.168.10.0/24 (LAN)")
+
+	h.MoveFocusToID(t, "10.20.10.0/24")
+	h.PressEnter()
+	h.AssertScreenContains("10.20.10.5 (nas)")
+}
+
+func TestCloneNetwork(t *testing.T) {
+	h := NewTestHarness(t)
+	h.NavigateToNetworks()
+	addNetworkViaDialog(h, "10.0.0.0/22")
+	h.MoveFocusToID(t, "10.0.0.0/22")
+	allocateSubnetsFocused(h, "Sites", "", "", "24")
+	h.PressEnter()
+	h.MoveFocusToID(t, "10.0.0.0/24")
+
+	h.PressRune('c')
+	h.AssertStatusContains("Clone is available only for allocated")
+
+	allocateSubnetsFocused(h, "{site}", "", "", "26")
+	h.PressEnter()
+	h.MoveFocusToID(t, "10.0.0.0/26")
+	allocateHostsFocused(h, "{site}-mgmt", "", "")
+	h.PressBackspace()
+	h.MoveFocusToID(t, "10.0.0.0/24")
+
+	h.PressRune('c')
+	h.AssertScreenContains("Clone 10.0.0.0/24")
+	h.PressTab()
+	h.PressTab()
+	h.TypeText("site=ams")
+	h.PressEnter()
+	h.AssertStatusContains("Cloned network")
+	h.AssertScreenContains("10.0.1.0/24 (ams)")
+
+	h.MoveFocusToID(t, "10.0.1.0/24")
+	h.PressEnter()
+	h.AssertScreenContains("ams-mgmt")
+	h.AssertScreenContains("10.0.1.64/26 (*)")
+}
+
+func TestAllocateWithLayoutTemplate(t *testing.T) {
+	dir := t.TempDir()
+	templatesDir := filepath.Join(dir, ".ez-ipam", "templates")
+	if err := os.MkdirAll(templatesDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	branch := "prefix_len: 23\nsubnets:\n" +
+		"  - {name: mgmt, prefix_len: 26, offset: 0, gateway_offset: 1}\n" +
+		"  - {name: users, prefix_len: 24, offset: 1}\n"
+	if err := os.WriteFile(filepath.Join(templatesDir, "branch.yaml"), []byte(branch), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	h := NewTestHarnessInDir(t, dir)
+	h.NavigateToNetworks()
+	addNetworkViaDialog(h, "10.1.0.0/23")
+	addNetworkViaDialog(h, "10.2.0.0/24")
+
+	h.MoveFocusToID(t, "10.2.0.0/24")
+	h.PressRune('a')
+	h.AssertScreenContains("Child Prefix Len")
+	h.AssertScreenNotContains("Template")
+	h.PressEscape()
+
+	h.MoveFocusToID(t, "10.1.0.0/23")
+	h.PressRune('a')
+	h.AssertScreenContains("Template")
+	h.TypeText("AMS")
+	h.SelectDropdownOption("Template", "branch")
+	h.PressEnter()
+	h.AssertStatusContains("Allocated network")
+
+	h.MoveFocusToID(t, "10.1.0.0/23")
+	h.PressEnter()
+	h.AssertScreenContains("10.1.0.0/26 (mgmt)")
+	h.AssertScreenContains("10.1.0.64/26 (*)")
+	h.AssertScreenContains("10.1.1.0/24 (users)")
+	h.MoveFocusToID(t, "10.1.0.0/26")
+	h.PressEnter()
+	h.AssertScreenContains("10.1.0.1 (gateway)")
+}
+
+func TestHostPoolGatewayAndExcludedRanges(t *testing.T) {
+	h := NewTestHarness(t)
+	h.NavigateToNetworks()
+	addNetworkViaDialog(h, "10.5.0.0/24")
+	h.MoveFocusToID(t, "10.5.0.0/24")
+	allocateHostPoolFocused(h, "LAN", ".300", "", "")
+	h.AssertStatusContains("Error allocating network")
+
+	allocateHostPoolFocused(h, "LAN", ".1", ".1-.10, .250-254", "")
+	h.AssertStatusContains("Allocated network")
+	h.AssertScreenContains("Gateway             : 10.5.0.1")
+	h.AssertScreenContains("Excluded Ranges     : 10.5.0.1-10.5.0.10,")
+
+	h.PressEnter()
+	reserveIPFromCurrentNetwork(h, "10.5.0.252", "printer", "")
+	h.AssertStatusContains("Error reserving IP: IP 10.5.0.252 falls in excluded")
+	reserveIPFromCurrentNetwork(h, "10.5.0.1", "router", "")
+	h.AssertStatusContains("Reserved IP")
+	reserveIPFromCurrentNetwork(h, "10.5.0.20", "printer", "")
+	h.AssertStatusContains("Reserved IP")
+
+	h.PressBackspace()
+	h.MoveFocusToID(t, "10.5.0.0/24")
+	updateAllocationFocused(h, "", "", "")
+	h.AssertStatusContains("Allocated network updated")
+	h.PressRune('u')
+	h.PressTab()
+	h.PressTab()
+	h.PressTab()
+	h.PressTab()
+	h.TypeText(", .20")
+	h.PressTab()
+	h.PressEnter()
+	h.AssertStatusContains("Error updating network allocation")
+	h.AssertScreenContains("10.5.0.20 falls in excluded")
+}
+
+func TestHostPoolDHCPRanges(t *testing.T) {
+	h := NewTestHarness(t)
+	h.NavigateToNetworks()
+	addNetworkViaDialog(h, "10.6.0.0/24")
+	h.MoveFocusToID(t, "10.6.0.0/24")
+	allocateHostPoolFocused(h, "LAN", ".1", "", ".1-.50")
+	h.AssertStatusContains("Error allocating network")
+
+	allocateHostPoolFocused(h, "LAN", ".1", "", ".100-.199")
+	h.AssertStatusContains("Allocated network")
+	h.AssertScreenContains("DHCP Ranges         : 10.6.0.100-10.6.0.199")
+	h.AssertScreenContains("Address Split       : 100 dynamic (DHCP), 154 static")
+
+	h.PressEnter()
+	reserveIPFromCurrentNetwork(h, "10.6.0.150", "printer", "")
+	h.AssertStatusContains("Reserved IP")
+	h.AssertStatusContains("warning")
+	h.AssertScreenContains("Warning              : 10.6.0.150 (printer) is")
+}
+
+func TestHostPoolDHCPOptions(t *testing.T) {
+	h := NewTestHarness(t)
+	h.NavigateToNetworks()
+	addNetworkViaDialog(h, "10.7.0.0/24")
+	h.MoveFocusToID(t, "10.7.0.0/24")
+	allocateHostPoolFocused(h, "LAN", ".1", "", "")
+
+	h.PressRune('o')
+	h.AssertScreenContains("DHCP Options for 10.7.0.0/24")
+	h.PressTab()
+	h.TypeText("10.7.0.53, 10.7.0.54")
+	h.PressTab()
+	h.TypeText("lan.example.com")
+	h.PressTab()
+	h.PressTab()
+	h.PressTab()
+	h.TypeText("forever")
+	h.PressTab()
+	h.PressTab() // Save button
+	h.PressEnter()
+	h.AssertStatusContains("Error updating DHCP options")
+
+	h.PressRune('o')
+	h.AssertScreenContains("DHCP Options for 10.7.0.0/24")
+	h.PressTab()
+	h.TypeText("10.7.0.53, 10.7.0.54")
+	h.PressTab()
+	h.TypeText("lan.example.com")
+	h.PressTab()
+	h.PressTab()
+	h.PressTab()
+	h.TypeText("12h")
+	h.PressTab()
+	h.TypeText("66=tftp.lan.example.com")
+	h.PressTab() // Save button
+	h.PressEnter()
+	h.AssertStatusContains("Updated DHCP options")
+	h.AssertScreenContains("DHCP Router         : 10.7.0.1")
+	h.AssertScreenContains("DHCP DNS Servers    : 10.7.0.53, 10.7.0.54")
+
+	h.PressRune('o')
+	h.AssertScreenContains("lan.example.com")
+	h.AssertScreenContains("12h")
+	h.AssertScreenContains("66=tftp.lan.example.com")
+	h.PressEscape()
+}
+
 func TestReserveUpdateUnreserveIPBranches(t *testing.T) {
 	h := NewTestHarness(t)
 	h.NavigateToNetworks()
@@ -649,7 +1073,7 @@ func TestDNSAliasLifecycleAndCascadeOnUnreserve(t *testing.T) {
 	h.AssertStatusContains("Reserved IP")
 
 	navigateToDNSRoot(t, h)
-	addDNSRecordViaDialog(h, "mail.home", "MX", "10 mail.provider.home", "Mail route")
+	addDNSRecordViaDialog(h, "mail.home", "MX", "Mail route", "10", "mail.provider.home")
 	h.AssertStatusContains("Added DNS record")
 	addDNSAliasViaDialog(h, "gateway.home", "10.77.0.2", "Gateway alias")
 	h.AssertStatusContains("Added DNS record")
@@ -657,6 +1081,7 @@ func TestDNSAliasLifecycleAndCascadeOnUnreserve(t *testing.T) {
 	h.AssertScreenContains("Type                 : Alias")
 	h.AssertScreenContains("10.77.0.2 (dns.home")
 	h.AssertScreenContains("aa:bb:cc:dd:ee:ff")
+	h.AssertScreenContains("Description          : Gateway alias")
 
 	navigateToNetworksRoot(t, h)
 	h.MoveFocusToID(t, "10.77.0.0/24")
@@ -673,6 +1098,133 @@ func TestDNSAliasLifecycleAndCascadeOnUnreserve(t *testing.T) {
 	h.AssertScreenContains("mail.home")
 }
 
+func TestDNSHostAndMultiTargetAliases(t *testing.T) {
+	h := NewTestHarness(t)
+	h.NavigateToNetworks()
+	addNetworkViaDialog(h, "10.66.0.0/24")
+	h.MoveFocusToID(t, "10.66.0.0/24")
+	allocateHostsFocused(h, "v4.home", "IPv4 hosts", "")
+	addNetworkViaDialog(h, "fd66::/64")
+	h.MoveFocusToID(t, "fd66::/64")
+	allocateHostsFocused(h, "v6.home", "IPv6 hosts", "")
+	h.MoveFocusToID(t, "10.66.0.0/24")
+	h.PressEnter()
+	reserveIPFromCurrentNetworkWithMAC(h, "10.66.0.10", "nas", "", "NAS")
+	reserveIPFromCurrentNetworkWithMAC(h, "10.66.0.11", "nas-mgmt", "", "NAS management")
+	navigateToNetworksRoot(t, h)
+	h.MoveFocusToID(t, "fd66::/64")
+	h.PressEnter()
+	reserveIPFromCurrentNetworkWithMAC(h, "fd66::10", "nas", "", "NAS")
+	h.AssertStatusContains("Reserved IP")
+
+	navigateToDNSRoot(t, h)
+	h.PressRune('r')
+	h.TypeText("files.home")
+	h.SelectDropdownOption("Mode", "Host")
+	h.SelectDropdownOption("Host", "nas")
+	h.PressTab() // Host
+	h.PressTab() // TTL
+	h.PressTab() // Description
+	h.PressTab() // Save button
+	h.PressEnter()
+	h.AssertStatusContains("Added DNS record")
+	h.MoveFocusToID(t, "files.home")
+	h.AssertScreenContains("Type                 : Alias (host nas)")
+	h.AssertScreenContains("10.66.0.10 (nas), fd66::10")
+
+	h.PressRune('r')
+	h.TypeText("storage.home")
+	h.SelectDropdownOption("Mode", "Alias")
+	h.SelectDropdownOption("Reserved IP", "10.66.0.10")
+	h.SelectDropdownOption("Also Reserved IP", "10.66.0.11")
+	for range 6 {
+		h.PressTab()
+		h.PressEnter()
+		if !strings.Contains(h.GetScreenText(), "Add DNS Record") {
+			break
+		}
+	}
+	h.AssertStatusContains("Added DNS record")
+	h.MoveFocusToID(t, "storage.home")
+	h.AssertScreenContains("10.66.0.10 (nas), 10.66.0.11")
+
+	navigateToNetworksRoot(t, h)
+	h.MoveFocusToID(t, "10.66.0.0/24")
+	h.PressEnter()
+	h.MoveFocusToID(t, "10.66.0.11 (nas-mgmt")
+	h.PressRune('R')
+	h.AssertScreenContains("The following DNS aliases")
+	h.AssertScreenContains("- storage.home")
+	h.ConfirmModal()
+	h.AssertStatusContains("Unreserved IP")
+
+	navigateToDNSRoot(t, h)
+	h.MoveFocusToID(t, "storage.home")
+	h.AssertScreenContains("Value                : 10.66.0.10 (nas)")
+	h.AssertScreenNotContains("nas-mgmt")
+}
+
+func TestDNSZones(t *testing.T) {
+	h := NewTestHarness(t)
+	navigateToDNSRoot(t, h)
+
+	h.PressRune('z')
+	h.AssertScreenContains("Add DNS Zone")
+	h.TypeText("Example.com.")
+	h.PressTab()
+	h.TypeText("600")
+	h.PressTab()
+	h.TypeText("admin@example.com")
+	h.PressTab()
+	h.TypeText("ns1.example.com, ns2.example.net")
+	h.PressTab() // Description
+	h.PressTab() // Save button
+	h.PressEnter()
+	h.AssertStatusContains("Added DNS zone")
+	h.MoveFocusToID(t, "example.com (zone)")
+	h.AssertScreenContains("Default TTL          : 600")
+	h.AssertScreenContains("Name Servers         : ns1.example.com,")
+	h.AssertScreenContains("ns2.example.net")
+
+	h.PressEnter()
+	addDNSRecordViaDialog(h, "www.example.net", "A", "", "10.0.0.80")
+	h.AssertStatusContains("outside its zone")
+	h.PressRune('r')
+	h.TypeText("www.example.com")
+	h.PressTab() // Mode
+	h.PressTab() // Record Type, A
+	h.PressTab()
+	h.TypeText("10.0.0.80")
+	h.PressTab()
+	h.TypeText("60")
+	h.PressTab() // Description
+	h.PressTab() // Save button
+	h.PressEnter()
+	h.AssertStatusContains("Added DNS record: DNS -> example.com ->")
+	h.MoveFocusToID(t, "www.example.com")
+	h.AssertScreenContains("TTL                  : 60")
+
+	h.PressEscape()
+	h.MoveFocusToID(t, "example.com (zone)")
+	h.PressRune('u')
+	h.AssertScreenContains("Update DNS Zone example.com")
+	h.TypeText("0")
+	h.PressTab()
+	h.PressTab()
+	h.PressTab() // Description
+	h.PressTab() // Save button
+	h.PressEnter()
+	h.AssertStatusContains("Updated DNS zone")
+	h.AssertScreenContains("Default TTL          : 6000")
+	h.AssertScreenContains("Records              : 1")
+
+	h.PressRune('D')
+	h.AssertScreenContains("www.example.com")
+	h.ConfirmModal()
+	h.AssertStatusContains("Deleted DNS zone")
+	h.AssertScreenNotContains("example.com (zone)")
+}
+
 func TestDNSModeSwitchDoesNotResetFocusToFQDN(t *testing.T) {
 	h := NewTestHarness(t)
 	h.NavigateToNetworks()
@@ -707,6 +1259,36 @@ func TestDNSModeSwitchDoesNotResetFocusToFQDN(t *testing.T) {
 	h.AssertScreenContains("FQDN                 : focus-alias.home")
 }
 
+func TestDNSRecordTypedForms(t *testing.T) {
+	h := NewTestHarness(t)
+	navigateToDNSRoot(t, h)
+
+	addDNSRecordViaDialog(h, "sip.home", "SRV", "SIP service", "10", "5", "5060", "pbx.home")
+	h.AssertStatusContains("Added DNS record")
+	h.MoveFocusToID(t, "sip.home")
+	h.AssertScreenContains("Type                 : SRV")
+	h.AssertScreenContains("Value                : 10 5 5060 pbx.home")
+
+	h.PressRune('u')
+	h.AssertScreenContains("Update DNS Record")
+	h.AssertScreenContains("Weight")
+	h.AssertScreenContains("5060")
+	h.PressEscape()
+
+	addDNSRecordViaDialog(h, "home", "CAA", "CA policy", "0", "", "letsencrypt.org")
+	h.AssertStatusContains("Added DNS record")
+	h.MoveFocusToID(t, "home")
+	h.AssertScreenContains(`Value                : 0 issue "letsencrypt.org"`)
+
+	addDNSRecordViaDialog(h, "bad-a.home", "A", "", "not-an-ip")
+	h.AssertStatusContains("must be an IPv4")
+	addDNSRecordViaDialog(h, "bad-mx.home", "MX", "", "10", "")
+	h.AssertStatusContains("Error adding DNS record")
+	addDNSRecordViaDialog(h, "bad-aaaa.home", "AAAA", "", "10.0.0.1")
+	h.AssertStatusContains("must be an IPv6")
+	h.AssertScreenNotContains("bad-a.home")
+}
+
 func TestAddVLANBranches(t *testing.T) {
 	h := NewTestHarness(t)
 	navigateToVLANs(t, h)
@@ -1150,6 +1732,209 @@ func TestHomeNavigation(t *testing.T) {
 	h.PressBackspace()
 }
 
+func TestNetworkTreeView(t *testing.T) {
+	h := NewTestHarness(t)
+
+	h.PressRune('t')
+	h.AssertStatusContains("Tree view is available only inside Networks")
+
+	h.NavigateToNetworks()
+	addNetworkViaDialog(h, "10.0.0.0/16")
+	h.MoveFocusToID(t, "10.0.0.0/16")
+	allocateSubnetsFocused(h, "Site", "site block", "", "24")
+	h.PressEnter()
+	h.MoveFocusToID(t, "10.0.0.0/24")
+	allocateHostsFocused(h, "Office", "LAN", "")
+
+	h.PressRune('t')
+	h.AssertScreenContains("Networks Tree")
+	h.AssertScreenContains("10.0.0.0/24 [Pool")
+	h.AssertScreenContains("10.0.1.0/24 [Free")
+	if !h.FocusMatches("10.0.0.0/24") {
+		t.Fatalf("expected tree focus on 10.0.0.0/24, got %q", h.CurrentFocusID())
+	}
+
+	// h on a leaf jumps to its parent, then collapses it; l expands it again.
+	h.PressRune('h')
+	if !h.FocusMatches("10.0.0.0/16") {
+		t.Fatalf("expected tree focus on 10.0.0.0/16, got %q", h.CurrentFocusID())
+	}
+	h.PressRune('h')
+	h.AssertScreenNotContains("10.0.0.0/24")
+	h.PressRune('l')
+	h.AssertScreenContains("10.0.1.0/24 [Free")
+
+	// Menu keys apply to the highlighted pool.
+	h.MoveFocusToID(t, "10.0.0.0/24")
+	reserveIPFromCurrentNetwork(h, "10.0.0.1", "gateway.home", "gw")
+	h.AssertStatusContains("Reserved IP")
+	h.AssertScreenContains("Networks Tree")
+	h.AssertScreenContains("10.0.0.1 (gatew")
+
+	// Focus keys apply to the highlighted free block.
+	h.MoveFocusToID(t, "10.0.1.0/24")
+	allocateHostsFocused(h, "Guest", "Guest LAN", "")
+	h.AssertScreenContains("10.0.1.0/24 [Pool")
+
+	h.PressRune('t')
+	h.AssertScreenContains("═Menu═")
+	h.AssertScreenNotContains("Networks Tree")
+	if !h.FocusMatches("10.0.1.0/24") {
+		t.Fatalf("expected list focus to stay on 10.0.1.0/24, got %q", h.CurrentFocusID())
+	}
+
+	// Collapsed nodes get their children on first expansion.
+	h.PressRune('t')
+	h.AssertScreenNotContains("10.0.0.1 (gatew")
+	h.MoveFocusToID(t, "10.0.0.0/24")
+	h.PressRune('l')
+	h.AssertScreenContains("10.0.0.1 (gatew")
+}
+
+func TestAddressMap(t *testing.T) {
+	h := NewTestHarness(t)
+	h.NavigateToNetworks()
+	addNetworkViaDialog(h, "10.0.0.0/24")
+	h.MoveFocusToID(t, "10.0.0.0/24")
+
+	h.PressRune('m')
+	h.AssertStatusContains("Address map is available only for Subnet Containers")
+
+	allocateSubnetsFocused(h, "Site", "site block", "", "26")
+	h.PressRune('m')
+	h.AssertScreenContains("Address Map: 10.0.0.0/24 (Site)")
+	h.AssertScreenContains("10.0.0.192/26")
+	h.AssertScreenContains("25.0%")
+
+	// Allocate the first block straight from the map.
+	h.PressRune('A')
+	h.AssertScreenContains("Allocate as Host Pool for 10.0.0.0/26")
+	h.TypeText("Office")
+	h.PressTab()
+	h.PressTab()
+	h.PressTab()
+	h.PressEnter()
+	h.AssertStatusContains("Allocated network")
+	if !h.FocusMatches("10.0.0.0/26") {
+		t.Fatalf("expected focus on 10.0.0.0/26, got %q", h.CurrentFocusID())
+	}
+
+	h.PressBackspace()
+	h.MoveFocusToID(t, "10.0.0.0/24")
+	h.PressRune('m')
+	h.AssertScreenContains("Pool")
+	h.AssertScreenContains("Office")
+
+	// Split the second block from the map.
+	h.PressDown()
+	h.PressRune('s')
+	h.AssertScreenContains("Split 10.0.0.64/26")
+	h.TypeText("27")
+	h.PressEnter()
+	h.AssertScreenContains("10.0.0.96/27")
+
+	// Enter on an allocated block opens it.
+	h.PressBackspace()
+	h.MoveFocusToID(t, "10.0.0.0/24")
+	h.PressRune('m')
+	h.PressEnter()
+	h.AssertScreenContains("Networks -> 10.0.0.0/24 -> 10.0.0.0/26")
+	reserveIPFromCurrentNetwork(h, "10.0.0.1", "gateway.home", "")
+	h.AssertStatusContains("Reserved IP")
+
+	h.PressBackspace()
+	h.PressBackspace()
+	h.MoveFocusToID(t, "10.0.0.0/24")
+	h.PressRune('m')
+	h.AssertScreenContains("1 (2%)")
+	h.PressRune('c')
+	h.AssertScreenContains("by VLAN ID")
+	h.PressEscape()
+	h.AssertScreenNotContains("Address Map")
+	h.AssertScreenContains("═Menu═")
+}
+
+func TestFreeSpaceReport(t *testing.T) {
+	h := NewTestHarness(t)
+	h.NavigateToNetworks()
+	addNetworkViaDialog(h, "10.0.0.0/24")
+	addNetworkViaDialog(h, "10.0.1.0/24")
+	h.MoveFocusToID(t, "10.0.0.0/24")
+	allocateSubnetsFocused(h, "Site", "", "", "26")
+	h.PressEnter()
+	h.MoveFocusToID(t, "10.0.0.0/26")
+	allocateHostsFocused(h, "LAN", "", "")
+	h.PressBackspace()
+
+	h.PressRune('F')
+	h.AssertScreenContains("Free space under Networks")
+	h.AssertScreenContains("10.0.0.64/26")
+	h.AssertScreenContains("10.0.0.128/25")
+	h.AssertScreenContains("10.0.1.0/24")
+	h.AssertScreenContains("sorted by address")
+	h.PressRune('o')
+	h.AssertScreenContains("sorted by size")
+	h.PressEscape()
+	h.AssertScreenNotContains("Free space under")
+
+	h.MoveFocusToID(t, "10.0.0.0/24")
+	h.PressRune('f')
+	h.AssertScreenContains("Free space under Networks -> 10.0.0.0/24")
+	h.AssertScreenContains("2 blocks")
+	h.AssertScreenNotContains("10.0.1.0/24")
+	h.PressEnter()
+
+	h.MoveFocusToID(t, "10.0.1.0/24")
+	h.PressRune('f')
+	h.AssertStatusContains("Free space report is available only")
+}
+
+func TestSubnetPlanner(t *testing.T) {
+	h := NewTestHarness(t)
+	h.NavigateToNetworks()
+	addNetworkViaDialog(h, "10.0.0.0/16")
+	h.MoveFocusToID(t, "10.0.0.0/16")
+
+	h.PressRune('P')
+	h.AssertStatusContains("Subnet planner is available only")
+
+	allocateSubnetsFocused(h, "Site", "", "", "")
+	h.PressRune('P')
+	h.AssertScreenContains("Plan Subnets in 10.0.0.0/16")
+	h.TypeText("servers /24")
+	h.PressEnter()
+	h.TypeText("2x users 500")
+	h.PressTab()
+	h.PressEnter()
+	h.AssertScreenContains("Subnet Plan Preview")
+	h.AssertScreenContains("10.0.0.0/23")
+	h.AssertScreenContains("users-2")
+	h.AssertScreenContains("10.0.4.0/24")
+
+	// Backspace goes back to the requirements with the text kept.
+	h.PressBackspace()
+	h.AssertScreenContains("2x users 500")
+	h.PressTab()
+	h.PressEnter()
+	h.PressEnter()
+	h.AssertStatusContains("Planned 3 subnets")
+
+	h.PressEnter()
+	h.MoveFocusToID(t, "10.0.0.0/23")
+	h.AssertScreenContains("10.0.0.0/23 (users-1)")
+	h.AssertScreenContains("10.0.2.0/23 (users-2)")
+	h.AssertScreenContains("10.0.4.0/24 (servers)")
+	h.AssertScreenContains("10.0.5.0/24 (*)")
+
+	h.PressBackspace()
+	h.MoveFocusToID(t, "10.0.0.0/16")
+	h.PressRune('P')
+	h.TypeText("huge /8")
+	h.PressTab()
+	h.PressEnter()
+	h.AssertStatusContains("Error planning subnets")
+}
+
 func TestNetworksLifecycle(t *testing.T) {
 	h := NewTestHarness(t)
 	step := 1
@@ -1749,11 +2534,11 @@ func TestDemoState(t *testing.T) {
 	navigateToNetworksRoot(t, h)
 
 	navigateToDNSRoot(t, h)
-	addDNSRecordViaDialog(h, "mail.home", "MX", "10 mail.provider.home", "Primary mail route")
-	addDNSRecordViaDialog(h, "txt.home", "TXT", "v=spf1 include:provider.home -all", "SPF policy")
-	addDNSAliasViaDialog(h, "gateway.home", "192.168.0.1", "Primary IPv4 gateway alias")
-	addDNSAliasViaDialog(h, "nas.home", "192.168.0.10", "NAS alias")
-	addDNSAliasViaDialog(h, "gateway-v6.home", "fd42::1", "Primary IPv6 gateway alias")
+	addDNSRecordViaDialog(h, "mail.home", "MX", "Primary mail route", "10", "mail.provider.home")
+	addDNSRecordViaDialog(h, "txt.home", "TXT", "SPF policy", "v=spf1 include:provider.home -all")
+	addDNSAliasViaDialog(h, "gateway.home", "192.168.0.1", "")
+	addDNSAliasViaDialog(h, "nas.home", "192.168.0.10", "")
+	addDNSAliasViaDialog(h, "gateway-v6.home", "fd42::1", "")
 	navigateToNetworksRoot(t, h)
 
 	h.PressCtrl('s')
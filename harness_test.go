@@ -11,6 +11,7 @@ import (
 	"time"
 
 	"github.com/gdamore/tcell/v2"
+	"github.com/plumber-cd/ez-ipam/internal/domain"
 	"github.com/plumber-cd/ez-ipam/internal/ui"
 )
 
@@ -386,6 +387,21 @@ func (h *TestHarness) CurrentFocusID() string {
 	return <-done
 }
 
+// FocusedNetworkMode returns the allocation mode of the focused network, or
+// AllocationModeUnallocated when the focus is not a network (thread-safe).
+func (h *TestHarness) FocusedNetworkMode() domain.AllocationMode {
+	done := make(chan domain.AllocationMode, 1)
+	h.App.TviewApp.QueueUpdateDraw(func() {
+		n, ok := h.App.CurrentFocus.(*domain.Network)
+		if !ok {
+			done <- domain.AllocationModeUnallocated
+			return
+		}
+		done <- n.AllocationMode
+	})
+	return <-done
+}
+
 // CurrentKeys returns current menu and focus key lists (thread-safe).
 func (h *TestHarness) CurrentKeys() ([]string, []string) {
 	type result struct {
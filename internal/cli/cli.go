@@ -0,0 +1,101 @@
+// Package cli implements the non-interactive ez-ipam subcommands.
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/plumber-cd/ez-ipam/internal/domain"
+	"github.com/plumber-cd/ez-ipam/internal/store"
+)
+
+// command is a single subcommand.
+type command struct {
+	name    string
+	summary string
+	run     func(dir string, args []string, stdout, stderr io.Writer) error
+}
+
+var commands = []command{
+	{name: "free", summary: "List unallocated address space", run: runFree},
+	{name: "dhcp", summary: "Export static DHCP leases (Kea, dnsmasq, ISC dhcpd)", run: runDHCP},
+	{name: "dns", summary: "Export DNS records (BIND zones, Unbound, CoreDNS, Pi-hole, hosts)", run: runDNS},
+	{name: "export", summary: "Export every item as CSV, JSON or YAML tables", run: runExport},
+	{name: "import", summary: "Import CSV rows or reconcile DHCP lease files with the reservations", run: runImport},
+}
+
+// Run executes the subcommand named by args[0] against the data in dir.
+func Run(dir string, args []string, stdout, stderr io.Writer) error {
+	if len(args) == 0 {
+		printUsage(stderr)
+		return errors.New("no command given")
+	}
+	name := args[0]
+	if name == "help" || name == "-h" || name == "--help" {
+		printUsage(stdout)
+		return nil
+	}
+	idx := slices.IndexFunc(commands, func(c command) bool { return c.name == name })
+	if idx < 0 {
+		printUsage(stderr)
+		return fmt.Errorf("unknown command %q", name)
+	}
+	err := commands[idx].run(dir, args[1:], stdout, stderr)
+	if errors.Is(err, flag.ErrHelp) {
+		return nil
+	}
+	return err
+}
+
+func printUsage(w io.Writer) {
+	fmt.Fprintln(w, "Usage: ez-ipam [command] [flags]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Without a command, the interactive terminal UI is started.")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Commands:")
+	for _, c := range commands {
+		fmt.Fprintf(w, "  %-10s %s\n", c.name, c.summary)
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Run 'ez-ipam <command> -h' for command flags.")
+}
+
+// newFlagSet creates a flag set that reports errors instead of exiting.
+func newFlagSet(name string, stderr io.Writer) *flag.FlagSet {
+	fs := flag.NewFlagSet("ez-ipam "+name, flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	return fs
+}
+
+// save writes the catalog and regenerates EZ-IPAM.md and the configured
+// DHCP and DNS exports, as saving in the terminal UI does.
+// load reads the catalog in dir and reports the DNS data problems it
+// accepted on stderr.
+func load(dir string, stderr io.Writer) (*domain.Catalog, error) {
+	catalog, err := store.Load(dir)
+	if err != nil {
+		return nil, fmt.Errorf("load data: %w", err)
+	}
+	for _, warning := range catalog.LoadWarnings {
+		fmt.Fprintf(stderr, "warning: %s\n", warning)
+	}
+	return catalog, nil
+}
+
+// findNetwork resolves ref, either a full catalog path or a bare CIDR, to a network.
+func findNetwork(catalog *domain.Catalog, ref string) (*domain.Network, error) {
+	ref = strings.TrimSpace(ref)
+	if n, ok := catalog.Get(ref).(*domain.Network); ok {
+		return n, nil
+	}
+	for _, item := range catalog.All() {
+		if n, ok := item.(*domain.Network); ok && n.ID == ref {
+			return n, nil
+		}
+	}
+	return nil, fmt.Errorf("network %q not found", ref)
+}
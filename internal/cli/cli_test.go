@@ -0,0 +1,305 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/plumber-cd/ez-ipam/internal/domain"
+	"github.com/plumber-cd/ez-ipam/internal/store"
+)
+
+// newTestDir saves a small catalog to a temp directory and returns its path.
+func newTestDir(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	catalog, err := store.Load(dir)
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	root := &domain.Network{
+		Base:           domain.Base{ID: "10.0.0.0/16", ParentPath: domain.FolderNetworks},
+		AllocationMode: domain.AllocationModeSubnets,
+		DisplayName:    "Site",
+	}
+	catalog.Put(root)
+	pool := &domain.Network{
+		Base:           domain.Base{ID: "10.0.0.0/24", ParentPath: root.GetPath()},
+		AllocationMode: domain.AllocationModeHosts,
+		DisplayName:    "LAN",
+	}
+	catalog.Put(pool)
+	catalog.Put(&domain.IP{Base: domain.Base{ID: "10.0.0.1", ParentPath: pool.GetPath()}, DisplayName: "gw", MACAddress: "00:11:22:33:44:55"})
+	for _, cidr := range []string{"10.0.1.0/24", "10.0.2.0/23", "10.0.4.0/22", "10.0.8.0/21", "10.0.16.0/20", "10.0.32.0/19", "10.0.64.0/18", "10.0.128.0/17"} {
+		catalog.Put(&domain.Network{Base: domain.Base{ID: cidr, ParentPath: root.GetPath()}})
+	}
+	catalog.Put(&domain.Network{Base: domain.Base{ID: "192.168.0.0/24", ParentPath: domain.FolderNetworks}})
+	if err := store.Save(dir, catalog); err != nil {
+		t.Fatalf("Save() error: %v", err)
+	}
+	return dir
+}
+
+func runCLI(t *testing.T, dir string, args ...string) (string, error) {
+	t.Helper()
+	var stdout, stderr bytes.Buffer
+	err := Run(dir, args, &stdout, &stderr)
+	return stdout.String(), err
+}
+
+func TestRunUnknownCommand(t *testing.T) {
+	if _, err := runCLI(t, t.TempDir(), "nope"); err == nil {
+		t.Fatal("expected error for unknown command")
+	}
+	if _, err := runCLI(t, t.TempDir()); err == nil {
+		t.Fatal("expected error when no command is given")
+	}
+	out, err := runCLI(t, t.TempDir(), "help")
+	if err != nil || !strings.Contains(out, "free") {
+		t.Fatalf("help output = %q, err = %v", out, err)
+	}
+}
+
+func TestFreeCommand(t *testing.T) {
+	dir := newTestDir(t)
+
+	out, err := runCLI(t, dir, "free")
+	if err != nil {
+		t.Fatalf("free error: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if !strings.HasPrefix(lines[1], "10.0.1.0/24") || !strings.Contains(out, "192.168.0.0/24") {
+		t.Errorf("unexpected address-sorted output:\n%s", out)
+	}
+	if !strings.Contains(out, "Free blocks: 9") {
+		t.Errorf("expected 9 free blocks:\n%s", out)
+	}
+
+	out, err = runCLI(t, dir, "free", "--sort", "size", "--under", "10.0.0.0/16")
+	if err != nil {
+		t.Fatalf("free --under error: %v", err)
+	}
+	lines = strings.Split(strings.TrimSpace(out), "\n")
+	if !strings.HasPrefix(lines[1], "10.0.128.0/17") || strings.Contains(out, "192.168.0.0/24") {
+		t.Errorf("unexpected size-sorted output:\n%s", out)
+	}
+
+	if _, err := runCLI(t, dir, "free", "--sort", "random"); err == nil {
+		t.Error("expected error for invalid sort")
+	}
+	if _, err := runCLI(t, dir, "free", "--under", "172.16.0.0/12"); err == nil {
+		t.Error("expected error for unknown network")
+	}
+}
+
+func TestDHCPCommand(t *testing.T) {
+	dir := newTestDir(t)
+
+	out, err := runCLI(t, dir, "dhcp", "--format", "dnsmasq")
+	if err != nil {
+		t.Fatalf("dhcp error: %v", err)
+	}
+	if out != "# 10.0.0.0/24 (LAN)\ndhcp-host=00:11:22:33:44:55,10.0.0.1,gw\n" {
+		t.Errorf("unexpected dnsmasq output:\n%s", out)
+	}
+
+	out, err = runCLI(t, dir, "dhcp", "--under", "192.168.0.0/24")
+	if err != nil || !strings.Contains(out, "{}") {
+		t.Errorf("expected no Kea subnets outside the pool, got %q, %v", out, err)
+	}
+
+	if _, err := runCLI(t, dir, "dhcp", "--format", "isc"); err == nil {
+		t.Error("expected error for unknown format")
+	}
+
+	output := filepath.Join(t.TempDir(), "dhcpd.conf")
+	if _, err := runCLI(t, dir, "dhcp", "--format", "dhcpd", "--output", output); err != nil {
+		t.Fatalf("dhcp --output error: %v", err)
+	}
+	if v4, err := os.ReadFile(output); err != nil || !strings.Contains(string(v4), "fixed-address 10.0.0.1;") {
+		t.Errorf("unexpected IPv4 dhcpd file %q, %v", v4, err)
+	}
+	if v6, err := os.ReadFile(filepath.Join(filepath.Dir(output), "dhcpd6.conf")); err != nil || len(v6) != 0 {
+		t.Errorf("expected an empty IPv6 dhcpd file, got %q, %v", v6, err)
+	}
+	if out, err := runCLI(t, dir, "dhcp", "--format", "dnsmasq", "--family", "6"); err != nil || out != "" {
+		t.Errorf("expected no IPv6 leases, got %q, %v", out, err)
+	}
+	if _, err := runCLI(t, dir, "dhcp", "--family", "5"); err == nil {
+		t.Error("expected error for unknown address family")
+	}
+}
+
+func TestDNSCommand(t *testing.T) {
+	dir := newTestDir(t)
+	catalog, err := store.Load(dir)
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	catalog.Put(&domain.DNSRecord{Base: domain.Base{ID: "ns1.example.com", ParentPath: domain.FolderDNS}, RecordType: "A", RecordValue: "10.0.0.2"})
+	for _, alias := range []string{"gw.example.com", "router.example.com"} {
+		catalog.Put(&domain.DNSRecord{Base: domain.Base{ID: alias, ParentPath: domain.FolderDNS}, ReservedIPPath: "Networks -> 10.0.0.0/16 -> 10.0.0.0/24 -> 10.0.0.1"})
+	}
+	if err := store.Save(dir, catalog); err != nil {
+		t.Fatalf("Save() error: %v", err)
+	}
+
+	out, err := runCLI(t, dir, "dns")
+	if err != nil {
+		t.Fatalf("dns error: %v", err)
+	}
+	if !strings.Contains(out, "$ORIGIN example.com.") || !strings.Contains(out, "ns1\tIN\tA\t10.0.0.2") {
+		t.Errorf("unexpected zone output:\n%s", out)
+	}
+
+	if !strings.Contains(out, "$ORIGIN 0.0.10.in-addr.arpa.") || !strings.Contains(out, "1\tIN\tPTR\tgw.example.com.") {
+		t.Errorf("missing reverse zone:\n%s", out)
+	}
+
+	out, err = runCLI(t, dir, "dns", "--conflicts")
+	if err != nil || out != "10.0.0.1\tgw.example.com, router.example.com\tPTR -> gw.example.com\n" {
+		t.Errorf("unexpected conflict report %q, %v", out, err)
+	}
+
+	zonesDir := t.TempDir()
+	if _, err := runCLI(t, dir, "dns", "--output", zonesDir); err != nil {
+		t.Fatalf("dns --output error: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(zonesDir, "0.0.10.in-addr.arpa.zone")); err != nil {
+		t.Errorf("zone file not written: %v", err)
+	}
+
+	out, err = runCLI(t, dir, "dns", "--format", "hosts")
+	if err != nil || !strings.Contains(out, "10.0.0.1\tgw.example.com router.example.com gw\n") {
+		t.Errorf("unexpected hosts output %q, %v", out, err)
+	}
+
+	if _, err := runCLI(t, dir, "dns", "--format", "nsd"); err == nil {
+		t.Error("expected error for unknown format")
+	}
+}
+
+func TestDNSCommandDemoData(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	if err := Run(filepath.Join("..", ".."), []string{"dns"}, &stdout, &stderr); err != nil {
+		t.Fatalf("dns on the demo data error: %v", err)
+	}
+	for _, want := range []string{"$ORIGIN home.\n", "gateway\tIN\tA\t192.168.0.1\n", "$ORIGIN az-a.demo.\n", "$ORIGIN 0.168.192.in-addr.arpa.\n"} {
+		if !strings.Contains(stdout.String(), want) {
+			t.Errorf("demo zones missing %q:\n%s", want, stdout.String())
+		}
+	}
+	if strings.Contains(stdout.String(), "$ORIGIN gateway.home.") {
+		t.Errorf("hosts must not get zones of their own:\n%s", stdout.String())
+	}
+	if !strings.Contains(stderr.String(), "warning: zone home: default NS ns1.home has no address records") {
+		t.Errorf("missing default NS warning:\n%s", stderr.String())
+	}
+}
+
+func TestImportCommand(t *testing.T) {
+	dir := newTestDir(t)
+	csvPath := filepath.Join(t.TempDir(), "ips.csv")
+	if err := os.WriteFile(csvPath, []byte("address,name\n10.0.0.20,printer\n10.0.1.20,nowhere\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	reservation := "Networks -> 10.0.0.0/16 -> 10.0.0.0/24 -> 10.0.0.20"
+
+	out, err := runCLI(t, dir, "import", "csv", "--type", "ips", "--dry-run", csvPath)
+	if err == nil || !strings.Contains(err.Error(), "1 of 2 rows") || out != "would add "+reservation+"\n" {
+		t.Fatalf("dry run output = %q, err = %v", out, err)
+	}
+	catalog, err := store.Load(dir)
+	if err != nil || catalog.Get(reservation) != nil {
+		t.Fatalf("dry run must not save: %v", err)
+	}
+
+	if _, err := runCLI(t, dir, "import", "csv", "--type", "ips", csvPath); err == nil {
+		t.Fatal("expected error for the row outside every Host Pool")
+	}
+	catalog, err = store.Load(dir)
+	if err != nil || catalog.Get(reservation) == nil {
+		t.Fatalf("valid row was not saved: %v", err)
+	}
+	if md, err := os.ReadFile(filepath.Join(dir, store.MarkdownFileName)); err != nil || !strings.Contains(string(md), "printer") {
+		t.Errorf("EZ-IPAM.md not regenerated: %v", err)
+	}
+
+	if _, err := runCLI(t, dir, "import", "csv", "--type", "networks", csvPath); err == nil {
+		t.Error("expected error for unknown import type")
+	}
+	if _, err := runCLI(t, dir, "import", "xlsx"); err == nil {
+		t.Error("expected error for unknown import source")
+	}
+}
+
+func TestImportLeasesCommand(t *testing.T) {
+	dir := newTestDir(t)
+	leasesPath := filepath.Join(t.TempDir(), "dnsmasq.leases")
+	leases := "1760000000 66:77:88:99:aa:bb 10.0.0.1 router *\n1760000000 aa:bb:cc:dd:ee:ff 10.0.0.50 tv *\n"
+	if err := os.WriteFile(leasesPath, []byte(leases), 0644); err != nil {
+		t.Fatal(err)
+	}
+	reservation := "Networks -> 10.0.0.0/16 -> 10.0.0.0/24 -> 10.0.0.50"
+
+	out, err := runCLI(t, dir, "import", "leases", "--format", "dnsmasq", "--reserve", "all", "--dry-run", leasesPath)
+	want := "undocumented\t10.0.0.50\taa:bb:cc:dd:ee:ff\ttv\t10.0.0.0/24\n" +
+		"mismatch\t10.0.0.1\t66:77:88:99:aa:bb\treserved 10.0.0.1 for 00:11:22:33:44:55 (gw)\n" +
+		"would reserve " + reservation + "\n"
+	if err != nil || out != want {
+		t.Fatalf("dry run output = %q, err = %v", out, err)
+	}
+	catalog, err := store.Load(dir)
+	if err != nil || catalog.Get(reservation) != nil {
+		t.Fatalf("dry run must not save: %v", err)
+	}
+
+	if _, err := runCLI(t, dir, "import", "leases", "--format", "dnsmasq", "--reserve", "10.0.0.1", leasesPath); err == nil {
+		t.Error("expected error for reserving a documented address")
+	}
+	if _, err := runCLI(t, dir, "import", "leases", "--format", "dnsmasq", "--reserve", "10.0.0.50", leasesPath); err != nil {
+		t.Fatalf("import leases --reserve error: %v", err)
+	}
+	catalog, err = store.Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ip, ok := catalog.Get(reservation).(*domain.IP); !ok || ip.DisplayName != "tv" || ip.Description != "Imported from dnsmasq.leases" {
+		t.Errorf("reservation = %+v, want tv imported from the lease file", ip)
+	}
+
+	if _, err := runCLI(t, dir, "import", "leases", "--format", "isc", leasesPath); err == nil {
+		t.Error("expected error for unknown lease format")
+	}
+}
+
+func TestExportCommand(t *testing.T) {
+	dir := newTestDir(t)
+
+	out, err := runCLI(t, dir, "export", "--format", "csv", "--table", "ips")
+	if err != nil || out != "path,address,network,name,mac,vlan_id,vlan_name,description\nNetworks -> 10.0.0.0/16 -> 10.0.0.0/24 -> 10.0.0.1,10.0.0.1,10.0.0.0/24,gw,00:11:22:33:44:55,,,\n" {
+		t.Errorf("unexpected CSV %q, %v", out, err)
+	}
+
+	out, err = runCLI(t, dir, "export")
+	if err != nil || !strings.Contains(out, `"parent_cidr": "10.0.0.0/16"`) {
+		t.Errorf("unexpected JSON %q, %v", out, err)
+	}
+
+	tablesDir := filepath.Join(t.TempDir(), "tables")
+	if _, err := runCLI(t, dir, "export", "--format", "csv", "--output", tablesDir); err != nil {
+		t.Fatalf("export --output error: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(tablesDir, "dns_records.csv")); err != nil {
+		t.Errorf("table not written: %v", err)
+	}
+
+	if _, err := runCLI(t, dir, "export", "--format", "csv"); err == nil {
+		t.Error("expected error for csv without a table or directory")
+	}
+	if _, err := runCLI(t, dir, "export", "--format", "xml"); err == nil {
+		t.Error("expected error for unknown format")
+	}
+}
@@ -0,0 +1,65 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/plumber-cd/ez-ipam/internal/domain"
+	"github.com/plumber-cd/ez-ipam/internal/export"
+)
+
+// runDHCP prints the static DHCP leases in a server's configuration format.
+// dhcpd needs IPv4 and IPv6 in separate files: either one family is
+// selected, or --output receives the IPv4 file and a sibling the IPv6 one.
+func runDHCP(dir string, args []string, stdout, stderr io.Writer) error {
+	fs := newFlagSet("dhcp", stderr)
+	format := fs.String("format", domain.DHCPFormatKea, "output format: "+strings.Join(domain.DHCPFormats, ", "))
+	under := fs.String("under", "", "limit the export to a network, given as a CIDR or full path")
+	family := fs.String("family", "", "limit the export to IPv4 (4) or IPv6 (6) pools")
+	output := fs.String("output", "", "write to this file instead of stdout; for dhcpd, IPv6 goes to the same name with a 6 appended")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *family != "" && *family != "4" && *family != "6" {
+		return fmt.Errorf("unknown address family %q: must be 4 or 6", *family)
+	}
+
+	catalog, err := load(dir, stderr)
+	if err != nil {
+		return err
+	}
+
+	var root domain.Item
+	if *under != "" {
+		if root, err = findNetwork(catalog, *under); err != nil {
+			return err
+		}
+	}
+	pools, err := export.StaticLeases(catalog, root)
+	if err != nil {
+		return err
+	}
+	if v4, v6 := export.SplitPoolsByFamily(pools); *family == "4" {
+		pools = v4
+	} else if *family == "6" {
+		pools = v6
+	}
+	if *format == domain.DHCPFormatDhcpd && *family == "" && *output != "" {
+		return export.WriteDhcpdFiles(*output, pools)
+	}
+	content, err := export.RenderDHCPLeases(pools, *format)
+	if errors.Is(err, export.ErrDhcpdMixedFamilies) {
+		return fmt.Errorf("%w: use --family 4 or 6, or --output to write both", err)
+	}
+	if err != nil {
+		return err
+	}
+	if *output != "" {
+		return os.WriteFile(*output, []byte(content), 0644)
+	}
+	_, err = io.WriteString(stdout, content)
+	return err
+}
@@ -0,0 +1,105 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/plumber-cd/ez-ipam/internal/domain"
+	"github.com/plumber-cd/ez-ipam/internal/export"
+)
+
+// runDNS exports the DNS records. The bind format checks the forward and
+// reverse zones and prints them, or writes one file per zone to a directory;
+// the local resolver formats print or write a single file. Addresses claimed
+// by several names and zones whose default name server has no address are
+// reported on stderr; --conflicts prints only the claimed addresses.
+func runDNS(dir string, args []string, stdout, stderr io.Writer) error {
+	fs := newFlagSet("dns", stderr)
+	format := fs.String("format", domain.DNSFormatBIND, "output format: "+strings.Join(domain.DNSFormats, ", "))
+	output := fs.String("output", "", "write to this file instead of stdout; a directory receiving one file per zone for bind")
+	conflictsOnly := fs.Bool("conflicts", false, "only list addresses claimed by more than one name")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if !slices.Contains(domain.DNSFormats, *format) {
+		return fmt.Errorf("unknown DNS format %q: must be one of %s", *format, strings.Join(domain.DNSFormats, ", "))
+	}
+
+	catalog, err := load(dir, stderr)
+	if err != nil {
+		return err
+	}
+	if *conflictsOnly {
+		_, conflicts, err := export.ReverseZones(catalog)
+		if err != nil {
+			return err
+		}
+		for _, conflict := range conflicts {
+			fmt.Fprintf(stdout, "%s\t%s\tPTR -> %s\n", conflict.Address, strings.Join(conflict.Names, ", "), conflict.Chosen)
+		}
+		return nil
+	}
+	if *format != domain.DNSFormatBIND {
+		return runLocalDNS(catalog, *format, *output, stdout, stderr)
+	}
+
+	zones, outside, err := export.DNSZones(catalog)
+	if err != nil {
+		return err
+	}
+	reverse, conflicts, err := export.ReverseZones(catalog)
+	if err != nil {
+		return err
+	}
+	for _, name := range outside {
+		fmt.Fprintf(stderr, "warning: %s is outside every configured zone\n", name)
+	}
+	for _, conflict := range conflicts {
+		fmt.Fprintf(stderr, "warning: %s is claimed by %s; PTR points to %s\n", conflict.Address, strings.Join(conflict.Names, ", "), conflict.Chosen)
+	}
+	zones = append(zones, reverse...)
+	d := catalog.Config.DNSDefaults()
+	for _, zone := range zones {
+		for _, warning := range export.ZoneWarnings(zone, d) {
+			fmt.Fprintf(stderr, "warning: %s\n", warning)
+		}
+	}
+
+	now := time.Now()
+	if *output != "" {
+		return export.WriteBINDZones(*output, catalog, zones, now)
+	}
+	for i, zone := range zones {
+		if i > 0 {
+			fmt.Fprintln(stdout)
+		}
+		if _, err := io.WriteString(stdout, export.RenderBINDZone(zone, d, uint32(now.Unix()))); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// runLocalDNS prints or writes the records in a local resolver format.
+func runLocalDNS(catalog *domain.Catalog, format, output string, stdout, stderr io.Writer) error {
+	records, err := export.LocalDNSRecords(catalog)
+	if err != nil {
+		return err
+	}
+	content, skipped, err := export.RenderLocalDNS(records, format, catalog.Config.DNSDefaults().TTL)
+	if err != nil {
+		return err
+	}
+	for _, name := range skipped {
+		fmt.Fprintf(stderr, "warning: CNAME %s has no known address and is left out\n", name)
+	}
+	if output != "" {
+		return os.WriteFile(output, []byte(content), 0644)
+	}
+	_, err = io.WriteString(stdout, content)
+	return err
+}
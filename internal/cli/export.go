@@ -0,0 +1,67 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/plumber-cd/ez-ipam/internal/export"
+)
+
+// runExport prints every catalog item as flat rows: one JSON or YAML
+// document, or a CSV table. Without --table, csv writes one <table>.csv per
+// table into the --output directory.
+func runExport(dir string, args []string, stdout, stderr io.Writer) error {
+	fs := newFlagSet("export", stderr)
+	format := fs.String("format", export.InventoryFormatJSON, "output format: "+strings.Join(export.InventoryFormats, ", "))
+	table := fs.String("table", "", "export a single table: "+strings.Join(export.InventoryTables, ", "))
+	output := fs.String("output", "", "write to this file instead of stdout; a directory for csv without --table")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if !slices.Contains(export.InventoryFormats, *format) {
+		return fmt.Errorf("unknown export format %q: must be one of %s", *format, strings.Join(export.InventoryFormats, ", "))
+	}
+	if *table != "" && !slices.Contains(export.InventoryTables, *table) {
+		return fmt.Errorf("unknown table %q: must be one of %s", *table, strings.Join(export.InventoryTables, ", "))
+	}
+
+	catalog, err := load(dir, stderr)
+	if err != nil {
+		return err
+	}
+	inv := export.BuildInventory(catalog)
+
+	if *format == export.InventoryFormatCSV && *table == "" {
+		if *output == "" {
+			return errors.New("csv needs --table, or --output with a directory for all tables")
+		}
+		if err := os.MkdirAll(*output, 0755); err != nil {
+			return err
+		}
+		for _, name := range export.InventoryTables {
+			content, err := export.RenderInventory(inv, *format, name)
+			if err != nil {
+				return err
+			}
+			if err := os.WriteFile(filepath.Join(*output, name+".csv"), []byte(content), 0644); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	content, err := export.RenderInventory(inv, *format, *table)
+	if err != nil {
+		return err
+	}
+	if *output != "" {
+		return os.WriteFile(*output, []byte(content), 0644)
+	}
+	_, err = io.WriteString(stdout, content)
+	return err
+}
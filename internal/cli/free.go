@@ -0,0 +1,56 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/plumber-cd/ez-ipam/internal/domain"
+)
+
+// runFree prints the free-space report.
+func runFree(dir string, args []string, stdout, stderr io.Writer) error {
+	fs := newFlagSet("free", stderr)
+	under := fs.String("under", "", "limit the report to a network, given as a CIDR or full path")
+	sortBy := fs.String("sort", "address", "sort order: address or size")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *sortBy != "address" && *sortBy != "size" {
+		return fmt.Errorf("invalid --sort %q: must be address or size", *sortBy)
+	}
+
+	catalog, err := load(dir, stderr)
+	if err != nil {
+		return err
+	}
+
+	var root domain.Item = catalog.GetByParentAndDisplayID(nil, domain.FolderNetworks)
+	if *under != "" {
+		if root, err = findNetwork(catalog, *under); err != nil {
+			return err
+		}
+	}
+
+	blocks, err := catalog.FreeSpace(root)
+	if err != nil {
+		return err
+	}
+	if *sortBy == "size" {
+		domain.SortFreeBlocksBySize(blocks)
+	}
+	return writeFreeSpaceReport(stdout, blocks)
+}
+
+func writeFreeSpaceReport(w io.Writer, blocks []domain.FreeBlock) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "CIDR\tADDRESSES\tPARENT")
+	for _, block := range blocks {
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", block.CIDR, block.Size, block.ParentPath)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "\nFree blocks: %d\n", len(blocks))
+	return nil
+}
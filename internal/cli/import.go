@@ -0,0 +1,211 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/plumber-cd/ez-ipam/internal/domain"
+	"github.com/plumber-cd/ez-ipam/internal/export"
+	"github.com/plumber-cd/ez-ipam/internal/importer"
+)
+
+const importUsage = "Usage: ez-ipam import csv [flags] FILE\n       ez-ipam import leases [flags] FILE\n"
+
+// runImport adds items read from a file to the catalog. The first argument
+// names the kind of file.
+func runImport(dir string, args []string, stdout, stderr io.Writer) error {
+	source := ""
+	if len(args) > 0 {
+		source, args = args[0], args[1:]
+	}
+	switch source {
+	case "csv":
+		return runImportCSV(dir, args, stdout, stderr)
+	case "leases":
+		return runImportLeases(dir, args, stdout, stderr)
+	case "-h", "--help", "help":
+		fmt.Fprint(stdout, importUsage)
+		return flag.ErrHelp
+	}
+	fmt.Fprint(stderr, importUsage)
+	if source == "" {
+		return errors.New("no import source given")
+	}
+	return fmt.Errorf("unknown import source %q", source)
+}
+
+// runImportCSV imports the rows of a CSV file and saves the catalog. Rows
+// that cannot be imported are reported on stderr and the others are kept;
+// with --dry-run nothing is saved.
+func runImportCSV(dir string, args []string, stdout, stderr io.Writer) error {
+	fs := newFlagSet("import csv", stderr)
+	importType := fs.String("type", "", "what each row is: "+strings.Join(importer.Types, ", "))
+	dryRun := fs.Bool("dry-run", false, "report what would be imported without saving")
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: ez-ipam import csv --type TYPE [--dry-run] FILE")
+		fmt.Fprintln(stderr)
+		fs.PrintDefaults()
+		fmt.Fprintln(stderr)
+		fmt.Fprintln(stderr, "The first row names the columns, in any order. Columns marked * are required.")
+		for _, t := range importer.Types {
+			fmt.Fprintf(stderr, "\n%s:\n", t)
+			for _, column := range importer.Columns[t] {
+				name := column.Name
+				if column.Required {
+					name += "*"
+				}
+				fmt.Fprintln(stderr, strings.TrimRight(fmt.Sprintf("  %-14s %s", name, column.Help), " "))
+			}
+		}
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if !slices.Contains(importer.Types, *importType) {
+		return fmt.Errorf("unknown import type %q: must be one of %s", *importType, strings.Join(importer.Types, ", "))
+	}
+	if fs.NArg() != 1 {
+		return errors.New("expected exactly one CSV file")
+	}
+	path := fs.Arg(0)
+
+	catalog, err := load(dir, stderr)
+	if err != nil {
+		return err
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = f.Close() }()
+	result, err := importer.CSV(catalog, *importType, f)
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+
+	verb := "added"
+	if *dryRun {
+		verb = "would add"
+	}
+	for _, item := range result.Added {
+		fmt.Fprintf(stdout, "%s %s\n", verb, item.GetPath())
+	}
+	for _, rowErr := range result.Errors {
+		fmt.Fprintf(stderr, "%s:%d: %v\n", path, rowErr.Line, rowErr.Err)
+	}
+	if !*dryRun && len(result.Added) > 0 {
+		if err := export.Save(dir, catalog); err != nil {
+			return err
+		}
+	}
+	if len(result.Errors) > 0 {
+		return fmt.Errorf("%d of %d rows could not be imported", len(result.Errors), result.Rows)
+	}
+	return nil
+}
+
+// runImportLeases compares a DHCP lease file with the reservations and
+// reports undocumented devices, MAC mismatches and stale reservations.
+// --reserve reserves undocumented devices and saves the catalog unless
+// --dry-run is given.
+func runImportLeases(dir string, args []string, stdout, stderr io.Writer) error {
+	fs := newFlagSet("import leases", stderr)
+	format := fs.String("format", domain.DHCPFormatKea, "lease file format: "+strings.Join(domain.DHCPFormats, ", "))
+	reserve := fs.String("reserve", "", "reserve undocumented devices: \"all\" or comma-separated addresses")
+	dryRun := fs.Bool("dry-run", false, "report what --reserve would reserve without saving")
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: ez-ipam import leases [--format FORMAT] [--reserve all|ADDRESSES] [--dry-run] FILE")
+		fmt.Fprintln(stderr)
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return errors.New("expected exactly one lease file")
+	}
+	path := fs.Arg(0)
+
+	catalog, err := load(dir, stderr)
+	if err != nil {
+		return err
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = f.Close() }()
+	leases, err := importer.ParseLeases(*format, f)
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+
+	report := importer.Reconcile(catalog, leases)
+	for _, lease := range report.Undocumented {
+		pool := "no Host Pool"
+		if n := catalog.HostPoolContaining(lease.Address); n != nil {
+			pool = n.ID
+		}
+		fmt.Fprintf(stdout, "undocumented\t%s\t%s\t%s\t%s\n", lease.Address, valueOrDash(lease.MAC), valueOrDash(lease.Hostname), pool)
+	}
+	for _, mismatch := range report.Mismatches {
+		ip := mismatch.Reservation
+		fmt.Fprintf(stdout, "mismatch\t%s\t%s\treserved %s for %s (%s)\n", mismatch.Lease.Address, mismatch.Lease.MAC, ip.ID, ip.MACAddress, ip.DisplayName)
+	}
+	for _, ip := range report.Stale {
+		fmt.Fprintf(stdout, "stale\t%s\t%s\t%s\n", ip.ID, ip.MACAddress, ip.DisplayName)
+	}
+	if *reserve == "" {
+		return nil
+	}
+
+	selected := report.Undocumented
+	if *reserve != "all" {
+		selected = nil
+		for _, text := range strings.Split(*reserve, ",") {
+			addr, err := netip.ParseAddr(strings.TrimSpace(text))
+			if err != nil {
+				return fmt.Errorf("invalid address %q in --reserve", text)
+			}
+			idx := slices.IndexFunc(report.Undocumented, func(lease importer.Lease) bool { return lease.Address == addr })
+			if idx < 0 {
+				return fmt.Errorf("%s is not an undocumented lease", addr)
+			}
+			selected = append(selected, report.Undocumented[idx])
+		}
+	}
+	result := importer.ReserveLeases(catalog, selected, "Imported from "+filepath.Base(path))
+	verb := "reserved"
+	if *dryRun {
+		verb = "would reserve"
+	}
+	for _, item := range result.Added {
+		fmt.Fprintf(stdout, "%s %s\n", verb, item.GetPath())
+	}
+	for _, rowErr := range result.Errors {
+		fmt.Fprintf(stderr, "%s:%d: %v\n", path, rowErr.Line, rowErr.Err)
+	}
+	if !*dryRun && len(result.Added) > 0 {
+		if err := export.Save(dir, catalog); err != nil {
+			return err
+		}
+	}
+	if len(result.Errors) > 0 {
+		return fmt.Errorf("%d of %d leases could not be reserved", len(result.Errors), result.Rows)
+	}
+	return nil
+}
+
+func valueOrDash(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
@@ -0,0 +1,157 @@
+package domain
+
+import (
+	"fmt"
+	"math/big"
+	"net/netip"
+	"slices"
+)
+
+// AddressBlock is one contiguous range of a Subnet Container's address space.
+type AddressBlock struct {
+	CIDR string
+	// Network is the child network covering the block (an implicit subnet for a
+	// split child), or nil for a gap no child covers.
+	Network *Network
+	// Offset is the distance of the first address from the start of the container.
+	Offset *big.Int
+	Size   *big.Int
+	// Reserved is the number of reserved IPs (Host Pool blocks only).
+	Reserved int
+}
+
+// Density returns the share of usable addresses that are reserved in a Host Pool block.
+func (b AddressBlock) Density() float64 {
+	if b.Network == nil || b.Network.AllocationMode != AllocationModeHosts || b.Reserved == 0 {
+		return 0
+	}
+	prefix, err := netip.ParsePrefix(b.CIDR)
+	if err != nil {
+		return 0
+	}
+	return min(percentOf(big.NewInt(int64(b.Reserved)), usableHostCount(prefix))/100, 1)
+}
+
+// AddressMap returns the address space of n as blocks ordered by address:
+// one per child network, one per subnet of a split child (see
+// Network.Split), plus maximal aligned CIDRs for any gaps between them.
+func (c *Catalog) AddressMap(n *Network) ([]AddressBlock, error) {
+	return addressMap(n, c.GetChildren)
+}
+
+// addressMap builds the AddressMap of n, taking the sorted children of an
+// item from children.
+func addressMap(n *Network, children func(Item) []Item) ([]AddressBlock, error) {
+	prefix, err := netip.ParsePrefix(n.ID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid CIDR %s: %w", n.ID, err)
+	}
+	isIPv4 := prefix.Addr().Is4()
+	base := IPToBigInt(prefix.Masked().Addr())
+	end := IPToBigInt(LastAddr(prefix))
+
+	var networks []*Network
+	for _, child := range children(n) {
+		if network, ok := child.(*Network); ok {
+			networks = append(networks, network)
+		}
+	}
+
+	blocks := []AddressBlock{}
+	addGaps := func(from, to *big.Int) {
+		for _, gap := range rangeToPrefixes(from, to, isIPv4) {
+			blocks = append(blocks, AddressBlock{
+				CIDR:   gap.String(),
+				Offset: new(big.Int).Sub(IPToBigInt(gap.Addr()), base),
+				Size:   prefixAddressCount(gap),
+			})
+		}
+	}
+
+	next := new(big.Int).Set(base)
+	for _, child := range networks {
+		childPrefix, err := netip.ParsePrefix(child.ID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid CIDR %s: %w", child.ID, err)
+		}
+		childStart := IPToBigInt(childPrefix.Masked().Addr())
+		if childStart.Cmp(next) > 0 {
+			addGaps(next, new(big.Int).Sub(childStart, big.NewInt(1)))
+		}
+
+		for _, subnet := range splitSubnets(n, child) {
+			subnetPrefix := netip.MustParsePrefix(subnet.ID)
+			blocks = append(blocks, AddressBlock{
+				CIDR:    subnet.ID,
+				Network: subnet,
+				Offset:  new(big.Int).Sub(IPToBigInt(subnetPrefix.Addr()), base),
+				Size:    prefixAddressCount(subnetPrefix),
+			})
+		}
+		block := AddressBlock{
+			CIDR:    child.ID,
+			Network: child,
+			Offset:  new(big.Int).Sub(childStart, base),
+			Size:    prefixAddressCount(childPrefix),
+		}
+		if child.AllocationMode == AllocationModeHosts {
+			for _, grandChild := range children(child) {
+				if _, ok := grandChild.(*IP); ok {
+					block.Reserved++
+				}
+			}
+		}
+		if child.SplitBits == 0 {
+			blocks = append(blocks, block)
+		}
+
+		childEnd := IPToBigInt(LastAddr(childPrefix))
+		if childEnd.Cmp(next) >= 0 {
+			next = new(big.Int).Add(childEnd, big.NewInt(1))
+		}
+	}
+	if next.Cmp(end) <= 0 {
+		addGaps(next, end)
+	}
+
+	slices.SortStableFunc(blocks, func(l, r AddressBlock) int {
+		return l.Offset.Cmp(r.Offset)
+	})
+	return blocks, nil
+}
+
+// rangeToPrefixes decomposes the inclusive range [from, to] into the minimal
+// list of aligned prefixes, in address order.
+func rangeToPrefixes(from, to *big.Int, isIPv4 bool) []netip.Prefix {
+	addrLen := 128
+	if isIPv4 {
+		addrLen = 32
+	}
+
+	var result []netip.Prefix
+	current := new(big.Int).Set(from)
+	one := big.NewInt(1)
+	for current.Cmp(to) <= 0 {
+		remaining := new(big.Int).Sub(to, current)
+		remaining.Add(remaining, one)
+
+		// Largest block aligned at current.
+		hostBits := int(current.TrailingZeroBits())
+		if current.Sign() == 0 {
+			hostBits = addrLen
+		}
+		// Shrink it until it fits in what is left of the range.
+		for hostBits > 0 && new(big.Int).Lsh(one, uint(hostBits)).Cmp(remaining) > 0 {
+			hostBits--
+		}
+
+		result = append(result, netip.PrefixFrom(BigIntToAddr(current, isIPv4), addrLen-hostBits))
+		current.Add(current, new(big.Int).Lsh(one, uint(hostBits)))
+	}
+	return result
+}
+
+// prefixAddressCount returns the number of addresses in prefix.
+func prefixAddressCount(prefix netip.Prefix) *big.Int {
+	return new(big.Int).Lsh(big.NewInt(1), uint(prefix.Addr().BitLen()-prefix.Bits()))
+}
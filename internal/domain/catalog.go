@@ -9,6 +9,17 @@ import (
 // Catalog holds the in-memory state of all IPAM entities.
 type Catalog struct {
 	items map[string]Item
+
+	// Config holds repository-wide settings.
+	Config Config
+
+	// Templates are the layout templates available for new Subnet Containers,
+	// ordered by name.
+	Templates []*LayoutTemplate
+
+	// LoadWarnings lists the stored DNS data problems Load accepted; see
+	// RecordDataError.
+	LoadWarnings []string
 }
 
 // NewCatalog creates an empty catalog.
@@ -44,6 +55,41 @@ func (c *Catalog) Remove(path string) {
 	delete(c.items, path)
 }
 
+// relocate moves item and its whole subtree under newParentPath. Every item
+// in the subtree, item included, gets the ID rename returns for it, and DNS
+// aliases pointing into the subtree follow. The caller validates the result.
+func (c *Catalog) relocate(item Item, newParentPath string, rename func(Item) string) {
+	moved := map[string]string{}
+	var visit func(it Item, parentPath string)
+	visit = func(it Item, parentPath string) {
+		children := c.GetChildren(it)
+		oldPath := it.GetPath()
+		delete(c.items, oldPath)
+		b := it.(interface{ base() *Base }).base()
+		b.ID = rename(it)
+		b.ParentPath = parentPath
+		c.items[it.GetPath()] = it
+		moved[oldPath] = it.GetPath()
+		for _, child := range children {
+			visit(child, it.GetPath())
+		}
+	}
+	visit(item, newParentPath)
+
+	for _, other := range c.items {
+		if record, ok := other.(*DNSRecord); ok {
+			if newPath, ok := moved[record.ReservedIPPath]; ok {
+				record.ReservedIPPath = newPath
+			}
+			for i, path := range record.ReservedIPPaths {
+				if newPath, ok := moved[path]; ok {
+					record.ReservedIPPaths[i] = newPath
+				}
+			}
+		}
+	}
+}
+
 // Get returns the item at the given path, or nil.
 func (c *Catalog) Get(path string) Item {
 	return c.items[path]
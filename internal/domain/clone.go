@@ -0,0 +1,130 @@
+package domain
+
+import (
+	"fmt"
+	"net/netip"
+	"strings"
+)
+
+// CloneOptions controls what Clone copies besides the allocation structure,
+// names and descriptions.
+type CloneOptions struct {
+	VLANs        bool
+	Reservations bool
+	// Placeholders maps placeholder names to values; {name} in copied names
+	// and descriptions is replaced with the value.
+	Placeholders map[string]string
+}
+
+// ParsePlaceholders parses placeholder assignments such as "site=ams, floor=2".
+// Assignments are separated by commas or whitespace.
+func ParsePlaceholders(text string) (map[string]string, error) {
+	placeholders := map[string]string{}
+	for _, field := range strings.FieldsFunc(text, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\t' || r == '\n'
+	}) {
+		key, value, ok := strings.Cut(field, "=")
+		key = strings.Trim(strings.TrimSpace(key), "{}")
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid placeholder %q: use name=value", field)
+		}
+		placeholders[key] = strings.TrimSpace(value)
+	}
+	return placeholders, nil
+}
+
+// CloneTargets returns the parents n can be cloned under, like MoveTargets
+// but including n's current parent.
+func (c *Catalog) CloneTargets(n *Network) []Item {
+	return c.placementTargets(n, true)
+}
+
+// Clone copies n and its allocated subtree into newCIDR, a free block of the
+// same size under newParent (the Networks folder or a Subnet Container).
+// Child networks and, optionally, IP reservations keep their offsets. MAC
+// addresses and DNS records are never copied. The copy of n is returned.
+func (n *Network) Clone(c *Catalog, newParent Item, newCIDR string, opts CloneOptions) (*Network, error) {
+	if newParent == nil {
+		return nil, fmt.Errorf("new parent must be set")
+	}
+	if isSameOrDescendant(newParent.GetPath(), n.GetPath()) {
+		return nil, fmt.Errorf("cannot clone %s into itself", n.ID)
+	}
+	if n.AllocationMode == AllocationModeUnallocated {
+		return nil, fmt.Errorf("only allocated networks can be cloned")
+	}
+	from, err := netip.ParsePrefix(n.ID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid CIDR %s: %w", n.ID, err)
+	}
+	newCIDR = strings.TrimSpace(newCIDR)
+	to, err := netip.ParsePrefix(newCIDR)
+	if err != nil {
+		return nil, fmt.Errorf("invalid CIDR %s: %w", newCIDR, err)
+	}
+	if to.Masked() != to {
+		return nil, fmt.Errorf("invalid CIDR %s: host bits must be zero (did you mean %s?)", newCIDR, to.Masked())
+	}
+	if to.Addr().Is4() != from.Addr().Is4() || to.Bits() != from.Bits() {
+		return nil, fmt.Errorf("%s must be a /%d of the same address family as %s", newCIDR, from.Bits(), n.ID)
+	}
+	if err := c.checkMoveTarget(newParent, to); err != nil {
+		return nil, fmt.Errorf("cannot clone %s: %w", n.ID, err)
+	}
+	// Keep the stored free blocks the carve replaces, so that an invalid copy
+	// can be undone without losing that space.
+	freeBlocks := c.freeBlocksOverlapping(newParent, to)
+	if _, err := c.CarveFreeBlock(newParent, to.String()); err != nil {
+		return nil, fmt.Errorf("cannot clone %s: %w", n.ID, err)
+	}
+
+	pairs := make([]string, 0, 2*len(opts.Placeholders))
+	for key, value := range opts.Placeholders {
+		pairs = append(pairs, "{"+key+"}", value)
+	}
+	replace := strings.NewReplacer(pairs...).Replace
+
+	var created []Item
+	var copySubtree func(src *Network, parentPath string) *Network
+	copySubtree = func(src *Network, parentPath string) *Network {
+		dst := &Network{
+			Base:           Base{ID: rebaseID(src.ID, from, to), ParentPath: parentPath},
+			AllocationMode: src.AllocationMode,
+			DisplayName:    replace(src.DisplayName),
+			Description:    replace(src.Description),
+			SplitBits:      src.SplitBits,
+		}
+		if opts.VLANs {
+			dst.VLANID = src.VLANID
+		}
+		rebaseHostPoolFields(dst, src, from, to)
+		c.Put(dst)
+		created = append(created, dst)
+		for _, child := range c.GetChildren(src) {
+			switch v := child.(type) {
+			case *Network:
+				copySubtree(v, dst.GetPath())
+			case *IP:
+				if opts.Reservations {
+					ip := &IP{
+						Base:        Base{ID: rebaseID(v.ID, from, to), ParentPath: dst.GetPath()},
+						DisplayName: replace(v.DisplayName),
+						Description: replace(v.Description),
+					}
+					c.Put(ip)
+					created = append(created, ip)
+				}
+			}
+		}
+		return dst
+	}
+	clone := copySubtree(n, newParent.GetPath())
+	for _, item := range created {
+		if err := item.Validate(c); err != nil {
+			c.Delete(clone)
+			c.restoreFreeBlocks(newParent, freeBlocks)
+			return nil, fmt.Errorf("cannot clone %s: %w", n.ID, err)
+		}
+	}
+	return clone, nil
+}
@@ -0,0 +1,169 @@
+package domain
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// DefaultPoolWarnThresholdPercent is used when no pool warning threshold is configured.
+const DefaultPoolWarnThresholdPercent = 90
+
+// Static DHCP lease export formats.
+const (
+	DHCPFormatKea     = "kea"
+	DHCPFormatDnsmasq = "dnsmasq"
+	DHCPFormatDhcpd   = "dhcpd"
+)
+
+// DHCPFormats lists the supported static DHCP lease export formats.
+var DHCPFormats = []string{DHCPFormatKea, DHCPFormatDnsmasq, DHCPFormatDhcpd}
+
+// DNS export formats.
+const (
+	DNSFormatBIND    = "bind"
+	DNSFormatUnbound = "unbound"
+	DNSFormatCoreDNS = "coredns"
+	DNSFormatPihole  = "pihole"
+	DNSFormatHosts   = "hosts"
+)
+
+// DNSFormats lists the supported DNS export formats.
+var DNSFormats = []string{DNSFormatBIND, DNSFormatUnbound, DNSFormatCoreDNS, DNSFormatPihole, DNSFormatHosts}
+
+// Defaults for zone files when DNSConfig leaves a value unset.
+const (
+	DefaultDNSTTL        = 3600
+	DefaultDNSSOARefresh = 3600
+	DefaultDNSSOARetry   = 900
+	DefaultDNSSOAExpire  = 1209600
+	DefaultDNSSOAMinimum = 300
+)
+
+// Config holds repository-wide settings stored alongside the catalog data.
+type Config struct {
+	// PoolWarnThresholdPercent flags host pools whose reserved share of usable
+	// addresses reaches this percentage. Zero means DefaultPoolWarnThresholdPercent.
+	PoolWarnThresholdPercent int `json:"pool_warn_threshold_percent,omitempty"`
+	// DHCPExports are static lease files written next to EZ-IPAM.md on every save.
+	DHCPExports []ExportTarget `json:"dhcp_exports,omitempty"`
+	// DNS holds the SOA, NS and TTL defaults of exported zones.
+	DNS *DNSConfig `json:"dns,omitempty"`
+	// DNSExports are DNS files written next to EZ-IPAM.md on every save. For
+	// the bind format, Path is a directory receiving one file per zone.
+	DNSExports []ExportTarget `json:"dns_exports,omitempty"`
+}
+
+// DNSConfig holds defaults for exported DNS zones. Unset values fall back to
+// the Default* constants; names default to ns1 and hostmaster in each zone.
+type DNSConfig struct {
+	// Zones are the zone apexes records are grouped into. When empty, zones
+	// are inferred from the parent domains of the exported names.
+	Zones       []string `json:"zones,omitempty"`
+	TTL         int      `json:"ttl,omitempty"`
+	PrimaryNS   string   `json:"primary_ns,omitempty"`
+	AdminEmail  string   `json:"admin_email,omitempty"`
+	NameServers []string `json:"name_servers,omitempty"`
+	Refresh     int      `json:"refresh,omitempty"`
+	Retry       int      `json:"retry,omitempty"`
+	Expire      int      `json:"expire,omitempty"`
+	Minimum     int      `json:"minimum,omitempty"`
+}
+
+// DNSDefaults returns the DNS settings with every unset number filled in.
+// Name defaults depend on the zone and are left empty.
+func (c Config) DNSDefaults() DNSConfig {
+	var d DNSConfig
+	if c.DNS != nil {
+		d = *c.DNS
+	}
+	for _, v := range []struct {
+		value    *int
+		fallback int
+	}{
+		{&d.TTL, DefaultDNSTTL},
+		{&d.Refresh, DefaultDNSSOARefresh},
+		{&d.Retry, DefaultDNSSOARetry},
+		{&d.Expire, DefaultDNSSOAExpire},
+		{&d.Minimum, DefaultDNSSOAMinimum},
+	} {
+		if *v.value == 0 {
+			*v.value = v.fallback
+		}
+	}
+	return d
+}
+
+// ExportTarget is a file rendered in a given format on every save. Path is
+// relative to the directory holding EZ-IPAM.md.
+type ExportTarget struct {
+	Format string `json:"format"`
+	Path   string `json:"path"`
+}
+
+// IsZero reports whether all settings are at their defaults.
+func (c Config) IsZero() bool {
+	return c.PoolWarnThresholdPercent == 0 && len(c.DHCPExports) == 0 && c.DNS == nil && len(c.DNSExports) == 0
+}
+
+// PoolWarnThreshold returns the effective pool warning threshold in percent.
+func (c Config) PoolWarnThreshold() int {
+	if c.PoolWarnThresholdPercent <= 0 {
+		return DefaultPoolWarnThresholdPercent
+	}
+	return c.PoolWarnThresholdPercent
+}
+
+// Validate checks that all settings are within range.
+func (c Config) Validate() error {
+	if c.PoolWarnThresholdPercent < 0 || c.PoolWarnThresholdPercent > 100 {
+		return fmt.Errorf("pool_warn_threshold_percent must be between 0 and 100, got %d", c.PoolWarnThresholdPercent)
+	}
+	for _, target := range c.DHCPExports {
+		if err := target.validate(DHCPFormats); err != nil {
+			return fmt.Errorf("dhcp_exports: %w", err)
+		}
+	}
+	if c.DNS != nil {
+		if err := c.DNS.validate(); err != nil {
+			return fmt.Errorf("dns: %w", err)
+		}
+	}
+	for _, target := range c.DNSExports {
+		if err := target.validate(DNSFormats); err != nil {
+			return fmt.Errorf("dns_exports: %w", err)
+		}
+	}
+	return nil
+}
+
+func (d *DNSConfig) validate() error {
+	for _, name := range append(append([]string{d.PrimaryNS, d.AdminEmail}, d.Zones...), d.NameServers...) {
+		if name == "" {
+			continue
+		}
+		if err := ValidateHostname(strings.Replace(strings.TrimSuffix(name, "."), "@", ".", 1)); err != nil {
+			return err
+		}
+	}
+	for _, v := range []struct {
+		name  string
+		value int
+	}{{"ttl", d.TTL}, {"refresh", d.Refresh}, {"retry", d.Retry}, {"expire", d.Expire}, {"minimum", d.Minimum}} {
+		if v.value < 0 {
+			return fmt.Errorf("%s must not be negative, got %d", v.name, v.value)
+		}
+	}
+	return nil
+}
+
+func (t ExportTarget) validate(formats []string) error {
+	if !slices.Contains(formats, t.Format) {
+		return fmt.Errorf("format %q must be one of %s", t.Format, strings.Join(formats, ", "))
+	}
+	if !filepath.IsLocal(t.Path) {
+		return fmt.Errorf("path %q must be a relative path inside the repository", t.Path)
+	}
+	return nil
+}
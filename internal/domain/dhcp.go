@@ -0,0 +1,230 @@
+package domain
+
+import (
+	"fmt"
+	"math/big"
+	"net/netip"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DHCPRangeOf returns the DHCP dynamic range of n that contains addr, or "".
+func (n *Network) DHCPRangeOf(addr netip.Addr) string {
+	prefix, err := netip.ParsePrefix(n.ID)
+	if err != nil {
+		return ""
+	}
+	for _, text := range n.DHCPRanges {
+		if r, err := ParseAddressRange(prefix, text); err == nil && r.Contains(addr) {
+			return text
+		}
+	}
+	return ""
+}
+
+// DHCPWarnings lists the static reservations of n that lie inside one of its
+// DHCP dynamic ranges. Such reservations are allowed, but the DHCP server
+// may hand the same address out dynamically.
+func (c *Catalog) DHCPWarnings(n *Network) []string {
+	if len(n.DHCPRanges) == 0 {
+		return nil
+	}
+	var warnings []string
+	for _, child := range c.GetChildren(n) {
+		ip, ok := child.(*IP)
+		if !ok {
+			continue
+		}
+		if warning := ip.DHCPWarning(c); warning != "" {
+			warnings = append(warnings, warning)
+		}
+	}
+	return warnings
+}
+
+// DHCPWarning describes why the reservation ip conflicts with a DHCP dynamic
+// range of its pool, or returns "" when it does not.
+func (i *IP) DHCPWarning(c *Catalog) string {
+	pool, ok := c.Get(i.ParentPath).(*Network)
+	if !ok {
+		return ""
+	}
+	addr, err := netip.ParseAddr(i.ID)
+	if err != nil {
+		return ""
+	}
+	r := pool.DHCPRangeOf(addr)
+	if r == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s (%s) is inside DHCP range %s", i.ID, i.DisplayName, r)
+}
+
+// DHCPRangeSize returns the number of addresses in the DHCP dynamic ranges of n.
+func (n *Network) DHCPRangeSize() *big.Int {
+	total := new(big.Int)
+	prefix, err := netip.ParsePrefix(n.ID)
+	if err != nil {
+		return total
+	}
+	for _, text := range n.DHCPRanges {
+		r, err := ParseAddressRange(prefix, text)
+		if err != nil {
+			continue
+		}
+		size := new(big.Int).Sub(IPToBigInt(r.End), IPToBigInt(r.Start))
+		total.Add(total, size.Add(size, big.NewInt(1)))
+	}
+	return total
+}
+
+// validateDHCPRanges checks that the DHCP ranges of n hold only usable hosts
+// of prefix and overlap neither each other, the excluded ranges nor the gateway.
+func (n *Network) validateDHCPRanges(prefix netip.Prefix) error {
+	ranges := make([]AddressRange, 0, len(n.DHCPRanges))
+	for _, text := range n.DHCPRanges {
+		r, err := ParseAddressRange(prefix, text)
+		if err != nil {
+			return fmt.Errorf("network %s: DHCP %w", n.ID, err)
+		}
+		if !isUsableHost(prefix, r.Start) || !isUsableHost(prefix, r.End) {
+			return fmt.Errorf("DHCP range %s of %s must only contain usable hosts", text, n.ID)
+		}
+		if gateway, err := netip.ParseAddr(n.Gateway); err == nil && r.Contains(gateway) {
+			return fmt.Errorf("DHCP range %s of %s contains the gateway %s", text, n.ID, n.Gateway)
+		}
+		for _, excludedText := range n.ExcludedRanges {
+			excluded, err := ParseAddressRange(prefix, excludedText)
+			if err == nil && rangesOverlap(r, excluded) {
+				return fmt.Errorf("DHCP range %s of %s overlaps excluded range %s", text, n.ID, excludedText)
+			}
+		}
+		ranges = append(ranges, r)
+	}
+	slices.SortFunc(ranges, func(l, r AddressRange) int { return l.Start.Compare(r.Start) })
+	for i := 1; i < len(ranges); i++ {
+		if rangesOverlap(ranges[i-1], ranges[i]) {
+			return fmt.Errorf("DHCP ranges %s and %s of %s overlap", ranges[i-1], ranges[i], n.ID)
+		}
+	}
+	return nil
+}
+
+func rangesOverlap(l, r AddressRange) bool {
+	return l.Start.Compare(r.End) <= 0 && r.Start.Compare(l.End) <= 0
+}
+
+// IsZero reports whether no option is set.
+func (o *DHCPOptions) IsZero() bool {
+	return o == nil || (o.Router == "" && len(o.DNSServers) == 0 && o.DomainName == "" &&
+		len(o.SearchDomains) == 0 && len(o.NTPServers) == 0 && o.LeaseTime == "" && len(o.Custom) == 0)
+}
+
+// EffectiveRouter returns the router option of n, falling back to its gateway.
+func (n *Network) EffectiveRouter() string {
+	if n.DHCPOptions != nil && n.DHCPOptions.Router != "" {
+		return n.DHCPOptions.Router
+	}
+	return n.Gateway
+}
+
+// LeaseDuration returns the parsed lease time, or zero when none is set.
+func (o *DHCPOptions) LeaseDuration() (time.Duration, error) {
+	if o == nil || o.LeaseTime == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(o.LeaseTime)
+	if err != nil {
+		return 0, fmt.Errorf("invalid lease time %q: %w", o.LeaseTime, err)
+	}
+	if d < time.Second {
+		return 0, fmt.Errorf("lease time %q must be at least one second", o.LeaseTime)
+	}
+	return d, nil
+}
+
+// ParseDHCPCustomOptions parses custom options such as "66=tftp.example.com,
+// 150=10.0.0.5". Options are separated by commas or newlines.
+func ParseDHCPCustomOptions(text string) ([]DHCPCustomOption, error) {
+	var options []DHCPCustomOption
+	for _, field := range strings.FieldsFunc(text, func(r rune) bool { return r == ',' || r == '\n' }) {
+		if strings.TrimSpace(field) == "" {
+			continue
+		}
+		codeText, value, ok := strings.Cut(field, "=")
+		code, err := strconv.Atoi(strings.TrimSpace(codeText))
+		if !ok || err != nil {
+			return nil, fmt.Errorf("invalid custom option %q: use code=value", strings.TrimSpace(field))
+		}
+		options = append(options, DHCPCustomOption{Code: code, Value: strings.TrimSpace(value)})
+	}
+	return options, nil
+}
+
+// FormatDHCPCustomOptions renders options in the form ParseDHCPCustomOptions reads.
+func FormatDHCPCustomOptions(options []DHCPCustomOption) string {
+	fields := make([]string, 0, len(options))
+	for _, option := range options {
+		fields = append(fields, fmt.Sprintf("%d=%s", option.Code, option.Value))
+	}
+	return strings.Join(fields, ", ")
+}
+
+// validateDHCPOptions checks the addresses, names and codes of the DHCP
+// options of n, a network of prefix.
+func (n *Network) validateDHCPOptions(prefix netip.Prefix) error {
+	o := n.DHCPOptions
+	if o == nil {
+		return nil
+	}
+	if o.Router != "" {
+		router, err := netip.ParseAddr(o.Router)
+		if err != nil {
+			return fmt.Errorf("invalid DHCP router %q for network %s: %w", o.Router, n.ID, err)
+		}
+		if !isUsableHost(prefix, router) {
+			return fmt.Errorf("DHCP router %s is not a usable host of %s", o.Router, n.ID)
+		}
+	}
+	for _, servers := range []struct {
+		label     string
+		addresses []string
+	}{{"DNS", o.DNSServers}, {"NTP", o.NTPServers}} {
+		for _, server := range servers.addresses {
+			addr, err := netip.ParseAddr(server)
+			if err != nil {
+				return fmt.Errorf("invalid %s server %q for network %s: %w", servers.label, server, n.ID, err)
+			}
+			if addr.Is4() != prefix.Addr().Is4() {
+				return fmt.Errorf("%s server %s of %s must be in the same address family as the network", servers.label, server, n.ID)
+			}
+		}
+	}
+	for _, domain := range append([]string{o.DomainName}, o.SearchDomains...) {
+		if domain == "" {
+			continue
+		}
+		if err := ValidateHostname(domain); err != nil {
+			return fmt.Errorf("invalid DHCP domain for network %s: %w", n.ID, err)
+		}
+	}
+	if _, err := o.LeaseDuration(); err != nil {
+		return fmt.Errorf("network %s: %w", n.ID, err)
+	}
+	seen := map[int]bool{}
+	for _, option := range o.Custom {
+		if option.Code < 1 || option.Code > 254 {
+			return fmt.Errorf("DHCP option code %d of %s must be between 1 and 254", option.Code, n.ID)
+		}
+		if seen[option.Code] {
+			return fmt.Errorf("DHCP option %d is set twice on %s", option.Code, n.ID)
+		}
+		seen[option.Code] = true
+		if option.Value == "" {
+			return fmt.Errorf("DHCP option %d of %s must have a value", option.Code, n.ID)
+		}
+	}
+	return nil
+}
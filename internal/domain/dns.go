@@ -0,0 +1,409 @@
+package domain
+
+import (
+	"fmt"
+	"net/netip"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// DNSRecordTypes lists the record types a DNS record can have.
+var DNSRecordTypes = []string{"A", "AAAA", "CNAME", "MX", "TXT", "SRV", "CAA", "NS", "PTR"}
+
+// CAATags lists the property tags of CAA records.
+var CAATags = []string{"issue", "issuewild", "iodef", "issuemail"}
+
+// maxTXTStringLen is the longest character string a TXT record can hold.
+const maxTXTStringLen = 255
+
+// maxTTL is the largest TTL RFC 2181 allows.
+const maxTTL = 1<<31 - 1
+
+// ValidateDNSName checks a domain name: a valid hostname of at most 253
+// characters with labels of at most 63. A trailing dot is allowed.
+func ValidateDNSName(name string) error {
+	name = strings.TrimSuffix(strings.TrimSpace(name), ".")
+	if len(name) > 253 {
+		return fmt.Errorf("name %q is longer than 253 characters", name)
+	}
+	for _, label := range strings.Split(name, ".") {
+		if len(label) > 63 {
+			return fmt.Errorf("label %q of %q is longer than 63 characters", label, name)
+		}
+	}
+	return ValidateHostname(name)
+}
+
+// ValidateOwnerName checks the name a DNS record is published under. Unlike a
+// hostname, its labels may hold underscores, as the owner names of SRV and
+// TXT records such as _sip._tcp.example.com and _dmarc.example.com do.
+func ValidateOwnerName(name string) error {
+	name = strings.TrimSuffix(strings.TrimSpace(name), ".")
+	if name == "" {
+		return fmt.Errorf("name must be set")
+	}
+	if len(name) > 253 {
+		return fmt.Errorf("name %q is longer than 253 characters", name)
+	}
+	for _, label := range strings.Split(name, ".") {
+		if label == "" {
+			return fmt.Errorf("name %q contains an empty label", name)
+		}
+		if len(label) > 63 {
+			return fmt.Errorf("label %q of %q is longer than 63 characters", label, name)
+		}
+		for _, r := range label {
+			if !isAlphaNumericRune(r) && r != '-' && r != '_' {
+				return fmt.Errorf("name %q contains unsupported character %q", name, string(r))
+			}
+		}
+		if label[0] == '-' || label[len(label)-1] == '-' {
+			return fmt.Errorf("label %q of %q must not start or end with a hyphen", label, name)
+		}
+	}
+	return nil
+}
+
+// RecordDataError is a DNS record problem that catalogs saved before record
+// data was checked may already hold: a value that does not match its type,
+// or a CNAME sharing its name with other data. Load reports these as
+// warnings instead of refusing the catalog.
+type RecordDataError struct {
+	err error
+}
+
+func (e *RecordDataError) Error() string { return e.err.Error() }
+
+func (e *RecordDataError) Unwrap() error { return e.err }
+
+// ValidateRecordValue checks value against the syntax of recordType:
+//
+//	A, AAAA          an IPv4 or IPv6 address
+//	CNAME            a domain name, which may hold "_" labels
+//	NS, PTR          a host name
+//	MX               "<priority> <mail server>"
+//	SRV              "<priority> <weight> <port> <target>", target "." for none
+//	TXT              text, or quoted strings, each at most 255 characters
+//	CAA              "<flags> <tag> <value>", such as 0 issue "letsencrypt.org"
+func ValidateRecordValue(recordType, value string) error {
+	recordType = strings.ToUpper(strings.TrimSpace(recordType))
+	value = strings.TrimSpace(value)
+	fields := strings.Fields(value)
+	switch recordType {
+	case "A", "AAAA":
+		addr, err := netip.ParseAddr(value)
+		if err != nil || addr.Zone() != "" || addr.Is4() != (recordType == "A") {
+			family := "IPv4"
+			if recordType == "AAAA" {
+				family = "IPv6"
+			}
+			return fmt.Errorf("%s record value %q must be an %s address", recordType, value, family)
+		}
+	case "CNAME":
+		if err := ValidateOwnerName(value); err != nil {
+			return fmt.Errorf("CNAME record target: %w", err)
+		}
+	case "NS", "PTR":
+		if err := ValidateDNSName(value); err != nil {
+			return fmt.Errorf("%s record target: %w", recordType, err)
+		}
+	case "MX":
+		if len(fields) != 2 {
+			return fmt.Errorf("MX record value %q must be \"<priority> <mail server>\"", value)
+		}
+		if err := validateUint16("MX priority", fields[0]); err != nil {
+			return err
+		}
+		if err := ValidateDNSName(fields[1]); err != nil {
+			return fmt.Errorf("MX mail server: %w", err)
+		}
+	case "SRV":
+		if len(fields) != 4 {
+			return fmt.Errorf("SRV record value %q must be \"<priority> <weight> <port> <target>\"", value)
+		}
+		for i, label := range []string{"SRV priority", "SRV weight", "SRV port"} {
+			if err := validateUint16(label, fields[i]); err != nil {
+				return err
+			}
+		}
+		if fields[3] != "." {
+			if err := ValidateDNSName(fields[3]); err != nil {
+				return fmt.Errorf("SRV target: %w", err)
+			}
+		}
+	case "TXT":
+		if _, err := ParseTXTStrings(value); err != nil {
+			return err
+		}
+	case "CAA":
+		return validateCAA(value)
+	case "":
+		return fmt.Errorf("record type must be set")
+	default:
+		return fmt.Errorf("unsupported record type %q: must be one of %s", recordType, strings.Join(DNSRecordTypes, ", "))
+	}
+	return nil
+}
+
+// ParseTXTStrings returns the character strings of a TXT value. A value
+// starting with a quote is a list of quoted strings, where \" and \\ are
+// escapes; any other value is a single unquoted string.
+func ParseTXTStrings(value string) ([]string, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil, fmt.Errorf("TXT record value must be set")
+	}
+	var parts []string
+	if !strings.HasPrefix(value, `"`) {
+		if strings.Contains(value, `"`) {
+			return nil, fmt.Errorf("TXT record value %q must be fully quoted when it contains quotes", value)
+		}
+		parts = []string{value}
+	} else {
+		rest := value
+		for rest != "" {
+			if rest[0] != '"' {
+				return nil, fmt.Errorf("TXT record value %q: expected a quoted string at %q", value, rest)
+			}
+			s, n, err := unquoteTXT(rest)
+			if err != nil {
+				return nil, fmt.Errorf("TXT record value %q: %w", value, err)
+			}
+			parts = append(parts, s)
+			rest = strings.TrimLeft(rest[n:], " \t")
+		}
+	}
+	for _, part := range parts {
+		if len(part) > maxTXTStringLen {
+			return nil, fmt.Errorf("TXT strings must not be longer than %d characters; split the value into several quoted strings", maxTXTStringLen)
+		}
+	}
+	return parts, nil
+}
+
+// unquoteTXT reads the quoted string at the start of text and returns it
+// with the number of bytes it took.
+func unquoteTXT(text string) (string, int, error) {
+	sb := new(strings.Builder)
+	for i := 1; i < len(text); i++ {
+		switch text[i] {
+		case '\\':
+			if i+1 == len(text) {
+				return "", 0, fmt.Errorf("unterminated escape")
+			}
+			i++
+			sb.WriteByte(text[i])
+		case '"':
+			return sb.String(), i + 1, nil
+		default:
+			sb.WriteByte(text[i])
+		}
+	}
+	return "", 0, fmt.Errorf("unterminated quoted string")
+}
+
+// validateCAA checks "<flags> <tag> <value>" with a known tag. The value may
+// be quoted; iodef values must be mailto: or http(s) URLs.
+func validateCAA(value string) error {
+	flags, rest, _ := strings.Cut(value, " ")
+	tag, tagValue, _ := strings.Cut(strings.TrimSpace(rest), " ")
+	tagValue = strings.TrimSpace(tagValue)
+	if tagValue == "" {
+		return fmt.Errorf("CAA record value %q must be \"<flags> <tag> <value>\"", value)
+	}
+	if n, err := strconv.ParseUint(flags, 10, 8); err != nil || (n != 0 && n != 128) {
+		return fmt.Errorf("CAA flags %q must be 0 or 128 (critical)", flags)
+	}
+	if !slices.Contains(CAATags, tag) {
+		return fmt.Errorf("CAA tag %q must be one of %s", tag, strings.Join(CAATags, ", "))
+	}
+	if strings.HasPrefix(tagValue, `"`) {
+		unquoted, n, err := unquoteTXT(tagValue)
+		if err != nil || n != len(tagValue) {
+			return fmt.Errorf("CAA value %q must be a single quoted string", tagValue)
+		}
+		tagValue = unquoted
+	}
+	if tag == "iodef" {
+		u, err := url.Parse(tagValue)
+		if err != nil || (u.Scheme != "mailto" && u.Scheme != "http" && u.Scheme != "https") {
+			return fmt.Errorf("CAA iodef value %q must be a mailto: or http(s) URL", tagValue)
+		}
+	}
+	return nil
+}
+
+func validateUint16(label, text string) error {
+	if _, err := strconv.ParseUint(text, 10, 16); err != nil {
+		return fmt.Errorf("%s %q must be a number between 0 and 65535", label, text)
+	}
+	return nil
+}
+
+// validateCNAMEExclusive checks that a CNAME is the only record for its name
+// (RFC 1034): a CNAME d may not share its name, ignoring case, with a
+// reservation hostname or another DNS record, and any other record d may not
+// share its name with a CNAME.
+func (c *Catalog) validateCNAMEExclusive(d *DNSRecord) error {
+	name := strings.TrimSuffix(d.ID, ".")
+	for _, item := range c.All() {
+		switch other := item.(type) {
+		case *IP:
+			if d.isCNAME() && strings.EqualFold(strings.TrimSuffix(other.DisplayName, "."), name) {
+				return fmt.Errorf("CNAME %s must be the only record for its name, but reserved IP %s is named %s", d.ID, other.ID, other.DisplayName)
+			}
+		case *DNSRecord:
+			if other == d || !strings.EqualFold(strings.TrimSuffix(other.ID, "."), name) {
+				continue
+			}
+			if d.isCNAME() {
+				return fmt.Errorf("CNAME %s must be the only record for its name, but DNS record %s has it too", d.ID, other.GetPath())
+			}
+			if other.isCNAME() {
+				return fmt.Errorf("%s is already a CNAME to %s", d.ID, other.RecordValue)
+			}
+		}
+	}
+	return nil
+}
+
+func (d *DNSRecord) isCNAME() bool {
+	return strings.EqualFold(strings.TrimSpace(d.RecordType), "CNAME")
+}
+
+// DNSRecordNamed returns the DNS record for name, ignoring case, wherever it
+// is stored: in the DNS folder or nested in a zone. It returns nil when there
+// is none.
+func (c *Catalog) DNSRecordNamed(name string) *DNSRecord {
+	name = strings.TrimSuffix(strings.TrimSpace(name), ".")
+	for _, item := range c.All() {
+		if d, ok := item.(*DNSRecord); ok && strings.EqualFold(strings.TrimSuffix(d.ID, "."), name) {
+			return d
+		}
+	}
+	return nil
+}
+
+// cnameNamed returns the CNAME record for name, or nil.
+func (c *Catalog) cnameNamed(name string) *DNSRecord {
+	for _, item := range c.All() {
+		if d, ok := item.(*DNSRecord); ok && d.isCNAME() && strings.EqualFold(d.ID, strings.TrimSuffix(name, ".")) {
+			return d
+		}
+	}
+	return nil
+}
+
+// AliasTargetPaths returns the reservation paths an alias lists.
+func (d *DNSRecord) AliasTargetPaths() []string {
+	var paths []string
+	for _, path := range append([]string{d.ReservedIPPath}, d.ReservedIPPaths...) {
+		if path = strings.TrimSpace(path); path != "" {
+			paths = append(paths, path)
+		}
+	}
+	return paths
+}
+
+// IsAlias reports whether d points at reservations instead of holding a
+// record type and value.
+func (d *DNSRecord) IsAlias() bool {
+	return len(d.AliasTargetPaths()) > 0 || strings.TrimSpace(d.ReservedHost) != ""
+}
+
+// AliasTargets returns the reservations an alias resolves to, in address
+// order: the listed ones, or every reservation named ReservedHost. Missing
+// listed reservations are left out.
+func (c *Catalog) AliasTargets(d *DNSRecord) []*IP {
+	var targets []*IP
+	if host := strings.TrimSuffix(strings.TrimSpace(d.ReservedHost), "."); host != "" {
+		for _, item := range c.All() {
+			if ip, ok := item.(*IP); ok && strings.EqualFold(ip.DisplayName, host) {
+				targets = append(targets, ip)
+			}
+		}
+	}
+	for _, path := range d.AliasTargetPaths() {
+		if ip, ok := c.Get(path).(*IP); ok && !slices.Contains(targets, ip) {
+			targets = append(targets, ip)
+		}
+	}
+	slices.SortFunc(targets, func(l, r *IP) int {
+		return netip.MustParseAddr(l.ID).Compare(netip.MustParseAddr(r.ID))
+	})
+	return targets
+}
+
+// ReservedHostnames returns the distinct hostnames of all reservations,
+// sorted and ignoring case.
+func (c *Catalog) ReservedHostnames() []string {
+	var names []string
+	for _, item := range c.All() {
+		if ip, ok := item.(*IP); ok && strings.TrimSpace(ip.DisplayName) != "" {
+			names = append(names, ip.DisplayName)
+		}
+	}
+	slices.SortFunc(names, func(l, r string) int {
+		return CompareNaturalNumberOrder(strings.ToLower(l), strings.ToLower(r))
+	})
+	return slices.CompactFunc(names, strings.EqualFold)
+}
+
+// validateTTL checks a TTL override; 0 means the default.
+func validateTTL(ttl int) error {
+	if ttl < 0 || ttl > maxTTL {
+		return fmt.Errorf("TTL must be between 0 and %d, got %d", maxTTL, ttl)
+	}
+	return nil
+}
+
+// ParseTTL parses an optional TTL in seconds; empty text is 0, the default.
+func ParseTTL(text string) (int, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return 0, nil
+	}
+	ttl, err := strconv.Atoi(text)
+	if err != nil {
+		return 0, fmt.Errorf("TTL %q must be a number of seconds", text)
+	}
+	return ttl, validateTTL(ttl)
+}
+
+// Contains reports whether name is the zone apex or one of its subdomains.
+func (z *DNSZone) Contains(name string) bool {
+	name = strings.ToLower(strings.TrimSuffix(strings.TrimSpace(name), "."))
+	zone := strings.ToLower(z.ID)
+	return name == zone || strings.HasSuffix(name, "."+zone)
+}
+
+// DNSZones returns the DNS zones, sorted by name.
+func (c *Catalog) DNSZones() []*DNSZone {
+	var zones []*DNSZone
+	for _, item := range c.All() {
+		if zone, ok := item.(*DNSZone); ok {
+			zones = append(zones, zone)
+		}
+	}
+	slices.SortFunc(zones, func(l, r *DNSZone) int { return l.Compare(r) })
+	return zones
+}
+
+// ZoneContaining returns the deepest DNS zone that contains name, or nil.
+func (c *Catalog) ZoneContaining(name string) *DNSZone {
+	var best *DNSZone
+	for _, zone := range c.DNSZones() {
+		if zone.Contains(name) && (best == nil || len(zone.ID) > len(best.ID)) {
+			best = zone
+		}
+	}
+	return best
+}
+
+// ZoneOf returns the DNS zone record is nested in, or nil.
+func (c *Catalog) ZoneOf(record *DNSRecord) *DNSZone {
+	zone, _ := c.Get(record.GetParentPath()).(*DNSZone)
+	return zone
+}
@@ -1,8 +1,12 @@
 package domain
 
 import (
+	"errors"
+	"fmt"
 	"math/big"
 	"net/netip"
+	"slices"
+	"strings"
 	"testing"
 )
 
@@ -257,16 +261,21 @@ func TestSplitNetwork(t *testing.T) {
 		name      string
 		cidr      string
 		newPrefix int
+		want      []string
 		wantCount int
 		wantErr   bool
 	}{
-		{"split_/24_to_/25", "10.0.0.0/24", 25, 2, false},
-		{"split_/24_to_/26", "10.0.0.0/24", 26, 4, false},
-		{"split_/16_to_/24", "10.0.0.0/16", 24, 256, false},
-		{"prefix_too_small", "10.0.0.0/24", 24, 0, true},
-		{"prefix_too_large", "10.0.0.0/24", 33, 0, true},
-		{"auto_prefix_zero", "10.0.0.0/24", 0, 2, false},
-		{"invalid_cidr", "invalid", 25, 0, true},
+		{"split_/24_to_/25", "10.0.0.0/24", 25, []string{"10.0.0.0/25", "10.0.0.128/25"}, 2, false},
+		{"split_/24_to_/26", "10.0.0.0/24", 26, nil, 4, false},
+		{"split_/16_to_/24", "10.0.0.0/16", 24, nil, 256, false},
+		{"split_/16_to_/26", "10.0.0.0/16", 26, nil, 1024, false},
+		{"split_/16_to_/27_lists_first_1024", "10.0.0.0/16", 27, nil, 1025, false},
+		{"split_/48_to_/64_lists_first_1024", "2001:db8::/48", 64, nil, 1024 + 6, false},
+		{"split_/0_to_/128", "::/0", 128, nil, 1024 + 118, false},
+		{"prefix_too_small", "10.0.0.0/24", 24, nil, 0, true},
+		{"prefix_too_large", "10.0.0.0/24", 33, nil, 0, true},
+		{"auto_prefix_zero", "10.0.0.0/24", 0, nil, 2, false},
+		{"invalid_cidr", "invalid", 25, nil, 0, true},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -278,10 +287,65 @@ func TestSplitNetwork(t *testing.T) {
 			if !tt.wantErr && len(got) != tt.wantCount {
 				t.Errorf("SplitNetwork(%q, %d) returned %d subnets, want %d", tt.cidr, tt.newPrefix, len(got), tt.wantCount)
 			}
+			if tt.want != nil && !slices.Equal(got, tt.want) {
+				t.Errorf("SplitNetwork(%q, %d) = %v, want %v", tt.cidr, tt.newPrefix, got, tt.want)
+			}
+		})
+	}
+
+	got, err := SplitNetwork("10.0.0.0/16", 27)
+	if err != nil {
+		t.Fatalf("SplitNetwork() error = %v", err)
+	}
+	if got[1023] != "10.0.127.224/27" || got[1024] != "10.0.128.0/17" {
+		t.Errorf("SplitNetwork() ends with %v, want the first 1024 subnets and 10.0.128.0/17", got[1022:])
+	}
+}
+
+func TestSplitNetworkSizes(t *testing.T) {
+	tests := []struct {
+		name    string
+		cidr    string
+		sizes   []int
+		want    []string
+		wantErr bool
+	}{
+		{"exact_tiling", "10.0.0.0/24", []int{26, 25, 26}, []string{"10.0.0.0/25", "10.0.0.128/26", "10.0.0.192/26"}, false},
+		{"with_leftover", "10.0.0.0/24", []int{27, 25}, []string{"10.0.0.0/25", "10.0.0.128/27", "10.0.0.160/27", "10.0.0.192/26"}, false},
+		{"ipv6", "2001:db8::/48", []int{64, 56}, []string{"2001:db8::/56", "2001:db8:0:100::/64", "2001:db8:0:101::/64", "2001:db8:0:102::/63", "2001:db8:0:104::/62", "2001:db8:0:108::/61", "2001:db8:0:110::/60", "2001:db8:0:120::/59", "2001:db8:0:140::/58", "2001:db8:0:180::/57", "2001:db8:0:200::/55", "2001:db8:0:400::/54", "2001:db8:0:800::/53", "2001:db8:0:1000::/52", "2001:db8:0:2000::/51", "2001:db8:0:4000::/50", "2001:db8:0:8000::/49"}, false},
+		{"too_much", "10.0.0.0/24", []int{25, 25, 26}, nil, true},
+		{"size_too_small", "10.0.0.0/24", []int{24, 25}, nil, true},
+		{"size_too_large", "10.0.0.0/24", []int{25, 33}, nil, true},
+		{"empty", "10.0.0.0/24", nil, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := SplitNetworkSizes(tt.cidr, tt.sizes)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("SplitNetworkSizes() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("SplitNetworkSizes() = %v, want %v", got, tt.want)
+			}
 		})
 	}
 }
 
+func TestParseSplitSizes(t *testing.T) {
+	got, err := ParseSplitSizes("10.0.0.0/24", "/25, 26 100h,2h")
+	if err != nil {
+		t.Fatalf("ParseSplitSizes() error = %v", err)
+	}
+	if want := []int{25, 26, 25, 31}; !slices.Equal(got, want) {
+		t.Errorf("ParseSplitSizes() = %v, want %v", got, want)
+	}
+	for _, text := range []string{"bad", "0h", "300h", "xh"} {
+		if _, err := ParseSplitSizes("10.0.0.0/24", text); err == nil {
+			t.Errorf("ParseSplitSizes(%q) expected error", text)
+		}
+	}
+}
+
 func TestSummarizeCIDRs(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -309,6 +373,21 @@ func TestSummarizeCIDRs(t *testing.T) {
 	}
 }
 
+func TestMergePrefixes(t *testing.T) {
+	var input []netip.Prefix
+	for _, s := range []string{"10.0.0.128/25", "10.0.1.0/24", "10.0.0.64/26", "10.0.3.0/24", "fd00::/65", "fd00:0:0:0:8000::/65"} {
+		input = append(input, netip.MustParsePrefix(s))
+	}
+	var got []string
+	for _, prefix := range mergePrefixes(input) {
+		got = append(got, prefix.String())
+	}
+	want := []string{"10.0.0.64/26", "10.0.0.128/25", "10.0.1.0/24", "10.0.3.0/24", "fd00::/64"}
+	if !slices.Equal(got, want) {
+		t.Errorf("mergePrefixes() = %v, want %v", got, want)
+	}
+}
+
 func TestFindSummarizableRange(t *testing.T) {
 	cidrs := []string{"10.0.0.0/26", "10.0.0.64/26", "10.0.0.128/26", "10.0.0.192/26"}
 
@@ -588,6 +667,39 @@ func TestDNSRecordValidate(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "valid_srv_owner_name",
+			record: &DNSRecord{
+				Base:        Base{ID: "_sip._tcp.example.com", ParentPath: FolderDNS},
+				RecordType:  "SRV",
+				RecordValue: "10 5 5060 sip.example.com",
+			},
+		},
+		{
+			name: "valid_txt_owner_name",
+			record: &DNSRecord{
+				Base:        Base{ID: "_dmarc.example.com", ParentPath: FolderDNS},
+				RecordType:  "TXT",
+				RecordValue: "v=DMARC1; p=none",
+			},
+		},
+		{
+			name: "invalid_address_record_owner_name",
+			record: &DNSRecord{
+				Base:        Base{ID: "_host.example.com", ParentPath: FolderDNS},
+				RecordType:  "A",
+				RecordValue: "10.0.0.10",
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid_alias_owner_name",
+			record: &DNSRecord{
+				Base:           Base{ID: "_gw.example.com", ParentPath: FolderDNS},
+				ReservedIPPath: ip.GetPath(),
+			},
+			wantErr: true,
+		},
 		{
 			name: "invalid_mode_both_set",
 			record: &DNSRecord{
@@ -753,3 +865,1346 @@ func TestBasePath(t *testing.T) {
 		t.Errorf("GetPath() = %q, want \"root\"", root.GetPath())
 	}
 }
+
+// ---------- address_map.go ----------
+
+func TestCatalogAddressMap(t *testing.T) {
+	c := NewCatalog()
+	c.Put(&StaticFolder{Base: Base{ID: "Networks"}, Index: 0})
+	container := &Network{Base: Base{ID: "10.0.0.0/24", ParentPath: "Networks"}, AllocationMode: AllocationModeSubnets}
+	c.Put(container)
+	pool := &Network{Base: Base{ID: "10.0.0.0/26", ParentPath: container.GetPath()}, AllocationMode: AllocationModeHosts}
+	c.Put(pool)
+	c.Put(&Network{Base: Base{ID: "10.0.0.128/25", ParentPath: container.GetPath()}})
+	c.Put(&IP{Base: Base{ID: "10.0.0.1", ParentPath: pool.GetPath()}, DisplayName: "gw"})
+
+	blocks, err := c.AddressMap(container)
+	if err != nil {
+		t.Fatalf("AddressMap() error = %v", err)
+	}
+	var got []string
+	for _, block := range blocks {
+		got = append(got, fmt.Sprintf("%s@%s/%s", block.CIDR, block.Offset, block.Size))
+	}
+	want := []string{"10.0.0.0/26@0/64", "10.0.0.64/26@64/64", "10.0.0.128/25@128/128"}
+	if !slices.Equal(got, want) {
+		t.Fatalf("AddressMap() = %v, want %v", got, want)
+	}
+	if blocks[0].Network != pool || blocks[0].Reserved != 1 {
+		t.Errorf("first block = %+v, want pool with 1 reservation", blocks[0])
+	}
+	if blocks[1].Network != nil {
+		t.Errorf("second block should be a gap, got %+v", blocks[1].Network)
+	}
+	if d := blocks[0].Density(); d <= 0 || d >= 0.1 {
+		t.Errorf("Density() = %v, want 1/62", d)
+	}
+}
+
+func TestRangeToPrefixes(t *testing.T) {
+	tests := []struct {
+		from, to string
+		want     []string
+	}{
+		{"10.0.0.0", "10.0.0.255", []string{"10.0.0.0/24"}},
+		{"10.0.0.1", "10.0.0.6", []string{"10.0.0.1/32", "10.0.0.2/31", "10.0.0.4/31", "10.0.0.6/32"}},
+		{"10.0.0.64", "10.0.0.255", []string{"10.0.0.64/26", "10.0.0.128/25"}},
+		{"::", "::ffff", []string{"::/112"}},
+	}
+	for _, tt := range tests {
+		from := netip.MustParseAddr(tt.from)
+		var got []string
+		for _, prefix := range rangeToPrefixes(IPToBigInt(from), IPToBigInt(netip.MustParseAddr(tt.to)), from.Is4()) {
+			got = append(got, prefix.String())
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("rangeToPrefixes(%s, %s) = %v, want %v", tt.from, tt.to, got, tt.want)
+		}
+	}
+}
+
+// ---------- utilization.go ----------
+
+func TestCatalogUtilization(t *testing.T) {
+	c := NewCatalog()
+	c.Put(&StaticFolder{Base: Base{ID: "Networks"}, Index: 0})
+	root := &Network{Base: Base{ID: "10.0.0.0/16", ParentPath: "Networks"}, AllocationMode: AllocationModeSubnets}
+	c.Put(root)
+	site := &Network{Base: Base{ID: "10.0.0.0/24", ParentPath: root.GetPath()}, AllocationMode: AllocationModeSubnets}
+	c.Put(site)
+	c.Put(&Network{Base: Base{ID: "10.0.1.0/24", ParentPath: root.GetPath()}})
+	c.Put(&Network{Base: Base{ID: "10.0.2.0/23", ParentPath: root.GetPath()}})
+	pool := &Network{Base: Base{ID: "10.0.0.0/30", ParentPath: site.GetPath()}, AllocationMode: AllocationModeHosts}
+	c.Put(pool)
+	c.Put(&IP{Base: Base{ID: "10.0.0.1", ParentPath: pool.GetPath()}, DisplayName: "a"})
+	c.Put(&IP{Base: Base{ID: "10.0.0.2", ParentPath: pool.GetPath()}, DisplayName: "b"})
+
+	u, err := c.Utilization(pool)
+	if err != nil {
+		t.Fatalf("Utilization(pool) error = %v", err)
+	}
+	if u.Reserved != 2 || u.Usable.Int64() != 2 || u.ReservedPercent() != 100 {
+		t.Errorf("pool utilization = %d/%s, want 2/2", u.Reserved, u.Usable)
+	}
+	if !c.IsNearlyExhausted(pool, u) {
+		t.Error("expected full pool to be nearly exhausted")
+	}
+	c.Config.PoolWarnThresholdPercent = 100
+	if !c.IsNearlyExhausted(pool, u) {
+		t.Error("expected full pool to reach a 100% threshold")
+	}
+
+	u, err = c.Utilization(root)
+	if err != nil {
+		t.Fatalf("Utilization(root) error = %v", err)
+	}
+	if u.Allocated.Int64() != 256 || u.Unallocated().Int64() != 65536-256 {
+		t.Errorf("root allocated = %s, unallocated = %s", u.Allocated, u.Unallocated())
+	}
+	if u.Reserved != 2 || u.Usable.Int64() != 2 {
+		t.Errorf("root rolled up reservations = %d/%s, want 2/2", u.Reserved, u.Usable)
+	}
+	// Uncovered space past 10.0.3.255 includes a /17, larger than any free child.
+	if u.LargestFree != "10.0.128.0/17" {
+		t.Errorf("root LargestFree = %q, want 10.0.128.0/17", u.LargestFree)
+	}
+
+	u, err = c.Utilization(site)
+	if err != nil {
+		t.Fatalf("Utilization(site) error = %v", err)
+	}
+	if u.LargestFree != "10.0.0.128/25" || u.AllocatedPercent() != 1.5625 {
+		t.Errorf("site LargestFree = %q, allocated = %v%%", u.LargestFree, u.AllocatedPercent())
+	}
+
+	if c.IsNearlyExhausted(site, u) {
+		t.Error("containers are never flagged as exhausted")
+	}
+
+	usage := c.NewUtilizationIndex()
+	for _, n := range []*Network{root, site, pool} {
+		want, _ := c.Utilization(n)
+		got, err := usage.Utilization(n)
+		if err != nil || got.Reserved != want.Reserved || got.Allocated.Cmp(want.Allocated) != 0 || got.LargestFree != want.LargestFree {
+			t.Errorf("index Utilization(%s) = %+v, %v; want %+v", n.ID, got, err, want)
+		}
+	}
+	// The index keeps what it computed until it is rebuilt.
+	c.Put(&IP{Base: Base{ID: "10.0.0.3", ParentPath: pool.GetPath()}, DisplayName: "c"})
+	if u, _ := usage.Utilization(root); u.Reserved != 2 {
+		t.Errorf("memoized root reservations = %d, want 2", u.Reserved)
+	}
+	if u, _ := c.NewUtilizationIndex().Utilization(root); u.Reserved != 3 {
+		t.Errorf("rebuilt root reservations = %d, want 3", u.Reserved)
+	}
+}
+
+func TestConfigValidate(t *testing.T) {
+	if err := (Config{PoolWarnThresholdPercent: 101}).Validate(); err == nil {
+		t.Error("expected error for threshold above 100")
+	}
+	if got := (Config{}).PoolWarnThreshold(); got != DefaultPoolWarnThresholdPercent {
+		t.Errorf("PoolWarnThreshold() = %d, want default", got)
+	}
+	if !(Config{}).IsZero() || (Config{DHCPExports: []ExportTarget{{Format: DHCPFormatKea, Path: "kea.json"}}}).IsZero() {
+		t.Error("IsZero() should only hold for default settings")
+	}
+	for _, target := range []ExportTarget{
+		{Format: "isc", Path: "dhcpd.conf"},
+		{Format: DHCPFormatDhcpd, Path: ""},
+		{Format: DHCPFormatDhcpd, Path: "../dhcpd.conf"},
+		{Format: DHCPFormatDhcpd, Path: "/etc/dhcpd.conf"},
+	} {
+		if err := (Config{DHCPExports: []ExportTarget{target}}).Validate(); err == nil {
+			t.Errorf("expected error for %+v", target)
+		}
+	}
+	if err := (Config{DNS: &DNSConfig{AdminEmail: "ops@example.com", PrimaryNS: "ns1.example.com."}}).Validate(); err != nil {
+		t.Errorf("valid DNS settings rejected: %v", err)
+	}
+	if err := (Config{DNS: &DNSConfig{TTL: -1}}).Validate(); err == nil {
+		t.Error("expected error for negative TTL")
+	}
+	if err := (Config{DNS: &DNSConfig{Zones: []string{"bad_zone"}}}).Validate(); err == nil {
+		t.Error("expected error for invalid zone name")
+	}
+	if d := (Config{DNS: &DNSConfig{TTL: 60}}).DNSDefaults(); d.TTL != 60 || d.Minimum != DefaultDNSSOAMinimum {
+		t.Errorf("DNSDefaults() = %+v", d)
+	}
+}
+
+// ---------- free_space.go ----------
+
+func TestCatalogFreeSpace(t *testing.T) {
+	c := NewCatalog()
+	networks := &StaticFolder{Base: Base{ID: "Networks"}, Index: 0}
+	c.Put(networks)
+	root := &Network{Base: Base{ID: "10.0.0.0/16", ParentPath: "Networks"}, AllocationMode: AllocationModeSubnets}
+	c.Put(root)
+	// 10.0.0.0/24 allocated; 10.0.1.0/24, 10.0.2.0/23 and 10.0.4.0/22 free and contiguous.
+	c.Put(&Network{Base: Base{ID: "10.0.0.0/24", ParentPath: root.GetPath()}, AllocationMode: AllocationModeHosts})
+	c.Put(&Network{Base: Base{ID: "10.0.1.0/24", ParentPath: root.GetPath()}})
+	c.Put(&Network{Base: Base{ID: "10.0.2.0/23", ParentPath: root.GetPath()}})
+	c.Put(&Network{Base: Base{ID: "10.0.4.0/22", ParentPath: root.GetPath()}})
+	site := &Network{Base: Base{ID: "10.0.8.0/21", ParentPath: root.GetPath()}, AllocationMode: AllocationModeSubnets}
+	c.Put(site)
+	c.Put(&Network{Base: Base{ID: "10.0.8.0/22", ParentPath: site.GetPath()}, AllocationMode: AllocationModeHosts})
+	c.Put(&Network{Base: Base{ID: "10.0.12.0/22", ParentPath: site.GetPath()}})
+	c.Put(&Network{Base: Base{ID: "10.0.16.0/20", ParentPath: root.GetPath()}})
+	c.Put(&Network{Base: Base{ID: "10.0.32.0/19", ParentPath: root.GetPath()}})
+	c.Put(&Network{Base: Base{ID: "10.0.64.0/18", ParentPath: root.GetPath()}})
+	c.Put(&Network{Base: Base{ID: "10.0.128.0/17", ParentPath: root.GetPath()}})
+	c.Put(&Network{Base: Base{ID: "fd00::/64", ParentPath: "Networks"}})
+
+	blocks, err := c.FreeSpace(networks)
+	if err != nil {
+		t.Fatalf("FreeSpace() error = %v", err)
+	}
+	var got []string
+	for _, block := range blocks {
+		got = append(got, block.CIDR)
+	}
+	want := []string{"10.0.1.0/24", "10.0.2.0/23", "10.0.4.0/22", "10.0.12.0/22", "10.0.16.0/20", "10.0.32.0/19", "10.0.64.0/18", "10.0.128.0/17", "fd00::/64"}
+	if !slices.Equal(got, want) {
+		t.Fatalf("FreeSpace() = %v, want %v", got, want)
+	}
+	if blocks[3].ParentPath != site.GetPath() {
+		t.Errorf("10.0.12.0/22 ParentPath = %q, want %q", blocks[3].ParentPath, site.GetPath())
+	}
+
+	SortFreeBlocksBySize(blocks)
+	if blocks[0].CIDR != "fd00::/64" || blocks[1].CIDR != "10.0.128.0/17" {
+		t.Errorf("SortFreeBlocksBySize() starts with %s, %s", blocks[0].CIDR, blocks[1].CIDR)
+	}
+
+	blocks, err = c.FreeSpace(site)
+	if err != nil {
+		t.Fatalf("FreeSpace(site) error = %v", err)
+	}
+	if len(blocks) != 1 || blocks[0].CIDR != "10.0.12.0/22" {
+		t.Errorf("FreeSpace(site) = %+v, want only 10.0.12.0/22", blocks)
+	}
+}
+
+// ---------- implicit_networks.go ----------
+
+func TestCatalogChildrenWithFreeSpace(t *testing.T) {
+	c := NewCatalog()
+	c.Put(&StaticFolder{Base: Base{ID: "Networks"}, Index: 0})
+	container := &Network{Base: Base{ID: "2001:db8::/48", ParentPath: "Networks"}, AllocationMode: AllocationModeSubnets}
+	c.Put(container)
+	c.Put(&Network{Base: Base{ID: "2001:db8:0:1::/64", ParentPath: container.GetPath()}, AllocationMode: AllocationModeHosts, DisplayName: "LAN"})
+
+	children := c.ChildrenWithFreeSpace(container)
+	var got []string
+	for _, child := range children {
+		got = append(got, child.(*Network).ID)
+	}
+	if len(got) != 17 || got[0] != "2001:db8::/64" || got[1] != "2001:db8:0:1::/64" || got[2] != "2001:db8:0:2::/63" {
+		t.Fatalf("ChildrenWithFreeSpace() = %v", got)
+	}
+	if len(c.GetChildren(container)) != 1 {
+		t.Error("implicit networks must not be stored")
+	}
+
+	implicit, ok := c.Lookup(container.GetPath() + " -> 2001:db8:0:2::/63").(*Network)
+	if !ok || implicit.AllocationMode != AllocationModeUnallocated || !c.IsImplicit(implicit) {
+		t.Fatalf("Lookup() = %#v, want implicit unallocated network", implicit)
+	}
+	if c.Lookup(container.GetPath()+" -> 2001:db8:0:2::/64") != nil {
+		t.Error("Lookup() should not resolve a CIDR that is not a free block")
+	}
+	if err := c.Materialize(implicit); err != nil {
+		t.Fatalf("Materialize() error = %v", err)
+	}
+	if c.IsImplicit(implicit) || len(c.GetChildren(container)) != 2 {
+		t.Error("Materialize() should store the network")
+	}
+
+	if got := c.ChildrenWithFreeSpace(c.Get("Networks")); len(got) != 1 {
+		t.Errorf("Networks folder children = %d, want 1", len(got))
+	}
+}
+
+func TestCatalogCarveFreeBlock(t *testing.T) {
+	c := NewCatalog()
+	c.Put(&StaticFolder{Base: Base{ID: "Networks"}, Index: 0})
+	container := &Network{Base: Base{ID: "10.2.0.0/23", ParentPath: "Networks"}, AllocationMode: AllocationModeSubnets}
+	c.Put(container)
+	c.Put(&Network{Base: Base{ID: "10.2.0.0/24", ParentPath: container.GetPath()}})
+	c.Put(&Network{Base: Base{ID: "10.2.1.0/24", ParentPath: container.GetPath()}, AllocationMode: AllocationModeHosts})
+
+	n, err := c.CarveFreeBlock(container, "10.2.0.64/26")
+	if err != nil {
+		t.Fatalf("CarveFreeBlock() error = %v", err)
+	}
+	if !c.IsImplicit(n) || n.ID != "10.2.0.64/26" {
+		t.Fatalf("CarveFreeBlock() = %+v, want implicit 10.2.0.64/26", n)
+	}
+	var got []string
+	for _, child := range c.GetChildren(container) {
+		got = append(got, child.(*Network).ID)
+	}
+	want := []string{"10.2.0.0/26", "10.2.0.128/25", "10.2.1.0/24"}
+	if !slices.Equal(got, want) {
+		t.Errorf("stored children = %v, want %v", got, want)
+	}
+	if _, err := c.CarveFreeBlock(container, "10.2.1.0/25"); err == nil {
+		t.Error("expected error when carving from an allocated network")
+	}
+}
+
+func TestNetworkSplit(t *testing.T) {
+	c := NewCatalog()
+	c.Put(&StaticFolder{Base: Base{ID: "Networks"}, Index: 0})
+	root := &Network{Base: Base{ID: "2001:db8::/32", ParentPath: "Networks"}}
+	c.Put(root)
+
+	if err := root.Split(c, 64); err != nil {
+		t.Fatalf("Split() error = %v", err)
+	}
+	if len(c.All()) != 2 || root.SplitBits != 64 {
+		t.Fatalf("Split() stored %d items, SplitBits %d; want only the split network", len(c.All()), root.SplitBits)
+	}
+	children := c.ChildrenWithFreeSpace(c.Get("Networks"))
+	if len(children) != 1024+22 || children[0].(*Network).ID != "2001:db8::/64" || !c.IsImplicit(children[0].(*Network)) {
+		t.Fatalf("ChildrenWithFreeSpace() listed %d networks starting with %v", len(children), children[0])
+	}
+
+	second, ok := c.Lookup("Networks -> 2001:db8:0:1::/64").(*Network)
+	if !ok {
+		t.Fatal("Lookup() should resolve a subnet of a split network")
+	}
+	if c.Lookup("Networks -> 2001:db8:0:1::/63") != nil {
+		t.Error("Lookup() should not resolve a CIDR that is not a listed subnet")
+	}
+	if err := second.Split(c, 0); err != nil {
+		t.Fatalf("Split() of a listed subnet error = %v", err)
+	}
+	var got []string
+	for _, child := range c.GetChildren(c.Get("Networks")) {
+		n := child.(*Network)
+		got = append(got, fmt.Sprintf("%s/%d", n.ID, n.SplitBits))
+	}
+	if len(got) != 33 || got[0] != "2001:db8::/64/0" || got[1] != "2001:db8:0:1::/64/65" || got[2] != "2001:db8:0:2::/63/64" {
+		t.Errorf("stored networks = %v, want the carved subnet between split leftovers", got)
+	}
+	listed := c.ChildrenWithFreeSpace(c.Get("Networks"))
+	if listed[1].(*Network).ID != "2001:db8:0:1::/65" || listed[2].(*Network).ID != "2001:db8:0:1:8000::/65" {
+		t.Errorf("ChildrenWithFreeSpace() = %v, %v; want the halves of the second /64", listed[1], listed[2])
+	}
+
+	allocated := &Network{Base: Base{ID: "2001:db9::/32", ParentPath: "Networks"}, AllocationMode: AllocationModeHosts, DisplayName: "LAN"}
+	c.Put(allocated)
+	if err := allocated.Split(c, 48); err == nil {
+		t.Error("expected error when splitting an allocated network")
+	}
+	small := &Network{Base: Base{ID: "10.0.0.0/31", ParentPath: "Networks"}}
+	c.Put(small)
+	if err := small.Split(c, 0); err == nil || small.SplitBits != 0 {
+		t.Error("expected error when splitting into single addresses")
+	}
+}
+
+// ---------- vlsm.go ----------
+
+func TestParseSubnetRequirements(t *testing.T) {
+	reqs, err := ParseSubnetRequirements("# site plan\nusers /22 100\n2x servers /24\n\nmgmt 25 30 container\n")
+	if err != nil {
+		t.Fatalf("ParseSubnetRequirements() error = %v", err)
+	}
+	want := []SubnetRequirement{
+		{Name: "users", PrefixLen: 22, VLANID: 100},
+		{Name: "servers-1", PrefixLen: 24},
+		{Name: "servers-2", PrefixLen: 24},
+		{Name: "mgmt", HostCount: 25, VLANID: 30, Container: true},
+	}
+	if !slices.Equal(reqs, want) {
+		t.Errorf("ParseSubnetRequirements() = %+v, want %+v", reqs, want)
+	}
+
+	for _, text := range []string{"", "users", "users /x", "users -5", "users /24 9999", "0x users /24"} {
+		if _, err := ParseSubnetRequirements(text); err == nil {
+			t.Errorf("ParseSubnetRequirements(%q) expected error", text)
+		}
+	}
+}
+
+func TestCatalogPlanSubnets(t *testing.T) {
+	c := NewCatalog()
+	c.Put(&StaticFolder{Base: Base{ID: "Networks"}, Index: 0})
+	c.Put(&StaticFolder{Base: Base{ID: "VLANs"}, Index: 1})
+	c.Put(&VLAN{Base: Base{ID: "100", ParentPath: "VLANs"}, DisplayName: "Users"})
+	container := &Network{Base: Base{ID: "10.1.0.0/20", ParentPath: "Networks"}, AllocationMode: AllocationModeSubnets}
+	c.Put(container)
+	c.Put(&Network{Base: Base{ID: "10.1.0.0/24", ParentPath: container.GetPath()}, AllocationMode: AllocationModeHosts})
+	c.Put(&Network{Base: Base{ID: "10.1.8.0/21", ParentPath: container.GetPath()}})
+
+	reqs, err := ParseSubnetRequirements("users /22 100\n2x servers /24\n4x mgmt 20\np2p /30")
+	if err != nil {
+		t.Fatalf("ParseSubnetRequirements() error = %v", err)
+	}
+	plan, err := c.PlanSubnets(container, reqs)
+	if err != nil {
+		t.Fatalf("PlanSubnets() error = %v", err)
+	}
+	var got []string
+	for _, p := range plan {
+		got = append(got, p.CIDR+"="+p.Name)
+	}
+	want := []string{
+		"10.1.1.0/24=servers-1", "10.1.2.0/24=servers-2",
+		"10.1.3.0/27=mgmt-1", "10.1.3.32/27=mgmt-2", "10.1.3.64/27=mgmt-3", "10.1.3.96/27=mgmt-4",
+		"10.1.3.128/30=p2p", "10.1.4.0/22=users",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("PlanSubnets() = %v, want %v", got, want)
+	}
+
+	if _, err := c.PlanSubnets(container, []SubnetRequirement{{Name: "big", PrefixLen: 19}}); err == nil {
+		t.Error("expected error for a subnet larger than the container")
+	}
+	if _, err := c.PlanSubnets(container, []SubnetRequirement{{Name: "x", PrefixLen: 22}, {Name: "y", PrefixLen: 21}, {Name: "z", PrefixLen: 21}}); err == nil {
+		t.Error("expected error when the container runs out of space")
+	}
+	if _, err := c.PlanSubnets(container, []SubnetRequirement{{Name: "v", PrefixLen: 24, VLANID: 200}}); err == nil {
+		t.Error("expected error for unknown VLAN")
+	}
+}
+
+func TestCatalogApplySubnetPlan(t *testing.T) {
+	c := NewCatalog()
+	c.Put(&StaticFolder{Base: Base{ID: "Networks"}, Index: 0})
+	c.Put(&StaticFolder{Base: Base{ID: "VLANs"}, Index: 1})
+	c.Put(&VLAN{Base: Base{ID: "100", ParentPath: "VLANs"}, DisplayName: "Users"})
+	container := &Network{Base: Base{ID: "10.1.0.0/20", ParentPath: "Networks"}, AllocationMode: AllocationModeSubnets}
+	c.Put(container)
+	c.Put(&Network{Base: Base{ID: "10.1.0.0/24", ParentPath: container.GetPath()}, AllocationMode: AllocationModeHosts, DisplayName: "lan"})
+	c.Put(&Network{Base: Base{ID: "10.1.8.0/21", ParentPath: container.GetPath()}})
+	before := len(c.All())
+
+	users := PlannedSubnet{SubnetRequirement: SubnetRequirement{Name: "users", VLANID: 100}, CIDR: "10.1.4.0/22"}
+	for _, bad := range []PlannedSubnet{
+		{SubnetRequirement: SubnetRequirement{Name: ""}, CIDR: "10.1.8.0/24"},
+		{SubnetRequirement: SubnetRequirement{Name: "v", VLANID: 200}, CIDR: "10.1.8.0/24"},
+		{SubnetRequirement: SubnetRequirement{Name: "lan2"}, CIDR: "10.1.0.0/25"},
+		{SubnetRequirement: SubnetRequirement{Name: "dup"}, CIDR: "10.1.5.0/24"},
+		{SubnetRequirement: SubnetRequirement{Name: "outside"}, CIDR: "10.2.0.0/24"},
+	} {
+		if err := c.ApplySubnetPlan(container, []PlannedSubnet{users, bad}); err == nil {
+			t.Errorf("ApplySubnetPlan() with %+v: expected error", bad)
+		}
+		if len(c.All()) != before || c.Get(container.GetPath()+" -> 10.1.4.0/22") != nil {
+			t.Fatalf("ApplySubnetPlan() with %+v changed the catalog", bad)
+		}
+	}
+
+	mgmt := PlannedSubnet{SubnetRequirement: SubnetRequirement{Name: "mgmt", Container: true}, CIDR: "10.1.8.0/24"}
+	if err := c.ApplySubnetPlan(container, []PlannedSubnet{users, mgmt}); err != nil {
+		t.Fatalf("ApplySubnetPlan() error = %v", err)
+	}
+	if n, ok := c.Get(container.GetPath() + " -> 10.1.4.0/22").(*Network); !ok || n.AllocationMode != AllocationModeHosts || n.VLANID != 100 {
+		t.Errorf("users = %+v, want a Host Pool on VLAN 100", n)
+	}
+	if n, ok := c.Get(container.GetPath() + " -> 10.1.8.0/24").(*Network); !ok || n.AllocationMode != AllocationModeSubnets {
+		t.Errorf("mgmt = %+v, want a Subnet Container", n)
+	}
+	if c.Get(container.GetPath()+" -> 10.1.8.0/21") != nil || c.Get(container.GetPath()+" -> 10.1.9.0/24") == nil {
+		t.Error("the stored free block was not carved around mgmt")
+	}
+}
+
+// ---------- resize.go ----------
+
+// newResizeCatalog builds Networks -> 10.0.0.0/23 (container) with a /24 pool
+// holding one reservation, an unallocated /25 sibling and a DNS alias to the reservation.
+func newResizeCatalog(t *testing.T) (*Catalog, *Network, *Network) {
+	t.Helper()
+	c := NewCatalog()
+	c.Put(&StaticFolder{Base: Base{ID: FolderNetworks}, Index: 0})
+	c.Put(&StaticFolder{Base: Base{ID: FolderDNS}, Index: 1})
+	container := &Network{Base: Base{ID: "10.0.0.0/23", ParentPath: FolderNetworks}, AllocationMode: AllocationModeSubnets}
+	c.Put(container)
+	pool := &Network{Base: Base{ID: "10.0.0.0/25", ParentPath: container.GetPath()}, AllocationMode: AllocationModeHosts, DisplayName: "LAN"}
+	c.Put(pool)
+	c.Put(&Network{Base: Base{ID: "10.0.0.128/25", ParentPath: container.GetPath()}})
+	ip := &IP{Base: Base{ID: "10.0.0.10", ParentPath: pool.GetPath()}, DisplayName: "nas"}
+	c.Put(ip)
+	c.Put(&DNSRecord{Base: Base{ID: "nas.home", ParentPath: FolderDNS}, ReservedIPPath: ip.GetPath()})
+	return c, container, pool
+}
+
+func TestNetworkGrow(t *testing.T) {
+	c, container, pool := newResizeCatalog(t)
+	if err := pool.Grow(c, 24); err != nil {
+		t.Fatalf("Grow() error = %v", err)
+	}
+	if pool.ID != "10.0.0.0/24" || c.Get(container.GetPath()+" -> 10.0.0.0/24") != pool {
+		t.Fatalf("pool not re-keyed: %s", pool.GetPath())
+	}
+	if len(c.GetChildren(container)) != 1 {
+		t.Errorf("unallocated sibling should be absorbed, children = %d", len(c.GetChildren(container)))
+	}
+	if c.Get(pool.GetPath()+" -> 10.0.0.10") == nil {
+		t.Error("reservation should move with the pool")
+	}
+	if record := c.Get("DNS -> nas.home").(*DNSRecord); record.ReservedIPPath != pool.GetPath()+" -> 10.0.0.10" {
+		t.Errorf("alias not updated: %s", record.ReservedIPPath)
+	}
+
+	if err := pool.Grow(c, 23); err == nil {
+		t.Error("expected error when growing to the parent size")
+	}
+	c.Put(&Network{Base: Base{ID: "10.0.1.0/24", ParentPath: container.GetPath()}, AllocationMode: AllocationModeHosts})
+	if err := container.Grow(c, 22); err != nil {
+		t.Fatalf("Grow() root error = %v", err)
+	}
+	if c.Get("Networks -> 10.0.0.0/22 -> 10.0.0.0/24 -> 10.0.0.10") == nil {
+		t.Error("nested reservation should move with the container")
+	}
+}
+
+func TestNetworkGrowRefusesAllocatedSibling(t *testing.T) {
+	c, container, pool := newResizeCatalog(t)
+	c.Put(&Network{Base: Base{ID: "10.0.0.128/25", ParentPath: container.GetPath()}, AllocationMode: AllocationModeHosts})
+	if err := pool.Grow(c, 24); err == nil {
+		t.Fatal("expected error when absorbing an allocated sibling")
+	}
+	if pool.ID != "10.0.0.0/25" {
+		t.Errorf("pool changed on failure: %s", pool.ID)
+	}
+}
+
+func TestNetworkShrink(t *testing.T) {
+	c, container, pool := newResizeCatalog(t)
+	if err := pool.Shrink(c, 26); err != nil {
+		t.Fatalf("Shrink() error = %v", err)
+	}
+	var got []string
+	for _, child := range c.GetChildren(container) {
+		got = append(got, child.(*Network).ID)
+	}
+	if want := []string{"10.0.0.0/26", "10.0.0.128/25"}; !slices.Equal(got, want) {
+		t.Errorf("stored children = %v, want %v", got, want)
+	}
+	if free, ok := c.Lookup(container.GetPath() + " -> 10.0.0.64/26").(*Network); !ok || !c.IsImplicit(free) {
+		t.Error("released space should be implicit free space")
+	}
+	if record := c.Get("DNS -> nas.home").(*DNSRecord); record.ReservedIPPath != pool.GetPath()+" -> 10.0.0.10" {
+		t.Errorf("alias not updated: %s", record.ReservedIPPath)
+	}
+
+	if err := pool.Shrink(c, 29); err == nil {
+		t.Error("expected error when a reservation would fall outside")
+	}
+	c.Put(&IP{Base: Base{ID: "10.0.0.31", ParentPath: pool.GetPath()}, DisplayName: "edge"})
+	if err := pool.Shrink(c, 27); err == nil || !strings.Contains(err.Error(), "broadcast") {
+		t.Errorf("Shrink() error = %v, want error for a reservation on the broadcast address", err)
+	}
+	if pool.ID != "10.0.0.0/26" {
+		t.Errorf("pool changed on failure: %s", pool.ID)
+	}
+	c.Remove(pool.GetPath() + " -> 10.0.0.31")
+	c.Put(&Network{Base: Base{ID: "10.0.1.0/24", ParentPath: container.GetPath()}})
+	if err := container.Shrink(c, 24); err != nil {
+		t.Fatalf("Shrink() container error = %v", err)
+	}
+	if len(c.GetChildren(container)) != 2 {
+		t.Errorf("unallocated child outside should be dropped, children = %d", len(c.GetChildren(container)))
+	}
+	if c.Get("Networks -> 10.0.1.0/24") == nil {
+		t.Error("released space under Networks should become an unallocated sibling")
+	}
+	c.Put(&Network{Base: Base{ID: "10.0.0.128/25", ParentPath: "Networks -> 10.0.0.0/24"}, AllocationMode: AllocationModeHosts})
+	if err := container.Shrink(c, 25); err == nil {
+		t.Error("expected error when an allocated child would fall outside")
+	}
+	if err := (&Network{Base: Base{ID: "10.9.0.0/24", ParentPath: FolderNetworks}}).Shrink(c, 25); err == nil {
+		t.Error("expected error for unallocated network")
+	}
+}
+
+// ---------- move.go ----------
+
+// newMoveCatalog builds a /16 site with a Lab and a Prod /20 container. Lab
+// holds a /24 container with a pool, a reservation and a DNS alias to it.
+func newMoveCatalog(t *testing.T) (c *Catalog, site, lab, prod, servers *Network) {
+	t.Helper()
+	c = NewCatalog()
+	c.Put(&StaticFolder{Base: Base{ID: FolderNetworks}, Index: 0})
+	c.Put(&StaticFolder{Base: Base{ID: FolderDNS}, Index: 1})
+	site = &Network{Base: Base{ID: "10.0.0.0/16", ParentPath: FolderNetworks}, AllocationMode: AllocationModeSubnets}
+	c.Put(site)
+	lab = &Network{Base: Base{ID: "10.0.0.0/20", ParentPath: site.GetPath()}, AllocationMode: AllocationModeSubnets, DisplayName: "Lab"}
+	c.Put(lab)
+	prod = &Network{Base: Base{ID: "10.0.16.0/20", ParentPath: site.GetPath()}, AllocationMode: AllocationModeSubnets, DisplayName: "Prod"}
+	c.Put(prod)
+	servers = &Network{Base: Base{ID: "10.0.1.0/24", ParentPath: lab.GetPath()}, AllocationMode: AllocationModeSubnets, DisplayName: "Servers"}
+	c.Put(servers)
+	pool := &Network{Base: Base{ID: "10.0.1.128/25", ParentPath: servers.GetPath()}, AllocationMode: AllocationModeHosts, DisplayName: "DB"}
+	c.Put(pool)
+	ip := &IP{Base: Base{ID: "10.0.1.130", ParentPath: pool.GetPath()}, DisplayName: "db"}
+	c.Put(ip)
+	c.Put(&DNSRecord{Base: Base{ID: "db.home", ParentPath: FolderDNS}, ReservedIPPath: ip.GetPath()})
+	return c, site, lab, prod, servers
+}
+
+func TestMoveTargets(t *testing.T) {
+	c, site, _, prod, servers := newMoveCatalog(t)
+	var got []string
+	for _, target := range c.MoveTargets(servers) {
+		got = append(got, target.GetPath())
+	}
+	want := []string{FolderNetworks, site.GetPath(), prod.GetPath()}
+	if !slices.Equal(got, want) {
+		t.Errorf("MoveTargets() = %v, want %v", got, want)
+	}
+}
+
+func TestNetworkMove(t *testing.T) {
+	c, _, lab, prod, servers := newMoveCatalog(t)
+	placement, err := c.MovePlacement(servers, prod)
+	if err != nil || placement != "10.0.16.0/24" {
+		t.Fatalf("MovePlacement() = %q, %v", placement, err)
+	}
+	if err := servers.Move(c, prod, "10.0.17.0/24"); err != nil {
+		t.Fatalf("Move() error = %v", err)
+	}
+	if c.Get(prod.GetPath()+" -> 10.0.17.0/24") != servers || servers.DisplayName != "Servers" {
+		t.Fatalf("servers not re-keyed: %s", servers.GetPath())
+	}
+	ipPath := servers.GetPath() + " -> 10.0.17.128/25 -> 10.0.17.130"
+	if c.Get(ipPath) == nil {
+		t.Error("nested reservation should keep its offset")
+	}
+	if record := c.Get("DNS -> db.home").(*DNSRecord); record.ReservedIPPath != ipPath {
+		t.Errorf("alias not updated: %s", record.ReservedIPPath)
+	}
+	if len(c.GetChildren(lab)) != 0 {
+		t.Errorf("old parent should be left empty, children = %d", len(c.GetChildren(lab)))
+	}
+	for _, item := range c.All() {
+		if strings.Contains(item.GetPath(), "10.0.1.") {
+			t.Errorf("stale item left behind: %s", item.GetPath())
+		}
+	}
+}
+
+func TestNetworkMoveErrors(t *testing.T) {
+	c, site, lab, prod, servers := newMoveCatalog(t)
+	tests := []struct {
+		name    string
+		parent  Item
+		newCIDR string
+	}{
+		{"different size", prod, "10.0.16.0/23"},
+		{"outside parent", prod, "10.0.1.0/24"},
+		{"overlaps allocated", site, "10.0.16.0/24"},
+		{"under itself", servers, "10.0.1.0/25"},
+		{"unchanged", lab, ""},
+		{"host bits set", prod, "10.0.16.1/24"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := servers.Move(c, tt.parent, tt.newCIDR); err == nil {
+				t.Fatal("expected error")
+			}
+			if servers.ID != "10.0.1.0/24" || c.Get(servers.GetPath()) != servers {
+				t.Errorf("servers changed on failure: %s", servers.GetPath())
+			}
+		})
+	}
+}
+
+func TestNetworkMoveInvalidSubtree(t *testing.T) {
+	c, _, _, prod, servers := newMoveCatalog(t)
+	pool := c.Get(servers.GetPath() + " -> 10.0.1.128/25").(*Network)
+	// Stored without validation: the reservation 10.0.1.130 is excluded.
+	pool.ExcludedRanges = []string{"10.0.1.129-10.0.1.140"}
+	c.Put(&Network{Base: Base{ID: "10.0.16.0/21", ParentPath: prod.GetPath()}})
+	paths := func() []string {
+		var paths []string
+		for path := range c.All() {
+			paths = append(paths, path)
+		}
+		slices.Sort(paths)
+		return paths
+	}
+	before := paths()
+
+	err := servers.Move(c, prod, "10.0.17.0/24")
+	if err == nil || !strings.Contains(err.Error(), "excluded range") {
+		t.Fatalf("Move() error = %v, want excluded range error", err)
+	}
+	if got := paths(); !slices.Equal(got, before) {
+		t.Errorf("paths after failed move = %v, want %v", got, before)
+	}
+	if servers.ID != "10.0.1.0/24" || pool.ExcludedRanges[0] != "10.0.1.129-10.0.1.140" {
+		t.Errorf("servers = %s, excluded ranges = %v; want them unchanged", servers.ID, pool.ExcludedRanges)
+	}
+	if record := c.Get("DNS -> db.home").(*DNSRecord); record.ReservedIPPath != pool.GetPath()+" -> 10.0.1.130" {
+		t.Errorf("alias not restored: %s", record.ReservedIPPath)
+	}
+}
+
+// ---------- renumber.go ----------
+
+func TestNetworkRenumber(t *testing.T) {
+	c, _, _, prod, servers := newMoveCatalog(t)
+	c.Put(&DNSRecord{Base: Base{ID: "db-vip.home", ParentPath: FolderDNS}, RecordType: "A", RecordValue: "10.0.1.131"})
+	c.Put(&DNSRecord{Base: Base{ID: "other.home", ParentPath: FolderDNS}, RecordType: "A", RecordValue: "10.0.2.1"})
+
+	changes, err := c.PlanRenumber(servers, "10.0.17.0/24")
+	if err != nil {
+		t.Fatalf("PlanRenumber() error = %v", err)
+	}
+	var got []string
+	for _, change := range changes {
+		got = append(got, change.Old+" > "+change.New)
+	}
+	want := []string{
+		"10.0.1.0/24 > 10.0.17.0/24",
+		"10.0.1.128/25 > 10.0.17.128/25",
+		"10.0.1.130 > 10.0.17.130",
+		"10.0.1.131 > 10.0.17.131",
+		"10.0.1.130 > 10.0.17.130",
+	}
+	if !slices.Equal(got, want) {
+		t.Fatalf("PlanRenumber() = %v, want %v", got, want)
+	}
+	if servers.ID != "10.0.1.0/24" {
+		t.Fatal("PlanRenumber() must not change the catalog")
+	}
+
+	if err := servers.Renumber(c, "10.0.17.0/24"); err != nil {
+		t.Fatalf("Renumber() error = %v", err)
+	}
+	if servers.ParentPath != prod.GetPath() {
+		t.Errorf("servers should land in Prod, parent = %s", servers.ParentPath)
+	}
+	ipPath := servers.GetPath() + " -> 10.0.17.128/25 -> 10.0.17.130"
+	if record := c.Get("DNS -> db.home").(*DNSRecord); record.ReservedIPPath != ipPath {
+		t.Errorf("alias not updated: %s", record.ReservedIPPath)
+	}
+	if record := c.Get("DNS -> db-vip.home").(*DNSRecord); record.RecordValue != "10.0.17.131" {
+		t.Errorf("A record not rewritten: %s", record.RecordValue)
+	}
+	if record := c.Get("DNS -> other.home").(*DNSRecord); record.RecordValue != "10.0.2.1" {
+		t.Errorf("unrelated A record changed: %s", record.RecordValue)
+	}
+
+	if err := servers.Renumber(c, "192.168.10.0/24"); err != nil {
+		t.Fatalf("Renumber() to top level error = %v", err)
+	}
+	if servers.ParentPath != FolderNetworks {
+		t.Errorf("servers should move to the top level, parent = %s", servers.ParentPath)
+	}
+	if _, err := c.PlanRenumber(servers, "10.0.0.0/20"); err == nil {
+		t.Error("expected error for a different size")
+	}
+	c.Put(&Network{Base: Base{ID: "10.0.2.0/24", ParentPath: "Networks -> 10.0.0.0/16 -> 10.0.0.0/20"}, AllocationMode: AllocationModeHosts})
+	if _, err := c.PlanRenumber(servers, "10.0.2.0/24"); err == nil {
+		t.Error("expected error when the new CIDR lies in an allocated network")
+	}
+}
+
+// ---------- clone.go ----------
+
+func TestParsePlaceholders(t *testing.T) {
+	got, err := ParsePlaceholders("site=ams, {floor}=2")
+	if err != nil {
+		t.Fatalf("ParsePlaceholders() error = %v", err)
+	}
+	if len(got) != 2 || got["site"] != "ams" || got["floor"] != "2" {
+		t.Errorf("ParsePlaceholders() = %v", got)
+	}
+	if _, err := ParsePlaceholders("site"); err == nil {
+		t.Error("expected error without a value")
+	}
+}
+
+func TestNetworkClone(t *testing.T) {
+	c, _, lab, prod, servers := newMoveCatalog(t)
+	c.Put(&StaticFolder{Base: Base{ID: FolderVLANs}, Index: 2})
+	c.Put(&VLAN{Base: Base{ID: "10", ParentPath: FolderVLANs}, DisplayName: "servers"})
+	servers.Description = "{site} servers"
+	servers.VLANID = 10
+	ip := c.Get("Networks -> 10.0.0.0/16 -> 10.0.0.0/20 -> 10.0.1.0/24 -> 10.0.1.128/25 -> 10.0.1.130").(*IP)
+	ip.MACAddress = "00:11:22:33:44:55"
+
+	clone, err := servers.Clone(c, prod, "10.0.17.0/24", CloneOptions{
+		VLANs:        true,
+		Reservations: true,
+		Placeholders: map[string]string{"site": "ams"},
+	})
+	if err != nil {
+		t.Fatalf("Clone() error = %v", err)
+	}
+	if clone.GetPath() != prod.GetPath()+" -> 10.0.17.0/24" || clone.DisplayName != "Servers" || clone.Description != "ams servers" || clone.VLANID != 10 {
+		t.Errorf("clone = %+v", clone)
+	}
+	copied, ok := c.Get(clone.GetPath() + " -> 10.0.17.128/25 -> 10.0.17.130").(*IP)
+	if !ok {
+		t.Fatal("reservation should be copied at the same offset")
+	}
+	if copied.DisplayName != "db" || copied.MACAddress != "" {
+		t.Errorf("copied reservation = %+v", copied)
+	}
+	if c.Get(servers.GetPath()) != servers || servers.Description != "{site} servers" {
+		t.Error("source must be left untouched")
+	}
+
+	c.Put(&Network{Base: Base{ID: "10.0.1.0/25", ParentPath: servers.GetPath()}, SplitBits: 27})
+	clone, err = servers.Clone(c, lab, "10.0.0.0/24", CloneOptions{})
+	if err != nil {
+		t.Fatalf("Clone() into the same parent error = %v", err)
+	}
+	if clone.VLANID != 0 || c.Get(clone.GetPath()+" -> 10.0.0.128/25") == nil || len(c.GetChildren(c.Get(clone.GetPath()+" -> 10.0.0.128/25"))) != 0 {
+		t.Error("clone without options should copy only the structure")
+	}
+	if split, ok := c.Get(clone.GetPath() + " -> 10.0.0.0/25").(*Network); !ok || split.SplitBits != 27 {
+		t.Error("clone should keep a split network split")
+	}
+
+	if _, err := servers.Clone(c, lab, "10.0.1.0/24", CloneOptions{}); err == nil {
+		t.Error("expected error when cloning over an allocated network")
+	}
+	ip.DisplayName = "{host}"
+	if _, err := servers.Clone(c, prod, "10.0.18.0/24", CloneOptions{Reservations: true, Placeholders: map[string]string{"host": "bad name"}}); err == nil {
+		t.Error("expected error for an invalid reservation name")
+	}
+	if c.Get(prod.GetPath()+" -> 10.0.18.0/24") != nil {
+		t.Error("failed clone should be rolled back")
+	}
+
+	// The root has no implicit free space: the carved block must come back.
+	networks := c.Get(FolderNetworks)
+	c.Put(&Network{Base: Base{ID: "10.9.0.0/16", ParentPath: FolderNetworks}})
+	if _, err := servers.Clone(c, networks, "10.9.0.0/24", CloneOptions{Reservations: true, Placeholders: map[string]string{"host": "bad name"}}); err == nil {
+		t.Error("expected error for an invalid reservation name")
+	}
+	if c.Get("Networks -> 10.9.0.0/16") == nil || c.Get("Networks -> 10.9.0.0/24") != nil || c.Get("Networks -> 10.9.1.0/24") != nil {
+		t.Errorf("failed clone should restore the free block, got %v", c.GetChildren(networks))
+	}
+}
+
+// ---------- templates.go ----------
+
+func newBranchTemplate() *LayoutTemplate {
+	return &LayoutTemplate{
+		Name:      "branch",
+		PrefixLen: 23,
+		Subnets: []TemplateSubnet{
+			{Name: "mgmt", PrefixLen: 26, Offset: 0, VLANID: 10, GatewayOffset: 1},
+			{Name: "cameras", PrefixLen: 26, Offset: 1},
+			{Name: "iot", PrefixLen: 25, Offset: 1},
+			{Name: "users", PrefixLen: 24, Offset: 1, Mode: TemplateModeSubnets},
+		},
+	}
+}
+
+func TestLayoutTemplateValidate(t *testing.T) {
+	if err := newBranchTemplate().Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	tests := []struct {
+		name   string
+		mutate func(*LayoutTemplate)
+	}{
+		{"no name", func(tpl *LayoutTemplate) { tpl.Name = "" }},
+		{"no subnets", func(tpl *LayoutTemplate) { tpl.Subnets = nil }},
+		{"subnet larger than container", func(tpl *LayoutTemplate) { tpl.Subnets[0].PrefixLen = 23 }},
+		{"offset outside", func(tpl *LayoutTemplate) { tpl.Subnets[2].Offset = 4 }},
+		{"overlap", func(tpl *LayoutTemplate) { tpl.Subnets[1].Offset = 0 }},
+		{"bad mode", func(tpl *LayoutTemplate) { tpl.Subnets[0].Mode = "pool" }},
+		{"negative gateway", func(tpl *LayoutTemplate) { tpl.Subnets[0].GatewayOffset = -1 }},
+		{"gateway in container", func(tpl *LayoutTemplate) { tpl.Subnets[3].GatewayOffset = 1 }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tpl := newBranchTemplate()
+			tt.mutate(tpl)
+			if err := tpl.Validate(); err == nil {
+				t.Fatal("expected error")
+			}
+		})
+	}
+}
+
+func TestCatalogApplyTemplate(t *testing.T) {
+	c := NewCatalog()
+	c.Put(&StaticFolder{Base: Base{ID: FolderNetworks}, Index: 0})
+	c.Put(&StaticFolder{Base: Base{ID: FolderVLANs}, Index: 2})
+	container := &Network{Base: Base{ID: "10.1.0.0/23", ParentPath: FolderNetworks}, AllocationMode: AllocationModeSubnets, DisplayName: "AMS"}
+	c.Put(container)
+	tpl := newBranchTemplate()
+
+	if err := c.ApplyTemplate(container, tpl); err == nil || !strings.Contains(err.Error(), "VLAN ID 10") {
+		t.Fatalf("expected missing VLAN error, got %v", err)
+	}
+	if len(c.GetChildren(container)) != 0 {
+		t.Fatal("failed apply must not change the catalog")
+	}
+
+	c.Put(&VLAN{Base: Base{ID: "10", ParentPath: FolderVLANs}, DisplayName: "mgmt"})
+	if err := c.ApplyTemplate(container, tpl); err != nil {
+		t.Fatalf("ApplyTemplate() error = %v", err)
+	}
+	var got []string
+	for _, child := range c.GetChildren(container) {
+		got = append(got, child.DisplayID())
+	}
+	want := []string{"10.1.0.0/26 (mgmt)", "10.1.0.64/26 (cameras)", "10.1.0.128/25 (iot)", "10.1.1.0/24 (users)"}
+	if !slices.Equal(got, want) {
+		t.Errorf("children = %v, want %v", got, want)
+	}
+	if c.Get(container.GetPath()+" -> 10.1.0.0/26").(*Network).VLANID != 10 {
+		t.Error("VLAN should be assigned")
+	}
+	if c.Get(container.GetPath()+" -> 10.1.1.0/24").(*Network).AllocationMode != AllocationModeSubnets {
+		t.Error("users should be a Subnet Container")
+	}
+	if gw, ok := c.Get(container.GetPath() + " -> 10.1.0.0/26 -> 10.1.0.1").(*IP); !ok || gw.DisplayName != TemplateGatewayName {
+		t.Error("gateway should be reserved")
+	}
+
+	if err := c.ApplyTemplate(container, tpl); err == nil {
+		t.Error("expected error when subnets are already allocated")
+	}
+	tpl.Subnets[0].GatewayOffset = 63
+	if err := c.ApplyTemplate(&Network{Base: Base{ID: "10.3.0.0/23", ParentPath: FolderNetworks}, AllocationMode: AllocationModeSubnets}, tpl); err == nil {
+		t.Error("expected error for a gateway on the broadcast address")
+	}
+	small := &Network{Base: Base{ID: "10.2.0.0/24", ParentPath: FolderNetworks}, AllocationMode: AllocationModeSubnets, DisplayName: "x"}
+	c.Put(small)
+	if err := c.ApplyTemplate(small, tpl); err == nil {
+		t.Error("expected error for a container of another size")
+	}
+}
+
+// ---------- dhcp.go ----------
+
+func TestDHCPRanges(t *testing.T) {
+	c := NewCatalog()
+	c.Put(&StaticFolder{Base: Base{ID: FolderNetworks}, Index: 0})
+	pool := &Network{
+		Base:           Base{ID: "10.0.0.0/24", ParentPath: FolderNetworks},
+		AllocationMode: AllocationModeHosts,
+		DisplayName:    "LAN",
+		Gateway:        "10.0.0.1",
+		ExcludedRanges: []string{"10.0.0.250-10.0.0.254"},
+		DHCPRanges:     []string{"10.0.0.100-10.0.0.199"},
+	}
+	c.Put(pool)
+	if err := pool.Validate(c); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	if got := pool.DHCPRangeSize().Int64(); got != 100 {
+		t.Errorf("DHCPRangeSize() = %d, want 100", got)
+	}
+
+	static := &IP{Base: Base{ID: "10.0.0.150", ParentPath: pool.GetPath()}, DisplayName: "printer"}
+	if err := static.Validate(c); err != nil {
+		t.Fatalf("reservations inside a DHCP range are allowed: %v", err)
+	}
+	c.Put(static)
+	if got := static.DHCPWarning(c); got != "10.0.0.150 (printer) is inside DHCP range 10.0.0.100-10.0.0.199" {
+		t.Errorf("DHCPWarning() = %q", got)
+	}
+	if warnings := c.DHCPWarnings(pool); len(warnings) != 1 {
+		t.Errorf("DHCPWarnings() = %v, want one warning", warnings)
+	}
+	_, details, err := pool.RenderDetailsMap(c, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if details["Address Split"] != "100 dynamic (DHCP), 149 static, 5 excluded" || details["DHCP Warnings"] == "" {
+		t.Errorf("details = %q / %q", details["Address Split"], details["DHCP Warnings"])
+	}
+	// Overlapping ranges count once; the network address and the gateway
+	// are not excluded hosts.
+	overlapping := &Network{Base: Base{ID: "10.0.0.0/24"}, Gateway: "10.0.0.1", ExcludedRanges: []string{"10.0.0.0-10.0.0.9", ".5-.14"}}
+	if got := overlapping.excludedHostCount().Int64(); got != 13 {
+		t.Errorf("excludedHostCount() = %d, want 13", got)
+	}
+
+	for name, ranges := range map[string][]string{
+		"outside":          {"10.0.1.10-10.0.1.20"},
+		"network address":  {"10.0.0.0-10.0.0.20"},
+		"gateway":          {"10.0.0.1-10.0.0.20"},
+		"excluded overlap": {"10.0.0.240-10.0.0.251"},
+		"self overlap":     {"10.0.0.100-10.0.0.150", "10.0.0.140-10.0.0.160"},
+	} {
+		copied := *pool
+		copied.DHCPRanges = ranges
+		if err := copied.Validate(c); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+
+	if err := pool.Renumber(c, "10.9.0.0/24"); err != nil {
+		t.Fatalf("Renumber() error = %v", err)
+	}
+	if !slices.Equal(pool.DHCPRanges, []string{"10.9.0.100-10.9.0.199"}) {
+		t.Errorf("DHCP ranges not rebased: %v", pool.DHCPRanges)
+	}
+}
+
+func TestDHCPOptions(t *testing.T) {
+	custom, err := ParseDHCPCustomOptions("66=tftp.example.com, 150=10.0.0.5")
+	if err != nil || FormatDHCPCustomOptions(custom) != "66=tftp.example.com, 150=10.0.0.5" {
+		t.Fatalf("ParseDHCPCustomOptions() = %v, %v", custom, err)
+	}
+	if _, err := ParseDHCPCustomOptions("tftp=x"); err == nil {
+		t.Error("expected error for a non-numeric option code")
+	}
+
+	c := NewCatalog()
+	c.Put(&StaticFolder{Base: Base{ID: FolderNetworks}, Index: 0})
+	pool := &Network{
+		Base:           Base{ID: "10.0.0.0/24", ParentPath: FolderNetworks},
+		AllocationMode: AllocationModeHosts,
+		DisplayName:    "LAN",
+		Gateway:        "10.0.0.1",
+		DHCPOptions: &DHCPOptions{
+			DNSServers:    []string{"10.0.0.53", "1.1.1.1"},
+			DomainName:    "lan.example.com",
+			SearchDomains: []string{"example.com"},
+			NTPServers:    []string{"10.0.0.123"},
+			LeaseTime:     "12h",
+			Custom:        custom,
+		},
+	}
+	c.Put(pool)
+	if err := pool.Validate(c); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	_, details, err := pool.RenderDetailsMap(c, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if details["DHCP Router"] != "10.0.0.1" || details["DHCP DNS Servers"] != "10.0.0.53, 1.1.1.1" || details["DHCP Lease Time"] != "12h" {
+		t.Errorf("details = %v", details)
+	}
+
+	for name, mutate := range map[string]func(*DHCPOptions){
+		"router outside":    func(o *DHCPOptions) { o.Router = "10.0.1.1" },
+		"bad dns":           func(o *DHCPOptions) { o.DNSServers = []string{"dns"} },
+		"ipv6 ntp":          func(o *DHCPOptions) { o.NTPServers = []string{"fd00::123"} },
+		"bad domain":        func(o *DHCPOptions) { o.DomainName = "-lan" },
+		"bad lease":         func(o *DHCPOptions) { o.LeaseTime = "forever" },
+		"duplicate code":    func(o *DHCPOptions) { o.Custom = append(o.Custom, DHCPCustomOption{Code: 66, Value: "x"}) },
+		"code out of range": func(o *DHCPOptions) { o.Custom = []DHCPCustomOption{{Code: 255, Value: "x"}} },
+	} {
+		copied := *pool
+		options := *pool.DHCPOptions
+		mutate(&options)
+		copied.DHCPOptions = &options
+		if err := copied.Validate(c); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+
+	pool.DHCPOptions.Router = "10.0.0.254"
+	if err := pool.Renumber(c, "10.9.0.0/24"); err != nil {
+		t.Fatalf("Renumber() error = %v", err)
+	}
+	if pool.EffectiveRouter() != "10.9.0.254" {
+		t.Errorf("router not rebased: %s", pool.EffectiveRouter())
+	}
+}
+
+// ---------- dns.go ----------
+
+func TestValidateRecordValue(t *testing.T) {
+	tests := []struct {
+		recordType, value string
+		wantErr           bool
+	}{
+		{"A", "10.0.0.1", false},
+		{"A", "not-an-ip", true},
+		{"A", "fd00::1", true},
+		{"AAAA", "fd00::1", false},
+		{"AAAA", "10.0.0.1", true},
+		{"CNAME", "nas.home.", false},
+		{"CNAME", "bad name", true},
+		{"MX", "10 mail.home", false},
+		{"MX", "10", true},
+		{"MX", "high mail.home", true},
+		{"SRV", "10 5 5060 pbx.home", false},
+		{"SRV", "0 0 0 .", false},
+		{"SRV", "10 5 70000 pbx.home", true},
+		{"SRV", "10 5 pbx.home", true},
+		{"TXT", "v=spf1 -all", false},
+		{"TXT", `"part one" "part \"two\""`, false},
+		{"TXT", `"unterminated`, true},
+		{"TXT", `say "hi"`, true},
+		{"TXT", strings.Repeat("x", 256), true},
+		{"TXT", `"` + strings.Repeat("x", 255) + `" "more"`, false},
+		{"CAA", `0 issue "letsencrypt.org"`, false},
+		{"CAA", `128 iodef "mailto:security@example.com"`, false},
+		{"CAA", `0 iodef "ftp://example.com"`, true},
+		{"CAA", `0 policy "x"`, true},
+		{"CAA", `7 issue "ca.example"`, true},
+		{"CAA", `0 issue`, true},
+		{"mx", "10 mail.home", false},
+		{"SPF", "v=spf1", true},
+		{"", "10.0.0.1", true},
+	}
+	for _, tt := range tests {
+		if err := ValidateRecordValue(tt.recordType, tt.value); (err != nil) != tt.wantErr {
+			t.Errorf("ValidateRecordValue(%q, %q) error = %v, wantErr %v", tt.recordType, tt.value, err, tt.wantErr)
+		}
+	}
+	if err := ValidateDNSName(strings.Repeat("a", 64) + ".home"); err == nil {
+		t.Error("expected error for a label longer than 63 characters")
+	}
+}
+
+func TestCNAMEExclusive(t *testing.T) {
+	c := NewCatalog()
+	c.Put(&StaticFolder{Base: Base{ID: FolderNetworks}, Index: 0})
+	c.Put(&StaticFolder{Base: Base{ID: FolderDNS}, Index: 5})
+	pool := &Network{Base: Base{ID: "10.0.0.0/24", ParentPath: FolderNetworks}, AllocationMode: AllocationModeHosts}
+	c.Put(pool)
+	c.Put(&IP{Base: Base{ID: "10.0.0.10", ParentPath: pool.GetPath()}, DisplayName: "nas.home"})
+
+	cname := &DNSRecord{Base: Base{ID: "nas.home", ParentPath: FolderDNS}, RecordType: "CNAME", RecordValue: "files.home"}
+	if err := cname.Validate(c); err == nil {
+		t.Error("a CNAME must not share its name with a reservation")
+	}
+	cname.ID = "share.home"
+	if err := cname.Validate(c); err != nil {
+		t.Fatalf("Validate() error: %v", err)
+	}
+	c.Put(cname)
+	ip := &IP{Base: Base{ID: "10.0.0.11", ParentPath: pool.GetPath()}, DisplayName: "share.home"}
+	if err := ip.Validate(c); err == nil {
+		t.Error("a reservation must not take the name of a CNAME")
+	}
+
+	zone := &DNSZone{Base: Base{ID: "home", ParentPath: FolderDNS}}
+	c.Put(zone)
+	txt := &DNSRecord{Base: Base{ID: "Share.home", ParentPath: zone.GetPath()}, RecordType: "TXT", RecordValue: `"v=spf1 -all"`}
+	if err := txt.Validate(c); err == nil {
+		t.Error("a record must not take the name of a CNAME")
+	}
+	if err := cname.Validate(c); err != nil {
+		t.Errorf("a CNAME must not conflict with itself: %v", err)
+	}
+	mx := &DNSRecord{Base: Base{ID: "mail.home", ParentPath: zone.GetPath()}, RecordType: "MX", RecordValue: "10 mx.home"}
+	c.Put(mx)
+	if err := (&DNSRecord{Base: Base{ID: "MAIL.home", ParentPath: FolderDNS}, RecordType: "CNAME", RecordValue: "files.home"}).Validate(c); err == nil {
+		t.Error("a CNAME must not share its name with another record")
+	}
+	if got := c.DNSRecordNamed("MAIL.home."); got != mx {
+		t.Errorf("DNSRecordNamed() = %v, want the record nested in the zone", got)
+	}
+	var dataErr *RecordDataError
+	if err := txt.Validate(c); !errors.As(err, &dataErr) {
+		t.Errorf("Validate() error = %v, want a RecordDataError so stored conflicts only warn", err)
+	}
+}
+
+func TestAliasTargets(t *testing.T) {
+	c := NewCatalog()
+	c.Put(&StaticFolder{Base: Base{ID: FolderNetworks}, Index: 0})
+	c.Put(&StaticFolder{Base: Base{ID: FolderDNS}, Index: 5})
+	v4 := &Network{Base: Base{ID: "10.0.0.0/24", ParentPath: FolderNetworks}, AllocationMode: AllocationModeHosts}
+	v6 := &Network{Base: Base{ID: "fd00::/64", ParentPath: FolderNetworks}, AllocationMode: AllocationModeHosts}
+	c.Put(v4)
+	c.Put(v6)
+	nas4 := &IP{Base: Base{ID: "10.0.0.10", ParentPath: v4.GetPath()}, DisplayName: "nas"}
+	nas6 := &IP{Base: Base{ID: "fd00::10", ParentPath: v6.GetPath()}, DisplayName: "NAS"}
+	gw := &IP{Base: Base{ID: "10.0.0.1", ParentPath: v4.GetPath()}, DisplayName: "gw"}
+	c.Put(nas6)
+	c.Put(nas4)
+	c.Put(gw)
+
+	host := &DNSRecord{Base: Base{ID: "files.home", ParentPath: FolderDNS}, ReservedHost: "nas"}
+	if err := host.Validate(c); err != nil {
+		t.Fatalf("Validate() error: %v", err)
+	}
+	if got := c.AliasTargets(host); !slices.Equal(got, []*IP{nas4, nas6}) {
+		t.Errorf("AliasTargets(host) = %v, want both nas reservations", got)
+	}
+	if got := c.ReservedHostnames(); len(got) != 2 || got[0] != "gw" || !strings.EqualFold(got[1], "nas") {
+		t.Errorf("ReservedHostnames() = %v", got)
+	}
+
+	list := &DNSRecord{Base: Base{ID: "files.home", ParentPath: FolderDNS}, ReservedIPPath: nas6.GetPath(), ReservedIPPaths: []string{gw.GetPath()}}
+	if err := list.Validate(c); err != nil {
+		t.Fatalf("Validate() error: %v", err)
+	}
+	if got := c.AliasTargets(list); !slices.Equal(got, []*IP{gw, nas6}) {
+		t.Errorf("AliasTargets(list) = %v, want gw and nas6", got)
+	}
+
+	for name, record := range map[string]*DNSRecord{
+		"host_and_list":  {Base: Base{ID: "a.home", ParentPath: FolderDNS}, ReservedIPPath: gw.GetPath(), ReservedHost: "nas"},
+		"listed_twice":   {Base: Base{ID: "a.home", ParentPath: FolderDNS}, ReservedIPPath: gw.GetPath(), ReservedIPPaths: []string{gw.GetPath()}},
+		"missing_target": {Base: Base{ID: "a.home", ParentPath: FolderDNS}, ReservedIPPaths: []string{"missing"}},
+		"invalid_host":   {Base: Base{ID: "a.home", ParentPath: FolderDNS}, ReservedHost: "bad host"},
+	} {
+		if err := record.Validate(c); err == nil {
+			t.Errorf("%s: expected validation error", name)
+		}
+	}
+}
+
+func TestDNSZoneValidate(t *testing.T) {
+	c := NewCatalog()
+	c.Put(&StaticFolder{Base: Base{ID: FolderDNS}, Index: 5})
+	zone := &DNSZone{
+		Base:        Base{ID: "example.com", ParentPath: FolderDNS},
+		TTL:         600,
+		Contact:     "admin@example.com",
+		NameServers: []string{"ns1.example.com", "ns2.example.net"},
+	}
+	if err := zone.Validate(c); err != nil {
+		t.Fatalf("Validate() error: %v", err)
+	}
+	c.Put(zone)
+
+	for name, bad := range map[string]*DNSZone{
+		"trailing_dot": {Base: Base{ID: "example.com.", ParentPath: FolderDNS}},
+		"bad_name":     {Base: Base{ID: "bad zone", ParentPath: FolderDNS}},
+		"nested":       {Base: Base{ID: "lab.example.com", ParentPath: zone.GetPath()}},
+		"negative_ttl": {Base: Base{ID: "lab.com", ParentPath: FolderDNS}, TTL: -1},
+		"bad_contact":  {Base: Base{ID: "lab.com", ParentPath: FolderDNS}, Contact: "admin@@lab.com"},
+		"bad_ns":       {Base: Base{ID: "lab.com", ParentPath: FolderDNS}, NameServers: []string{"ns 1"}},
+	} {
+		if err := bad.Validate(c); err == nil {
+			t.Errorf("%s: expected validation error", name)
+		}
+	}
+
+	record := &DNSRecord{Base: Base{ID: "WWW.Example.com", ParentPath: zone.GetPath()}, RecordType: "A", RecordValue: "10.0.0.1", TTL: 60}
+	if err := record.Validate(c); err != nil {
+		t.Fatalf("Validate() error: %v", err)
+	}
+	if got := c.ZoneOf(record); got != zone {
+		t.Errorf("ZoneOf() = %v, want %v", got, zone)
+	}
+	record.ID = "www.example.net"
+	if err := record.Validate(c); err == nil || !strings.Contains(err.Error(), "outside its zone") {
+		t.Errorf("Validate() error = %v, want outside its zone", err)
+	}
+	record.ID = "example.com"
+	record.TTL = maxTTL + 1
+	if err := record.Validate(c); err == nil {
+		t.Error("expected error for a TTL above the maximum")
+	}
+
+	for text, want := range map[string]int{"": 0, " 300 ": 300, "2147483647": maxTTL} {
+		if got, err := ParseTTL(text); err != nil || got != want {
+			t.Errorf("ParseTTL(%q) = %d, %v; want %d", text, got, err, want)
+		}
+	}
+	for _, text := range []string{"-5", "1h", "2147483648"} {
+		if _, err := ParseTTL(text); err == nil {
+			t.Errorf("ParseTTL(%q): expected error", text)
+		}
+	}
+}
+
+// ---------- host_pool.go ----------
+
+func TestParseAddressRange(t *testing.T) {
+	pool := netip.MustParsePrefix("10.0.0.0/24")
+	tests := []struct {
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{"10.0.0.250-10.0.0.254", "10.0.0.250-10.0.0.254", false},
+		{".250-.254", "10.0.0.250-10.0.0.254", false},
+		{"10.0.0.1-10", "10.0.0.1-10.0.0.10", false},
+		{".5", "10.0.0.5", false},
+		{"10.0.0.16/29", "10.0.0.16-10.0.0.23", false},
+		{"10.0.0.10-10.0.0.1", "", true},
+		{"10.0.1.1", "", true},
+		{"nope", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParseAddressRange(pool, tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseAddressRange(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			}
+			if !tt.wantErr && got.String() != tt.want {
+				t.Errorf("ParseAddressRange(%q) = %s, want %s", tt.in, got, tt.want)
+			}
+		})
+	}
+
+	ranges, err := ParseAddressRanges(pool, ".1-.10, .250-.254\n")
+	if err != nil || !slices.Equal(ranges, []string{"10.0.0.1-10.0.0.10", "10.0.0.250-10.0.0.254"}) {
+		t.Errorf("ParseAddressRanges() = %v, %v", ranges, err)
+	}
+}
+
+func TestHostPoolSettingsValidation(t *testing.T) {
+	c := NewCatalog()
+	c.Put(&StaticFolder{Base: Base{ID: FolderNetworks}, Index: 0})
+	pool := &Network{
+		Base:           Base{ID: "10.0.0.0/24", ParentPath: FolderNetworks},
+		AllocationMode: AllocationModeHosts,
+		DisplayName:    "LAN",
+		Gateway:        "10.0.0.1",
+		ExcludedRanges: []string{"10.0.0.1-10.0.0.10", "10.0.0.250-10.0.0.254"},
+	}
+	c.Put(pool)
+	if err := pool.Validate(c); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+
+	gateway := &IP{Base: Base{ID: "10.0.0.1", ParentPath: pool.GetPath()}, DisplayName: "gw"}
+	if err := gateway.Validate(c); err != nil {
+		t.Errorf("gateway reservation should be allowed: %v", err)
+	}
+	excluded := &IP{Base: Base{ID: "10.0.0.252", ParentPath: pool.GetPath()}, DisplayName: "vrrp"}
+	if err := excluded.Validate(c); err == nil || !strings.Contains(err.Error(), "excluded range 10.0.0.250-10.0.0.254") {
+		t.Errorf("expected excluded range error, got %v", err)
+	}
+	c.Put(excluded)
+	if err := pool.Validate(c); err == nil {
+		t.Error("pool validation should flag existing reservations in excluded ranges")
+	}
+	c.Delete(excluded)
+
+	for name, mutate := range map[string]func(*Network){
+		"gateway outside":   func(n *Network) { n.Gateway = "10.0.1.1" },
+		"gateway broadcast": func(n *Network) { n.Gateway = "10.0.0.255" },
+		"bad range":         func(n *Network) { n.ExcludedRanges = []string{"x"} },
+		"container":         func(n *Network) { n.AllocationMode = AllocationModeSubnets },
+	} {
+		copied := *pool
+		mutate(&copied)
+		if err := copied.Validate(c); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
+
+func TestHostPoolSettingsFollowRenumberAndShrink(t *testing.T) {
+	c := NewCatalog()
+	c.Put(&StaticFolder{Base: Base{ID: FolderNetworks}, Index: 0})
+	pool := &Network{
+		Base:           Base{ID: "192.168.10.0/24", ParentPath: FolderNetworks},
+		AllocationMode: AllocationModeHosts,
+		DisplayName:    "LAN",
+		Gateway:        "192.168.10.1",
+		ExcludedRanges: []string{"192.168.10.250-192.168.10.254"},
+	}
+	c.Put(pool)
+	if err := pool.Renumber(c, "10.20.10.0/24"); err != nil {
+		t.Fatalf("Renumber() error = %v", err)
+	}
+	if pool.Gateway != "10.20.10.1" || !slices.Equal(pool.ExcludedRanges, []string{"10.20.10.250-10.20.10.254"}) {
+		t.Errorf("settings not rebased: %s %v", pool.Gateway, pool.ExcludedRanges)
+	}
+	if err := pool.Shrink(c, 25); err == nil {
+		t.Error("expected error when an excluded range would fall outside")
+	}
+}
@@ -0,0 +1,128 @@
+package domain
+
+import (
+	"cmp"
+	"fmt"
+	"math/big"
+	"net/netip"
+	"slices"
+)
+
+// FreeBlock is a maximal aligned block of unallocated address space.
+type FreeBlock struct {
+	CIDR string
+	// ParentPath is the path of the Subnet Container (or Networks folder) the block belongs to.
+	ParentPath string
+	Size       *big.Int
+}
+
+// FreeSpace collects unallocated space under root, which may be the Networks
+// folder or any network. Adjacent free pieces that share a parent are merged
+// and re-split into maximal aligned CIDRs. Blocks are returned in address order.
+func (c *Catalog) FreeSpace(root Item) ([]FreeBlock, error) {
+	var result []FreeBlock
+	var walkErr error
+
+	collect := func(parent Item, pieces []netip.Prefix) {
+		for _, prefix := range mergePrefixes(pieces) {
+			result = append(result, FreeBlock{
+				CIDR:       prefix.String(),
+				ParentPath: parent.GetPath(),
+				Size:       prefixAddressCount(prefix),
+			})
+		}
+	}
+
+	var visit func(n *Network)
+	visit = func(n *Network) {
+		if walkErr != nil || n.AllocationMode != AllocationModeSubnets {
+			return
+		}
+		blocks, err := c.AddressMap(n)
+		if err != nil {
+			walkErr = err
+			return
+		}
+		var pieces []netip.Prefix
+		for _, block := range blocks {
+			if block.Network == nil || block.Network.AllocationMode == AllocationModeUnallocated {
+				prefix, err := netip.ParsePrefix(block.CIDR)
+				if err != nil {
+					walkErr = fmt.Errorf("invalid CIDR %s: %w", block.CIDR, err)
+					return
+				}
+				pieces = append(pieces, prefix)
+				continue
+			}
+			visit(block.Network)
+		}
+		collect(n, pieces)
+	}
+
+	switch v := root.(type) {
+	case *Network:
+		if v.AllocationMode == AllocationModeUnallocated {
+			prefix, err := netip.ParsePrefix(v.ID)
+			if err != nil {
+				return nil, fmt.Errorf("invalid CIDR %s: %w", v.ID, err)
+			}
+			parent := c.Get(v.GetParentPath())
+			if parent == nil {
+				return nil, fmt.Errorf("parent not found for %s", v.GetPath())
+			}
+			collect(parent, []netip.Prefix{prefix})
+		}
+		visit(v)
+	default:
+		var pieces []netip.Prefix
+		for _, child := range c.GetChildren(root) {
+			n, ok := child.(*Network)
+			if !ok {
+				continue
+			}
+			if n.AllocationMode == AllocationModeUnallocated {
+				prefix, err := netip.ParsePrefix(n.ID)
+				if err != nil {
+					return nil, fmt.Errorf("invalid CIDR %s: %w", n.ID, err)
+				}
+				pieces = append(pieces, prefix)
+				continue
+			}
+			visit(n)
+		}
+		collect(root, pieces)
+	}
+	if walkErr != nil {
+		return nil, walkErr
+	}
+
+	SortFreeBlocksByAddress(result)
+	return result, nil
+}
+
+// SortFreeBlocksByAddress orders blocks by address, IPv4 before IPv6.
+func SortFreeBlocksByAddress(blocks []FreeBlock) {
+	slices.SortStableFunc(blocks, compareFreeBlockAddress)
+}
+
+// SortFreeBlocksBySize orders blocks from largest to smallest, then by address.
+func SortFreeBlocksBySize(blocks []FreeBlock) {
+	slices.SortStableFunc(blocks, func(l, r FreeBlock) int {
+		if c := r.Size.Cmp(l.Size); c != 0 {
+			return c
+		}
+		return compareFreeBlockAddress(l, r)
+	})
+}
+
+func compareFreeBlockAddress(l, r FreeBlock) int {
+	lp, lErr := netip.ParsePrefix(l.CIDR)
+	rp, rErr := netip.ParsePrefix(r.CIDR)
+	if lErr != nil || rErr != nil {
+		return cmp.Compare(l.CIDR, r.CIDR)
+	}
+	if c := lp.Addr().Compare(rp.Addr()); c != 0 {
+		return c
+	}
+	return cmp.Compare(lp.Bits(), rp.Bits())
+}
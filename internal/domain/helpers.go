@@ -89,6 +89,28 @@ func ParseTaggedMode(text string) TaggedVLANMode {
 	}
 }
 
+// NormalizeMACAddress returns a MAC address as six lowercase colon-separated
+// octets, or an empty string for empty input.
+func NormalizeMACAddress(input string) (string, error) {
+	input = strings.TrimSpace(input)
+	if input == "" {
+		return "", nil
+	}
+	hwAddr, err := net.ParseMAC(input)
+	if err != nil {
+		return "", err
+	}
+	if len(hwAddr) < 6 {
+		return "", fmt.Errorf("MAC address %q is too short", input)
+	}
+	hwAddr = hwAddr[:6]
+	parts := make([]string, 0, len(hwAddr))
+	for _, b := range hwAddr {
+		parts = append(parts, hex.EncodeToString([]byte{b}))
+	}
+	return strings.ToLower(strings.Join(parts, ":")), nil
+}
+
 // ValidateHostname validates a relaxed hostname/FQDN.
 func ValidateHostname(value string) error {
 	value = strings.TrimSpace(value)
@@ -0,0 +1,247 @@
+package domain
+
+import (
+	"fmt"
+	"net/netip"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// AddressRange is an inclusive range of addresses, such as an excluded range
+// of a Host Pool.
+type AddressRange struct {
+	Start netip.Addr
+	End   netip.Addr
+}
+
+// Contains reports whether addr lies in the range.
+func (r AddressRange) Contains(addr netip.Addr) bool {
+	return r.Start.Compare(addr) <= 0 && addr.Compare(r.End) <= 0
+}
+
+// String returns the range as "start-end", or a single address.
+func (r AddressRange) String() string {
+	if r.Start == r.End {
+		return r.Start.String()
+	}
+	return r.Start.String() + "-" + r.End.String()
+}
+
+// ParseAddressRange parses a range inside pool. Accepted forms are a single
+// address, "start-end", a CIDR, and for IPv4 a last-octet shorthand relative
+// to the pool's network address: ".250-.254", "10.0.0.250-254" or ".5".
+func ParseAddressRange(pool netip.Prefix, text string) (AddressRange, error) {
+	text = strings.TrimSpace(text)
+	if prefix, err := netip.ParsePrefix(text); err == nil {
+		r := AddressRange{Start: prefix.Masked().Addr(), End: LastAddr(prefix)}
+		return r, checkRangeInPool(pool, r, text)
+	}
+	startText, endText, isRange := strings.Cut(text, "-")
+	start, err := parsePoolAddr(pool, pool.Masked().Addr(), startText)
+	if err != nil {
+		return AddressRange{}, fmt.Errorf("invalid range %q: %w", text, err)
+	}
+	end := start
+	if isRange {
+		if end, err = parsePoolAddr(pool, start, endText); err != nil {
+			return AddressRange{}, fmt.Errorf("invalid range %q: %w", text, err)
+		}
+	}
+	r := AddressRange{Start: start, End: end}
+	if end.Less(start) {
+		return AddressRange{}, fmt.Errorf("invalid range %q: end is before start", text)
+	}
+	return r, checkRangeInPool(pool, r, text)
+}
+
+// parsePoolAddr parses a full address or an IPv4 last octet (with or without
+// a leading dot) that replaces the last octet of base.
+func parsePoolAddr(pool netip.Prefix, base netip.Addr, text string) (netip.Addr, error) {
+	text = strings.TrimSpace(text)
+	if addr, err := netip.ParseAddr(text); err == nil {
+		return addr, nil
+	}
+	if !pool.Addr().Is4() {
+		return netip.Addr{}, fmt.Errorf("%q is not an IP address", text)
+	}
+	octet, err := strconv.Atoi(strings.TrimPrefix(text, "."))
+	if err != nil || octet < 0 || octet > 255 {
+		return netip.Addr{}, fmt.Errorf("%q is not an IP address or last octet", text)
+	}
+	b := base.As4()
+	b[3] = byte(octet)
+	return netip.AddrFrom4(b), nil
+}
+
+func checkRangeInPool(pool netip.Prefix, r AddressRange, text string) error {
+	if !pool.Contains(r.Start) || !pool.Contains(r.End) {
+		return fmt.Errorf("range %q is outside %s", text, pool)
+	}
+	return nil
+}
+
+// ParseAddressRanges parses comma- or newline-separated ranges inside pool,
+// such as excluded or DHCP ranges, and returns them in canonical form.
+func ParseAddressRanges(pool netip.Prefix, text string) ([]string, error) {
+	var ranges []string
+	for _, field := range strings.FieldsFunc(text, func(r rune) bool { return r == ',' || r == '\n' }) {
+		if strings.TrimSpace(field) == "" {
+			continue
+		}
+		r, err := ParseAddressRange(pool, field)
+		if err != nil {
+			return nil, err
+		}
+		ranges = append(ranges, r.String())
+	}
+	return ranges, nil
+}
+
+// ExcludedRangeOf returns the excluded range of n that contains addr, or ""
+// when addr may be reserved. The gateway is never considered excluded.
+func (n *Network) ExcludedRangeOf(addr netip.Addr) string {
+	if gateway, err := netip.ParseAddr(n.Gateway); err == nil && gateway == addr {
+		return ""
+	}
+	prefix, err := netip.ParsePrefix(n.ID)
+	if err != nil {
+		return ""
+	}
+	for _, text := range n.ExcludedRanges {
+		if r, err := ParseAddressRange(prefix, text); err == nil && r.Contains(addr) {
+			return text
+		}
+	}
+	return ""
+}
+
+// HostPoolContaining returns the deepest Host Pool whose prefix contains
+// addr, or nil when no Host Pool does.
+func (c *Catalog) HostPoolContaining(addr netip.Addr) *Network {
+	var best *Network
+	bestBits := -1
+	for _, item := range c.All() {
+		n, ok := item.(*Network)
+		if !ok || n.AllocationMode != AllocationModeHosts {
+			continue
+		}
+		prefix, err := netip.ParsePrefix(n.ID)
+		if err != nil || !prefix.Contains(addr) {
+			continue
+		}
+		if prefix.Bits() > bestBits || (prefix.Bits() == bestBits && n.GetPath() < best.GetPath()) {
+			best, bestBits = n, prefix.Bits()
+		}
+	}
+	return best
+}
+
+// ReservationsAt returns the reservations of addr, sorted by path.
+func (c *Catalog) ReservationsAt(addr netip.Addr) []*IP {
+	var ips []*IP
+	for _, item := range c.All() {
+		if ip, ok := item.(*IP); ok {
+			if parsed, err := netip.ParseAddr(ip.ID); err == nil && parsed == addr {
+				ips = append(ips, ip)
+			}
+		}
+	}
+	slices.SortFunc(ips, func(l, r *IP) int { return strings.Compare(l.GetPath(), r.GetPath()) })
+	return ips
+}
+
+// validateHostPoolSettings checks the gateway, excluded ranges and DHCP
+// settings of n and, with a catalog, that no reservation of n lies in an
+// excluded range.
+func (n *Network) validateHostPoolSettings(c *Catalog, prefix netip.Prefix) error {
+	if n.Gateway == "" && len(n.ExcludedRanges) == 0 && len(n.DHCPRanges) == 0 && n.DHCPOptions == nil {
+		return nil
+	}
+	if n.AllocationMode != AllocationModeHosts {
+		return fmt.Errorf("gateway, excluded ranges and DHCP settings can only be set on Host Pools, not on %s", n.ID)
+	}
+	if n.Gateway != "" {
+		gateway, err := netip.ParseAddr(n.Gateway)
+		if err != nil {
+			return fmt.Errorf("invalid gateway %q for network %s: %w", n.Gateway, n.ID, err)
+		}
+		if !isUsableHost(prefix, gateway) {
+			return fmt.Errorf("gateway %s is not a usable host of %s", n.Gateway, n.ID)
+		}
+	}
+	for _, text := range n.ExcludedRanges {
+		if _, err := ParseAddressRange(prefix, text); err != nil {
+			return fmt.Errorf("network %s: %w", n.ID, err)
+		}
+	}
+	if err := n.validateDHCPRanges(prefix); err != nil {
+		return err
+	}
+	if err := n.validateDHCPOptions(prefix); err != nil {
+		return err
+	}
+	if c == nil {
+		return nil
+	}
+	for _, child := range c.GetChildren(n) {
+		ip, ok := child.(*IP)
+		if !ok {
+			continue
+		}
+		if addr, err := netip.ParseAddr(ip.ID); err == nil {
+			if r := n.ExcludedRangeOf(addr); r != "" {
+				return fmt.Errorf("reserved IP %s falls in excluded range %s of %s", ip.ID, r, n.ID)
+			}
+		}
+	}
+	return nil
+}
+
+// rebaseHostPoolSettings shifts the gateway, excluded ranges and DHCP ranges
+// of every network in the subtree of n from the from prefix to the to prefix.
+func (c *Catalog) rebaseHostPoolSettings(n *Network, from, to netip.Prefix) {
+	networks := []*Network{n}
+	for _, item := range c.All() {
+		if other, ok := item.(*Network); ok && isSameOrDescendant(other.GetPath(), n.GetPath()) && other != n {
+			networks = append(networks, other)
+		}
+	}
+	for _, network := range networks {
+		rebaseHostPoolFields(network, network, from, to)
+	}
+}
+
+// rebaseHostPoolFields sets the host-pool settings of dst, including the DHCP
+// router, to those of src shifted from the from prefix to the to prefix.
+func rebaseHostPoolFields(dst, src *Network, from, to netip.Prefix) {
+	gateway := src.Gateway
+	if gateway != "" {
+		gateway = rebaseID(gateway, from, to)
+	}
+	prefix, err := netip.ParsePrefix(src.ID)
+	rebaseRanges := func(texts []string) []string {
+		var ranges []string
+		for _, text := range texts {
+			r, parseErr := ParseAddressRange(prefix, text)
+			if err != nil || parseErr != nil {
+				ranges = append(ranges, text)
+				continue
+			}
+			r.Start = netip.MustParseAddr(rebaseID(r.Start.String(), from, to))
+			r.End = netip.MustParseAddr(rebaseID(r.End.String(), from, to))
+			ranges = append(ranges, r.String())
+		}
+		return ranges
+	}
+	var options *DHCPOptions
+	if src.DHCPOptions != nil {
+		copied := *src.DHCPOptions
+		if copied.Router != "" {
+			copied.Router = rebaseID(copied.Router, from, to)
+		}
+		options = &copied
+	}
+	excludedRanges, dhcpRanges := rebaseRanges(src.ExcludedRanges), rebaseRanges(src.DHCPRanges)
+	dst.Gateway, dst.ExcludedRanges, dst.DHCPRanges, dst.DHCPOptions = gateway, excludedRanges, dhcpRanges, options
+}
@@ -0,0 +1,263 @@
+package domain
+
+import (
+	"fmt"
+	"math/big"
+	"net/netip"
+	"slices"
+	"strings"
+)
+
+// Unallocated space inside a Subnet Container does not have to be stored.
+// Any range no stored child covers is implicit free space: it is listed as
+// unallocated networks (one per maximal aligned CIDR) and only written to the
+// catalog once it is allocated or otherwise modified. A split network is
+// stored once and listed as its subnets, which are implicit in the same way.
+
+// ChildrenWithFreeSpace returns the children of parent like GetChildren, with
+// every split network replaced by its subnets and, for a Subnet Container, an
+// implicit unallocated network for every gap between stored child networks.
+// Implicit networks are not stored in the catalog.
+func (c *Catalog) ChildrenWithFreeSpace(parent Item) []Item {
+	stored := c.GetChildren(parent)
+	children := make([]Item, 0, len(stored))
+	for _, child := range stored {
+		if n, ok := child.(*Network); ok && n.SplitBits != 0 {
+			for _, subnet := range splitSubnets(parent, n) {
+				children = append(children, subnet)
+			}
+			continue
+		}
+		children = append(children, child)
+	}
+	n, ok := parent.(*Network)
+	if !ok || n.AllocationMode != AllocationModeSubnets {
+		return children
+	}
+	blocks, err := c.AddressMap(n)
+	if err != nil {
+		return children
+	}
+	added := false
+	for _, block := range blocks {
+		if block.Network == nil {
+			children = append(children, newImplicitNetwork(n, block.CIDR))
+			added = true
+		}
+	}
+	if added {
+		slices.SortStableFunc(children, func(a, b Item) int {
+			return a.Compare(b)
+		})
+	}
+	return children
+}
+
+// Lookup returns the item at path like Get, and also resolves implicit
+// unallocated networks listed by ChildrenWithFreeSpace.
+func (c *Catalog) Lookup(path string) Item {
+	if item := c.Get(path); item != nil {
+		return item
+	}
+	idx := strings.LastIndex(path, " -> ")
+	if idx < 0 {
+		return nil
+	}
+	parent := c.Get(path[:idx])
+	if parent == nil {
+		return nil
+	}
+	id := path[idx+len(" -> "):]
+	prefix, err := netip.ParsePrefix(id)
+	if err != nil {
+		return nil
+	}
+	for _, block := range c.freeBlocksOverlapping(parent, prefix) {
+		for _, subnet := range splitSubnets(parent, block) {
+			if subnet.ID == id {
+				return subnet
+			}
+		}
+	}
+	container, ok := parent.(*Network)
+	if !ok || container.AllocationMode != AllocationModeSubnets {
+		return nil
+	}
+	blocks, err := c.AddressMap(container)
+	if err != nil {
+		return nil
+	}
+	for _, block := range blocks {
+		if block.Network == nil && block.CIDR == id {
+			return newImplicitNetwork(container, id)
+		}
+	}
+	return nil
+}
+
+// IsImplicit reports whether n is implicit free space rather than a stored network.
+func (c *Catalog) IsImplicit(n *Network) bool {
+	return c.Get(n.GetPath()) == nil
+}
+
+// Materialize stores an implicit network so it can be modified, carving it
+// out of the split network it is listed from. Networks that are already
+// stored are left untouched.
+func (c *Catalog) Materialize(n *Network) error {
+	if !c.IsImplicit(n) {
+		return nil
+	}
+	parent := c.Get(n.GetParentPath())
+	if parent == nil {
+		return fmt.Errorf("parent not found for network %s", n.GetPath())
+	}
+	prefix, err := netip.ParsePrefix(n.ID)
+	if err != nil {
+		return fmt.Errorf("invalid CIDR %s: %w", n.ID, err)
+	}
+	blocks := c.freeBlocksOverlapping(parent, prefix)
+	if _, err := c.CarveFreeBlock(parent, n.ID); err != nil {
+		return err
+	}
+	if err := c.Add(n); err != nil {
+		c.restoreFreeBlocks(parent, blocks)
+		return err
+	}
+	return nil
+}
+
+// Split splits the unallocated network n into subnets of prefix length
+// newBits, or in halves when newBits is below 1. Only n itself is stored,
+// with SplitBits set, so the cost does not depend on the number of subnets:
+// ChildrenWithFreeSpace lists the subnets in its place, and a subnet is
+// stored on its own once it is allocated or split again.
+func (n *Network) Split(c *Catalog, newBits int) error {
+	if n.AllocationMode != AllocationModeUnallocated {
+		return fmt.Errorf("cannot split allocated network %s", n.GetPath())
+	}
+	prefix, err := netip.ParsePrefix(n.ID)
+	if err != nil {
+		return fmt.Errorf("invalid CIDR %s: %w", n.ID, err)
+	}
+	if newBits < 1 {
+		newBits = prefix.Bits() + 1
+	}
+	split := *n
+	split.SplitBits = newBits
+	if err := split.Validate(c); err != nil {
+		return err
+	}
+	if err := c.Materialize(n); err != nil {
+		return err
+	}
+	n.SplitBits = newBits
+	return nil
+}
+
+// CarveFreeBlock returns an implicit unallocated network for cidr, which must
+// lie entirely in free space of parent (a Subnet Container or the Networks
+// folder). Stored unallocated children that overlap cidr are replaced by the
+// maximal aligned blocks left around it, which stay split like the child.
+func (c *Catalog) CarveFreeBlock(parent Item, cidr string) (*Network, error) {
+	target, err := netip.ParsePrefix(cidr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid CIDR %s: %w", cidr, err)
+	}
+
+	var overlapping []*Network
+	for _, child := range c.GetChildren(parent) {
+		n, ok := child.(*Network)
+		if !ok {
+			continue
+		}
+		prefix, err := netip.ParsePrefix(n.ID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid CIDR %s: %w", n.ID, err)
+		}
+		if !prefix.Overlaps(target) {
+			continue
+		}
+		if n.AllocationMode != AllocationModeUnallocated {
+			return nil, fmt.Errorf("%s overlaps allocated network %s", cidr, n.GetPath())
+		}
+		overlapping = append(overlapping, n)
+	}
+
+	targetStart, targetEnd := IPToBigInt(target.Masked().Addr()), IPToBigInt(LastAddr(target))
+	one := big.NewInt(1)
+	for _, n := range overlapping {
+		prefix := netip.MustParsePrefix(n.ID)
+		c.Delete(n)
+		start, end := IPToBigInt(prefix.Masked().Addr()), IPToBigInt(LastAddr(prefix))
+		var leftovers []netip.Prefix
+		if start.Cmp(targetStart) < 0 {
+			leftovers = append(leftovers, rangeToPrefixes(start, new(big.Int).Sub(targetStart, one), prefix.Addr().Is4())...)
+		}
+		if end.Cmp(targetEnd) > 0 {
+			leftovers = append(leftovers, rangeToPrefixes(new(big.Int).Add(targetEnd, one), end, prefix.Addr().Is4())...)
+		}
+		for _, leftover := range leftovers {
+			block := newImplicitNetwork(parent, leftover.String())
+			if n.SplitBits > leftover.Bits() {
+				block.SplitBits = n.SplitBits
+			}
+			c.Put(block)
+		}
+	}
+	return newImplicitNetwork(parent, target.String()), nil
+}
+
+// freeBlocksOverlapping returns the stored unallocated children of parent
+// that overlap prefix.
+func (c *Catalog) freeBlocksOverlapping(parent Item, prefix netip.Prefix) []*Network {
+	var blocks []*Network
+	for _, child := range c.GetChildren(parent) {
+		n, ok := child.(*Network)
+		if !ok || n.AllocationMode != AllocationModeUnallocated {
+			continue
+		}
+		if childPrefix, err := netip.ParsePrefix(n.ID); err == nil && childPrefix.Overlaps(prefix) {
+			blocks = append(blocks, n)
+		}
+	}
+	return blocks
+}
+
+// restoreFreeBlocks undoes CarveFreeBlock: blocks, taken from
+// freeBlocksOverlapping before the carve, replace the stored unallocated
+// children of parent that were left in their place.
+func (c *Catalog) restoreFreeBlocks(parent Item, blocks []*Network) {
+	for _, block := range blocks {
+		for _, leftover := range c.freeBlocksOverlapping(parent, netip.MustParsePrefix(block.ID)) {
+			c.Delete(leftover)
+		}
+		c.Put(block)
+	}
+}
+
+// splitSubnets returns the implicit subnets ChildrenWithFreeSpace lists for
+// the child n of parent: those of SplitNetwork when n is split, otherwise none.
+func splitSubnets(parent Item, n *Network) []*Network {
+	if n.SplitBits == 0 {
+		return nil
+	}
+	cidrs, err := SplitNetwork(n.ID, n.SplitBits)
+	if err != nil {
+		return []*Network{n}
+	}
+	subnets := make([]*Network, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		subnets = append(subnets, newImplicitNetwork(parent, cidr))
+	}
+	return subnets
+}
+
+func newImplicitNetwork(parent Item, cidr string) *Network {
+	return &Network{
+		Base: Base{
+			ID:         cidr,
+			ParentPath: parent.GetPath(),
+		},
+		AllocationMode: AllocationModeUnallocated,
+	}
+}
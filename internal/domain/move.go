@@ -0,0 +1,238 @@
+package domain
+
+import (
+	"cmp"
+	"fmt"
+	"math/big"
+	"net/netip"
+	"slices"
+	"strings"
+)
+
+// MoveTargets returns the parents n can be moved under: the Networks folder
+// and every Subnet Container of the same address family with a free block of
+// n's size. n's own subtree and current parent are excluded. Targets are
+// ordered by path.
+func (c *Catalog) MoveTargets(n *Network) []Item {
+	return c.placementTargets(n, false)
+}
+
+func (c *Catalog) placementTargets(n *Network, includeCurrent bool) []Item {
+	var targets []Item
+	if folder := c.GetByParentAndDisplayID(nil, FolderNetworks); folder != nil && (includeCurrent || folder.GetPath() != n.GetParentPath()) {
+		targets = append(targets, folder)
+	}
+	for _, item := range c.All() {
+		container, ok := item.(*Network)
+		if !ok || container.AllocationMode != AllocationModeSubnets {
+			continue
+		}
+		if !includeCurrent && container.GetPath() == n.GetParentPath() {
+			continue
+		}
+		if isSameOrDescendant(container.GetPath(), n.GetPath()) {
+			continue
+		}
+		if _, err := c.MovePlacement(n, container); err == nil {
+			targets = append(targets, container)
+		}
+	}
+	slices.SortFunc(targets, func(l, r Item) int {
+		return cmp.Compare(l.GetPath(), r.GetPath())
+	})
+	return targets
+}
+
+// MovePlacement suggests the CIDR n would take under newParent: its current
+// CIDR if that is free there, otherwise the lowest free block of the same size
+// in a Subnet Container.
+func (c *Catalog) MovePlacement(n *Network, newParent Item) (string, error) {
+	prefix, err := netip.ParsePrefix(n.ID)
+	if err != nil {
+		return "", fmt.Errorf("invalid CIDR %s: %w", n.ID, err)
+	}
+	if err := c.checkMoveTarget(newParent, prefix); err == nil {
+		return n.ID, nil
+	}
+	container, ok := newParent.(*Network)
+	if !ok {
+		return "", fmt.Errorf("%s overlaps another network under %s; enter a new CIDR", n.ID, newParent.GetPath())
+	}
+	plan, err := c.PlanSubnets(container, []SubnetRequirement{{Name: n.ID, PrefixLen: prefix.Bits()}})
+	if err != nil {
+		return "", err
+	}
+	return plan[0].CIDR, nil
+}
+
+// Move re-parents n, with all its descendants, under newParent (the Networks
+// folder or a Subnet Container) at newCIDR, which must have the same size as
+// n; an empty newCIDR keeps the current one. Child networks and IP reservations
+// keep their offsets inside n. Paths of descendants and DNS aliases pointing
+// into the subtree are rewritten. If the moved subtree does not validate,
+// nothing is changed.
+func (n *Network) Move(c *Catalog, newParent Item, newCIDR string) error {
+	from, to, err := c.moveDestination(n, newParent, newCIDR)
+	if err != nil {
+		return err
+	}
+	// Keep the stored free blocks the carve replaces, so that an invalid move
+	// can be undone without losing that space.
+	freeBlocks := c.freeBlocksOverlapping(newParent, to)
+	if _, err := c.CarveFreeBlock(newParent, to.String()); err != nil {
+		return fmt.Errorf("cannot move %s: %w", n.ID, err)
+	}
+	oldParentPath := n.ParentPath
+	c.rebaseHostPoolSettings(n, from, to)
+	c.relocate(n, newParent.GetPath(), func(item Item) string {
+		return rebaseID(item.RawID(), from, to)
+	})
+	if err := c.validateSubtree(n); err != nil {
+		c.rebaseHostPoolSettings(n, to, from)
+		c.relocate(n, oldParentPath, func(item Item) string {
+			return rebaseID(item.RawID(), to, from)
+		})
+		c.restoreFreeBlocks(newParent, freeBlocks)
+		return fmt.Errorf("cannot move %s: %w", n.ID, err)
+	}
+	return nil
+}
+
+// validateSubtree validates n, its descendants and the DNS records aliasing
+// addresses among them, in path order.
+func (c *Catalog) validateSubtree(n *Network) error {
+	var items []Item
+	for path, item := range c.items {
+		if isSameOrDescendant(path, n.GetPath()) {
+			items = append(items, item)
+			continue
+		}
+		record, ok := item.(*DNSRecord)
+		if !ok {
+			continue
+		}
+		for _, target := range append([]string{record.ReservedIPPath}, record.ReservedIPPaths...) {
+			if target != "" && isSameOrDescendant(target, n.GetPath()) {
+				items = append(items, item)
+				break
+			}
+		}
+	}
+	slices.SortFunc(items, func(l, r Item) int {
+		return cmp.Compare(l.GetPath(), r.GetPath())
+	})
+	for _, item := range items {
+		if err := item.Validate(c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// moveDestination validates moving n under newParent at newCIDR and returns
+// n's current prefix and the one it would take. n's own space counts as free.
+func (c *Catalog) moveDestination(n *Network, newParent Item, newCIDR string) (from, to netip.Prefix, err error) {
+	if newParent == nil {
+		return from, to, fmt.Errorf("new parent must be set")
+	}
+	if isSameOrDescendant(newParent.GetPath(), n.GetPath()) {
+		return from, to, fmt.Errorf("cannot move %s under itself", n.ID)
+	}
+	if n.AllocationMode == AllocationModeUnallocated {
+		return from, to, fmt.Errorf("only allocated networks can be moved")
+	}
+	from, err = netip.ParsePrefix(n.ID)
+	if err != nil {
+		return from, to, fmt.Errorf("invalid CIDR %s: %w", n.ID, err)
+	}
+	if newCIDR == "" {
+		newCIDR = n.ID
+	}
+	to, err = netip.ParsePrefix(newCIDR)
+	if err != nil {
+		return from, to, fmt.Errorf("invalid CIDR %s: %w", newCIDR, err)
+	}
+	if to.Masked() != to {
+		return from, to, fmt.Errorf("invalid CIDR %s: host bits must be zero (did you mean %s?)", newCIDR, to.Masked())
+	}
+	if to.Addr().Is4() != from.Addr().Is4() || to.Bits() != from.Bits() {
+		return from, to, fmt.Errorf("%s must be a /%d of the same address family as %s", newCIDR, from.Bits(), n.ID)
+	}
+	if newParent.GetPath() == n.GetParentPath() && to == from {
+		return from, to, fmt.Errorf("%s is already under %s", n.ID, newParent.GetPath())
+	}
+
+	oldPath := n.GetPath()
+	delete(c.items, oldPath)
+	err = c.checkMoveTarget(newParent, to)
+	c.items[oldPath] = n
+	if err != nil {
+		return from, to, fmt.Errorf("cannot move %s: %w", n.ID, err)
+	}
+	return from, to, nil
+}
+
+// checkMoveTarget reports whether prefix can be placed under parent: parent
+// must be the Networks folder or a Subnet Container containing prefix, and no
+// allocated child there may overlap it.
+func (c *Catalog) checkMoveTarget(parent Item, prefix netip.Prefix) error {
+	switch p := parent.(type) {
+	case *StaticFolder:
+		if p.ID != FolderNetworks {
+			return fmt.Errorf("networks can only be moved under Networks or a Subnet Container")
+		}
+	case *Network:
+		if p.AllocationMode != AllocationModeSubnets {
+			return fmt.Errorf("%s is not a Subnet Container", p.GetPath())
+		}
+		parentPrefix, err := netip.ParsePrefix(p.ID)
+		if err != nil {
+			return fmt.Errorf("invalid CIDR %s: %w", p.ID, err)
+		}
+		if parentPrefix.Addr().Is4() != prefix.Addr().Is4() || parentPrefix.Bits() >= prefix.Bits() || !parentPrefix.Contains(prefix.Addr()) {
+			return fmt.Errorf("%s is not within %s", prefix, p.GetPath())
+		}
+	default:
+		return fmt.Errorf("networks can only be moved under Networks or a Subnet Container")
+	}
+	if other := c.overlappingAllocatedChild(parent, prefix); other != nil {
+		return fmt.Errorf("%s overlaps allocated network %s", prefix, other.GetPath())
+	}
+	return nil
+}
+
+// overlappingAllocatedChild returns the first allocated child network of
+// parent that overlaps prefix, or nil.
+func (c *Catalog) overlappingAllocatedChild(parent Item, prefix netip.Prefix) *Network {
+	for _, child := range c.GetChildren(parent) {
+		n, ok := child.(*Network)
+		if !ok || n.AllocationMode == AllocationModeUnallocated {
+			continue
+		}
+		if childPrefix, err := netip.ParsePrefix(n.ID); err == nil && childPrefix.Overlaps(prefix) {
+			return n
+		}
+	}
+	return nil
+}
+
+// rebaseID maps a CIDR or address inside from to the same offset inside to.
+// IDs that are neither are returned unchanged.
+func rebaseID(id string, from, to netip.Prefix) string {
+	shift := func(addr netip.Addr) netip.Addr {
+		offset := new(big.Int).Sub(IPToBigInt(addr), IPToBigInt(from.Masked().Addr()))
+		return BigIntToAddr(offset.Add(offset, IPToBigInt(to.Masked().Addr())), to.Addr().Is4())
+	}
+	if prefix, err := netip.ParsePrefix(id); err == nil {
+		return netip.PrefixFrom(shift(prefix.Addr()), prefix.Bits()).String()
+	}
+	if addr, err := netip.ParseAddr(id); err == nil {
+		return shift(addr).String()
+	}
+	return id
+}
+
+// isSameOrDescendant reports whether path is ancestor itself or lies below it.
+func isSameOrDescendant(path, ancestor string) bool {
+	return path == ancestor || strings.HasPrefix(path, ancestor+" -> ")
+}
@@ -11,8 +11,9 @@ import (
 )
 
 // RenderDetailsMap returns an ordered list of keys and a map of detail values
-// for a network. This is pure computation without UI side effects.
-func (n *Network) RenderDetailsMap(c *Catalog) ([]string, map[string]string, error) {
+// for a network. This is pure computation without UI side effects. usage,
+// when not nil, is shared by the networks of one render pass.
+func (n *Network) RenderDetailsMap(c *Catalog, usage *UtilizationIndex) ([]string, map[string]string, error) {
 	index := []string{}
 	result := map[string]string{}
 
@@ -106,6 +107,79 @@ func (n *Network) RenderDetailsMap(c *Catalog) ([]string, map[string]string, err
 	default:
 		panic("Unknown AllocationMode")
 	}
+	if c != nil && usage == nil {
+		usage = c.NewUtilizationIndex()
+	}
+	if c != nil && n.AllocationMode != AllocationModeUnallocated {
+		u, err := usage.Utilization(n)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error computing utilization for %s: %w", n.ID, err)
+		}
+		if n.AllocationMode == AllocationModeSubnets {
+			index = append(index, "Allocated")
+			result["Allocated"] = p.Sprintf("%s of %s addresses (%.1f%%)", formatBigCount(p, u.Allocated), formatBigCount(p, u.Total), u.AllocatedPercent())
+			index = append(index, "Unallocated")
+			result["Unallocated"] = p.Sprintf("%s addresses", formatBigCount(p, u.Unallocated()))
+			index = append(index, "Largest Free Block")
+			result["Largest Free Block"] = defaultString(u.LargestFree, "<none>")
+		}
+		if n.AllocationMode == AllocationModeHosts || u.Usable.Sign() > 0 {
+			reserved := p.Sprintf("%d of %s usable (%.1f%%)", u.Reserved, formatBigCount(p, u.Usable), u.ReservedPercent())
+			if c.IsNearlyExhausted(n, u) {
+				reserved += p.Sprintf(" - nearly exhausted (threshold %d%%)", c.Config.PoolWarnThreshold())
+			}
+			index = append(index, "Reserved IPs")
+			result["Reserved IPs"] = reserved
+		}
+	}
+	if n.Gateway != "" {
+		index = append(index, "Gateway")
+		result["Gateway"] = n.Gateway
+	}
+	if len(n.ExcludedRanges) > 0 {
+		index = append(index, "Excluded Ranges")
+		result["Excluded Ranges"] = strings.Join(n.ExcludedRanges, ", ")
+	}
+	if len(n.DHCPRanges) > 0 {
+		index = append(index, "DHCP Ranges")
+		result["DHCP Ranges"] = strings.Join(n.DHCPRanges, ", ")
+		if c != nil {
+			if u, err := usage.Utilization(n); err == nil && u.Usable.Sign() > 0 {
+				// DHCP ranges hold only usable hosts outside the excluded
+				// ranges, so the three parts add up to the usable count.
+				dynamic := n.DHCPRangeSize()
+				excluded := n.excludedHostCount()
+				static := new(big.Int).Sub(u.Usable, dynamic)
+				static.Sub(static, excluded)
+				split := p.Sprintf("%s dynamic (DHCP), %s static", formatBigCount(p, dynamic), formatBigCount(p, static))
+				if excluded.Sign() > 0 {
+					split += p.Sprintf(", %s excluded", formatBigCount(p, excluded))
+				}
+				index = append(index, "Address Split")
+				result["Address Split"] = split
+			}
+			if warnings := c.DHCPWarnings(n); len(warnings) > 0 {
+				index = append(index, "DHCP Warnings")
+				result["DHCP Warnings"] = strings.Join(warnings, "; ")
+			}
+		}
+	}
+	if o := n.DHCPOptions; o != nil {
+		for _, option := range []struct{ key, value string }{
+			{"DHCP Router", n.EffectiveRouter()},
+			{"DHCP DNS Servers", strings.Join(o.DNSServers, ", ")},
+			{"DHCP Domain", o.DomainName},
+			{"DHCP Search Domains", strings.Join(o.SearchDomains, ", ")},
+			{"DHCP NTP Servers", strings.Join(o.NTPServers, ", ")},
+			{"DHCP Lease Time", o.LeaseTime},
+			{"DHCP Custom Options", FormatDHCPCustomOptions(o.Custom)},
+		} {
+			if option.value != "" {
+				index = append(index, option.key)
+				result[option.key] = option.value
+			}
+		}
+	}
 	if n.AllocationMode != AllocationModeUnallocated {
 		result["Description"] = n.Description
 	}
@@ -124,12 +198,28 @@ func (n *Network) RenderDetailsMap(c *Catalog) ([]string, map[string]string, err
 	return index, result, nil
 }
 
+// formatBigCount formats an address count with digit grouping, falling back
+// to plain digits for counts that do not fit in 64 bits.
+func formatBigCount(p *message.Printer, n *big.Int) string {
+	if n.IsUint64() {
+		return p.Sprintf("%d", n.Uint64())
+	}
+	return n.String()
+}
+
+func defaultString(value, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
+}
+
 // RenderDetails returns a human-readable string of network details.
 func (n *Network) RenderDetails(c *Catalog) string {
 	stringWriter := new(strings.Builder)
 	template := "%-20s: %s\n"
 
-	index, data, err := n.RenderDetailsMap(c)
+	index, data, err := n.RenderDetailsMap(c, nil)
 	if err != nil {
 		return fmt.Sprintf("Error rendering details: %v", err)
 	}
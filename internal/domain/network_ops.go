@@ -5,124 +5,149 @@ import (
 	"fmt"
 	"math/big"
 	"net/netip"
-	"sort"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
 )
 
-// SplitNetwork splits a CIDR into subnets of the given prefix size.
+// maxListedSplitBits is log2 of the number of subnets SplitNetwork lists.
+const maxListedSplitBits = 10
+
+// SplitNetwork splits a CIDR into subnets of the given prefix size and
+// returns them in address order. A split into more than 1024 subnets lists
+// the first 1024 and covers the rest of the range with maximal aligned
+// blocks, so the list stays short however small the subnets are.
 func SplitNetwork(cidr string, newSize int) ([]string, error) {
 	prefix, err := netip.ParsePrefix(cidr)
 	if err != nil {
 		return nil, fmt.Errorf("invalid CIDR: %w", err)
 	}
-
-	origSize := prefix.Bits()
-	addrLen := prefix.Addr().BitLen()
-
 	if newSize < 1 {
-		newSize = origSize + 1
+		newSize = prefix.Bits() + 1
 	}
-
-	if newSize <= origSize || newSize > addrLen {
+	if newSize <= prefix.Bits() || newSize > prefix.Addr().BitLen() {
 		return nil, fmt.Errorf("invalid new size: must be larger than original but not exceed address bit length")
 	}
 
-	numSubnets := 1 << (newSize - origSize)
-	maxSubnets := 1024
-	if numSubnets > maxSubnets {
-		return nil, fmt.Errorf("splitting would create %d subnets, current limit is %d due to performance issues", numSubnets, maxSubnets)
-	}
+	addrLen := prefix.Addr().BitLen()
+	isIPv4 := prefix.Addr().Is4()
+	step := new(big.Int).Lsh(big.NewInt(1), uint(addrLen-newSize))
+	currIP := IPToBigInt(prefix.Masked().Addr())
 
 	var subnets []string
-	addr := prefix.Addr()
-	step := big.NewInt(1)
-	step.Lsh(step, uint(addrLen-newSize))
-
-	currIP := IPToBigInt(addr)
-	isIPv4 := addr.Is4()
-
-	for range 1 << (newSize - origSize) {
-		nextPrefix := netip.PrefixFrom(addr, newSize)
-		subnets = append(subnets, nextPrefix.String())
-
+	for range 1 << min(newSize-prefix.Bits(), maxListedSplitBits) {
+		subnets = append(subnets, netip.PrefixFrom(BigIntToAddr(currIP, isIPv4), newSize).String())
 		currIP.Add(currIP, step)
+	}
+	if newSize-prefix.Bits() > maxListedSplitBits {
+		for _, p := range rangeToPrefixes(currIP, IPToBigInt(LastAddr(prefix)), isIPv4) {
+			subnets = append(subnets, p.String())
+		}
+	}
+	return subnets, nil
+}
 
-		nextAddr := BigIntToAddr(currIP, isIPv4)
-		if !nextAddr.IsValid() {
-			return nil, fmt.Errorf("failed to convert bigInt %d to IP", currIP)
+// SplitNetworkSizes tiles cidr with one subnet per entry of sizes (prefix
+// lengths). Subnets are laid out largest first from the start of the range,
+// which keeps every one of them aligned; any space left over is returned as
+// maximal aligned blocks. The result covers cidr exactly, in address order.
+func SplitNetworkSizes(cidr string, sizes []int) ([]string, error) {
+	prefix, err := netip.ParsePrefix(cidr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid CIDR: %w", err)
+	}
+	if len(sizes) == 0 {
+		return nil, errors.New("no subnet sizes given")
+	}
+	origSize := prefix.Bits()
+	addrLen := prefix.Addr().BitLen()
+	isIPv4 := prefix.Addr().Is4()
+
+	sorted := slices.Clone(sizes)
+	slices.Sort(sorted)
+	total := new(big.Int)
+	for _, size := range sorted {
+		if size <= origSize || size > addrLen {
+			return nil, fmt.Errorf("invalid size /%d: must be larger than /%d but not exceed /%d", size, origSize, addrLen)
 		}
-		addr = nextAddr
+		total.Add(total, new(big.Int).Lsh(big.NewInt(1), uint(addrLen-size)))
+	}
+	available := prefixAddressCount(prefix)
+	if total.Cmp(available) > 0 {
+		return nil, fmt.Errorf("requested subnets need %s addresses but %s has only %s", total, cidr, available)
 	}
 
+	var subnets []string
+	next := IPToBigInt(prefix.Masked().Addr())
+	for _, size := range sorted {
+		subnets = append(subnets, netip.PrefixFrom(BigIntToAddr(next, isIPv4), size).String())
+		next.Add(next, new(big.Int).Lsh(big.NewInt(1), uint(addrLen-size)))
+	}
+	if total.Cmp(available) < 0 {
+		for _, leftover := range rangeToPrefixes(next, IPToBigInt(LastAddr(prefix)), isIPv4) {
+			subnets = append(subnets, leftover.String())
+		}
+	}
 	return subnets, nil
 }
 
+// ParseSplitSizes parses a list of subnet sizes for splitting cidr, separated
+// by commas or spaces. Entries are prefix lengths ("26" or "/26") or, with an
+// h suffix, usable host counts ("100h") that map to the smallest subnet that fits.
+func ParseSplitSizes(cidr, text string) ([]int, error) {
+	prefix, err := netip.ParsePrefix(cidr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid CIDR: %w", err)
+	}
+	var sizes []int
+	for _, field := range strings.FieldsFunc(text, func(r rune) bool { return r == ',' || unicode.IsSpace(r) }) {
+		if hostsText, ok := strings.CutSuffix(strings.ToLower(field), "h"); ok {
+			hosts, err := strconv.Atoi(hostsText)
+			if err != nil || hosts < 1 {
+				return nil, fmt.Errorf("invalid host count %q", field)
+			}
+			size := prefixForHostCount(prefix, hosts)
+			if size <= prefix.Bits() {
+				return nil, fmt.Errorf("%d hosts do not fit in a subnet of %s", hosts, cidr)
+			}
+			sizes = append(sizes, size)
+			continue
+		}
+		size, err := strconv.Atoi(strings.TrimPrefix(field, "/"))
+		if err != nil {
+			return nil, fmt.Errorf("invalid prefix length %q", field)
+		}
+		sizes = append(sizes, size)
+	}
+	return sizes, nil
+}
+
 // SummarizeCIDRs merges a list of contiguous CIDRs into a single summary CIDR.
 func SummarizeCIDRs(cidrs []string) (string, error) {
 	if len(cidrs) == 0 {
 		return "", errors.New("no CIDRs provided")
 	}
 
-	var startIPs []*big.Int
-	var endIPs []*big.Int
-	var minIP, maxIP *big.Int
-	var isIPv4 bool
-
+	var prefixes []netip.Prefix
 	for i, cidrStr := range cidrs {
 		prefix, err := netip.ParsePrefix(cidrStr)
 		if err != nil {
 			return "", fmt.Errorf("invalid CIDR %s: %w", cidrStr, err)
 		}
-
-		if i == 0 {
-			isIPv4 = prefix.Addr().Is4()
-		} else if isIPv4 != prefix.Addr().Is4() {
+		if i > 0 && prefixes[0].Addr().Is4() != prefix.Addr().Is4() {
 			return "", errors.New("mixed IPv4 and IPv6 addresses are not supported")
 		}
-
-		startIP := IPToBigInt(prefix.Masked().Addr())
-		endIP := IPToBigInt(LastAddr(prefix))
-
-		startIPs = append(startIPs, startIP)
-		endIPs = append(endIPs, endIP)
-
-		if i == 0 || startIP.Cmp(minIP) < 0 {
-			minIP = startIP
-		}
-		if i == 0 || endIP.Cmp(maxIP) > 0 {
-			maxIP = endIP
-		}
-	}
-
-	indices := make([]int, len(startIPs))
-	for i := range indices {
-		indices[i] = i
+		prefixes = append(prefixes, prefix)
 	}
-	sort.Slice(indices, func(i, j int) bool {
-		return startIPs[indices[i]].Cmp(startIPs[indices[j]]) < 0
-	})
 
-	sortedStartIPs := make([]*big.Int, len(startIPs))
-	sortedEndIPs := make([]*big.Int, len(endIPs))
-	for i, idx := range indices {
-		sortedStartIPs[i] = startIPs[idx]
-		sortedEndIPs[i] = endIPs[idx]
+	ranges := mergeRanges(prefixes)
+	if len(ranges) != 1 {
+		return "", errors.New("CIDRs are not contiguous; gaps detected")
 	}
+	minIP, maxIP, isIPv4 := ranges[0].start, ranges[0].end, ranges[0].isIPv4
 
-	currentIP := new(big.Int).Set(minIP)
 	one := big.NewInt(1)
-
-	for i := range sortedStartIPs {
-		if sortedStartIPs[i].Cmp(currentIP) > 0 {
-			return "", errors.New("CIDRs are not contiguous; gaps detected")
-		}
-		if sortedStartIPs[i].Cmp(currentIP) <= 0 && sortedEndIPs[i].Cmp(currentIP) >= 0 {
-			currentIP = new(big.Int).Add(sortedEndIPs[i], one)
-		}
-	}
-	if new(big.Int).Sub(currentIP, one).Cmp(maxIP) != 0 {
-		return "", errors.New("CIDRs do not cover a contiguous range")
-	}
-
 	totalAddresses := new(big.Int).Add(new(big.Int).Sub(maxIP, minIP), one)
 	if !isPowerOfTwo(totalAddresses) {
 		return "", errors.New("total number of addresses is not a power of two; cannot summarize into a single CIDR without including extra addresses")
@@ -146,6 +171,47 @@ func SummarizeCIDRs(cidrs []string) (string, error) {
 	return summarizedPrefix.String(), nil
 }
 
+// addressRange is an inclusive range of addresses of a single family.
+type addressRange struct {
+	start, end *big.Int
+	isIPv4     bool
+}
+
+// mergeRanges sorts prefixes by address and merges overlapping or adjacent
+// ones into ranges. A range never spans IPv4 and IPv6.
+func mergeRanges(prefixes []netip.Prefix) []addressRange {
+	sorted := slices.Clone(prefixes)
+	slices.SortFunc(sorted, func(l, r netip.Prefix) int {
+		return l.Masked().Addr().Compare(r.Masked().Addr())
+	})
+
+	var ranges []addressRange
+	one := big.NewInt(1)
+	for _, prefix := range sorted {
+		start := IPToBigInt(prefix.Masked().Addr())
+		end := IPToBigInt(LastAddr(prefix))
+		isIPv4 := prefix.Addr().Is4()
+		if n := len(ranges); n > 0 && ranges[n-1].isIPv4 == isIPv4 && start.Cmp(new(big.Int).Add(ranges[n-1].end, one)) <= 0 {
+			if end.Cmp(ranges[n-1].end) > 0 {
+				ranges[n-1].end = end
+			}
+			continue
+		}
+		ranges = append(ranges, addressRange{start: start, end: end, isIPv4: isIPv4})
+	}
+	return ranges
+}
+
+// mergePrefixes merges contiguous prefixes and returns each merged range as
+// the minimal list of maximal aligned CIDRs.
+func mergePrefixes(prefixes []netip.Prefix) []netip.Prefix {
+	var result []netip.Prefix
+	for _, r := range mergeRanges(prefixes) {
+		result = append(result, rangeToPrefixes(r.start, r.end, r.isIPv4)...)
+	}
+	return result
+}
+
 // FindSummarizableRange finds the largest set of contiguous CIDRs containing
 // the element at index that can be summarized into a single CIDR.
 func FindSummarizableRange(cidrs []string, index int) (bool, []string, string) {
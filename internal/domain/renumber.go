@@ -0,0 +1,140 @@
+package domain
+
+import (
+	"cmp"
+	"fmt"
+	"net/netip"
+	"slices"
+	"strings"
+)
+
+// AddressChange is one rewrite a renumber makes, listed for preview.
+type AddressChange struct {
+	// Path is the catalog path of the changed item before the renumber.
+	Path string
+	Old  string
+	New  string
+}
+
+// PlanRenumber lists the rewrites renumbering n to newCIDR would make: n
+// itself, every child network and IP reservation, DNS aliases to those
+// reservations and A/AAAA records pointing into n. Nothing is changed.
+func (c *Catalog) PlanRenumber(n *Network, newCIDR string) ([]AddressChange, error) {
+	from, to, err := c.renumberDestination(n, newCIDR)
+	if err != nil {
+		return nil, err
+	}
+
+	var changes []AddressChange
+	var visit func(item Item)
+	visit = func(item Item) {
+		changes = append(changes, AddressChange{
+			Path: item.GetPath(),
+			Old:  item.RawID(),
+			New:  rebaseID(item.RawID(), from, to),
+		})
+		for _, child := range c.GetChildren(item) {
+			visit(child)
+		}
+	}
+	visit(n)
+
+	for _, record := range c.dnsRecordsInto(n, from) {
+		old := strings.TrimSpace(record.RecordValue)
+		for _, path := range record.AliasTargetPaths() {
+			if isSameOrDescendant(path, n.GetPath()) {
+				old = path[strings.LastIndex(path, " -> ")+len(" -> "):]
+				break
+			}
+		}
+		changes = append(changes, AddressChange{
+			Path: record.GetPath(),
+			Old:  old,
+			New:  rebaseID(old, from, to),
+		})
+	}
+	return changes, nil
+}
+
+// Renumber moves n to newCIDR, a prefix of the same size, keeping the offsets
+// of child networks and IP reservations. n is placed under the deepest Subnet
+// Container holding newCIDR, or at the top level. DNS aliases follow their
+// reservations and A/AAAA records pointing into n are rewritten.
+func (n *Network) Renumber(c *Catalog, newCIDR string) error {
+	from, to, err := c.renumberDestination(n, newCIDR)
+	if err != nil {
+		return err
+	}
+	records := c.dnsRecordsInto(n, from)
+	if err := n.Move(c, c.renumberParent(n, to), to.String()); err != nil {
+		return err
+	}
+	for _, record := range records {
+		if !record.IsAlias() {
+			record.RecordValue = rebaseID(strings.TrimSpace(record.RecordValue), from, to)
+		}
+	}
+	return nil
+}
+
+func (c *Catalog) renumberDestination(n *Network, newCIDR string) (from, to netip.Prefix, err error) {
+	newCIDR = strings.TrimSpace(newCIDR)
+	to, err = netip.ParsePrefix(newCIDR)
+	if err != nil {
+		return from, to, fmt.Errorf("invalid CIDR %s: %w", newCIDR, err)
+	}
+	return c.moveDestination(n, c.renumberParent(n, to.Masked()), newCIDR)
+}
+
+// renumberParent returns the deepest Subnet Container outside n's subtree
+// that contains to, or the Networks folder when there is none.
+func (c *Catalog) renumberParent(n *Network, to netip.Prefix) Item {
+	var best *Network
+	bestBits := -1
+	for _, item := range c.All() {
+		container, ok := item.(*Network)
+		if !ok || container.AllocationMode != AllocationModeSubnets || isSameOrDescendant(container.GetPath(), n.GetPath()) {
+			continue
+		}
+		prefix, err := netip.ParsePrefix(container.ID)
+		if err != nil || prefix.Addr().Is4() != to.Addr().Is4() {
+			continue
+		}
+		if prefix.Bits() < to.Bits() && prefix.Contains(to.Addr()) && prefix.Bits() > bestBits {
+			best, bestBits = container, prefix.Bits()
+		}
+	}
+	if best == nil {
+		return c.GetByParentAndDisplayID(nil, FolderNetworks)
+	}
+	return best
+}
+
+// dnsRecordsInto returns DNS records affected by renumbering n: aliases to
+// reservations below n and A/AAAA records whose value lies in from. Records
+// are ordered by path.
+func (c *Catalog) dnsRecordsInto(n *Network, from netip.Prefix) []*DNSRecord {
+	var records []*DNSRecord
+	for _, item := range c.All() {
+		record, ok := item.(*DNSRecord)
+		if !ok {
+			continue
+		}
+		if record.IsAlias() {
+			if slices.ContainsFunc(record.AliasTargetPaths(), func(path string) bool { return isSameOrDescendant(path, n.GetPath()) }) {
+				records = append(records, record)
+			}
+			continue
+		}
+		if recordType := strings.ToUpper(strings.TrimSpace(record.RecordType)); recordType != "A" && recordType != "AAAA" {
+			continue
+		}
+		if addr, err := netip.ParseAddr(strings.TrimSpace(record.RecordValue)); err == nil && from.Contains(addr) {
+			records = append(records, record)
+		}
+	}
+	slices.SortFunc(records, func(l, r *DNSRecord) int {
+		return cmp.Compare(l.GetPath(), r.GetPath())
+	})
+	return records
+}
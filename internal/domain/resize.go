@@ -0,0 +1,176 @@
+package domain
+
+import (
+	"fmt"
+	"math/big"
+	"net/netip"
+)
+
+// Grow enlarges an allocated network in place to the given prefix length,
+// keeping its start address aligned down. Unallocated siblings inside the
+// larger prefix are absorbed; allocated ones make the operation fail.
+// Children and IP reservations keep their place under the renamed network.
+func (n *Network) Grow(c *Catalog, newBits int) error {
+	prefix, err := n.resizablePrefix()
+	if err != nil {
+		return err
+	}
+	if newBits >= prefix.Bits() || newBits < 0 {
+		return fmt.Errorf("new prefix length must be shorter than /%d", prefix.Bits())
+	}
+	grown := netip.PrefixFrom(prefix.Addr(), newBits).Masked()
+
+	parent := c.Get(n.GetParentPath())
+	if parent == nil {
+		return fmt.Errorf("parent not found for %s", n.GetPath())
+	}
+	if p, ok := parent.(*Network); ok {
+		parentPrefix, err := netip.ParsePrefix(p.ID)
+		if err != nil {
+			return fmt.Errorf("invalid CIDR %s: %w", p.ID, err)
+		}
+		if grown.Bits() <= parentPrefix.Bits() || !parentPrefix.Contains(grown.Addr()) {
+			return fmt.Errorf("%s does not fit inside parent %s", grown, p.ID)
+		}
+	}
+
+	var absorbed []*Network
+	for _, sibling := range c.GetChildren(parent) {
+		other, ok := sibling.(*Network)
+		if !ok || other == n {
+			continue
+		}
+		otherPrefix, err := netip.ParsePrefix(other.ID)
+		if err != nil {
+			return fmt.Errorf("invalid CIDR %s: %w", other.ID, err)
+		}
+		if !otherPrefix.Overlaps(grown) {
+			continue
+		}
+		if other.AllocationMode != AllocationModeUnallocated || otherPrefix.Bits() < grown.Bits() {
+			return fmt.Errorf("cannot grow to %s: it overlaps %s", grown, other.GetPath())
+		}
+		absorbed = append(absorbed, other)
+	}
+
+	for _, other := range absorbed {
+		c.Delete(other)
+	}
+	c.relocate(n, n.ParentPath, renameOnly(n, grown.String()))
+	return nil
+}
+
+// Shrink reduces an allocated network in place to the given prefix length,
+// keeping its start address. In a Subnet Container the released trailing
+// space is left as implicit free space; under the Networks folder it is stored
+// as unallocated siblings. It fails if an IP reservation or allocated child
+// network would fall outside, or a reservation would become the network or
+// broadcast address; unallocated children outside are dropped.
+func (n *Network) Shrink(c *Catalog, newBits int) error {
+	prefix, err := n.resizablePrefix()
+	if err != nil {
+		return err
+	}
+	if newBits <= prefix.Bits() || newBits >= prefix.Addr().BitLen() {
+		return fmt.Errorf("new prefix length must be between /%d and /%d", prefix.Bits()+1, prefix.Addr().BitLen()-1)
+	}
+	shrunk := netip.PrefixFrom(prefix.Addr(), newBits)
+
+	if gateway, err := netip.ParseAddr(n.Gateway); err == nil && !isUsableHost(shrunk, gateway) {
+		return fmt.Errorf("cannot shrink to %s: gateway %s would fall outside", shrunk, n.Gateway)
+	}
+	for _, text := range n.ExcludedRanges {
+		if _, err := ParseAddressRange(shrunk, text); err != nil {
+			return fmt.Errorf("cannot shrink to %s: excluded range %s would fall outside", shrunk, text)
+		}
+	}
+	if n.DHCPOptions != nil && n.DHCPOptions.Router != "" {
+		if router, err := netip.ParseAddr(n.DHCPOptions.Router); err != nil || !isUsableHost(shrunk, router) {
+			return fmt.Errorf("cannot shrink to %s: DHCP router %s would fall outside", shrunk, n.DHCPOptions.Router)
+		}
+	}
+	for _, text := range n.DHCPRanges {
+		if r, err := ParseAddressRange(shrunk, text); err != nil || !isUsableHost(shrunk, r.End) {
+			return fmt.Errorf("cannot shrink to %s: DHCP range %s would fall outside", shrunk, text)
+		}
+	}
+
+	var dropped []Item
+	for _, child := range c.GetChildren(n) {
+		switch v := child.(type) {
+		case *IP:
+			addr, err := netip.ParseAddr(v.ID)
+			if err != nil {
+				return fmt.Errorf("invalid IP address %s: %w", v.ID, err)
+			}
+			if !shrunk.Contains(addr) {
+				return fmt.Errorf("cannot shrink to %s: reserved IP %s would fall outside", shrunk, v.ID)
+			}
+			if !isUsableHost(shrunk, addr) {
+				return fmt.Errorf("cannot shrink to %s: reserved IP %s would become its network or broadcast address", shrunk, v.ID)
+			}
+		case *Network:
+			childPrefix, err := netip.ParsePrefix(v.ID)
+			if err != nil {
+				return fmt.Errorf("invalid CIDR %s: %w", v.ID, err)
+			}
+			if shrunk.Contains(childPrefix.Addr()) && childPrefix.Bits() > shrunk.Bits() {
+				continue
+			}
+			if v.AllocationMode != AllocationModeUnallocated {
+				return fmt.Errorf("cannot shrink to %s: child network %s would fall outside", shrunk, v.ID)
+			}
+			dropped = append(dropped, v)
+		}
+	}
+
+	parent := c.Get(n.GetParentPath())
+	if parent == nil {
+		return fmt.Errorf("parent not found for %s", n.GetPath())
+	}
+	var split *Network
+	for _, child := range dropped {
+		// A split network covering the shrunk prefix keeps listing its
+		// subnets there.
+		if v := child.(*Network); v.SplitBits > shrunk.Bits() && netip.MustParsePrefix(v.ID).Contains(shrunk.Addr()) {
+			split = &Network{SplitBits: v.SplitBits}
+		}
+		c.Delete(child)
+	}
+	c.relocate(n, n.ParentPath, renameOnly(n, shrunk.String()))
+	if split != nil {
+		split.ID, split.ParentPath = shrunk.String(), n.GetPath()
+		c.Put(split)
+	}
+
+	// The Networks folder has no implicit free space to return the rest to.
+	if _, ok := parent.(*StaticFolder); ok {
+		from := new(big.Int).Add(IPToBigInt(LastAddr(shrunk)), big.NewInt(1))
+		for _, released := range rangeToPrefixes(from, IPToBigInt(LastAddr(prefix)), prefix.Addr().Is4()) {
+			c.Put(&Network{Base: Base{ID: released.String(), ParentPath: parent.GetPath()}})
+		}
+	}
+	return nil
+}
+
+func (n *Network) resizablePrefix() (netip.Prefix, error) {
+	if n.AllocationMode == AllocationModeUnallocated {
+		return netip.Prefix{}, fmt.Errorf("only allocated networks can be resized; split or summarize %s instead", n.ID)
+	}
+	prefix, err := netip.ParsePrefix(n.ID)
+	if err != nil {
+		return netip.Prefix{}, fmt.Errorf("invalid CIDR %s: %w", n.ID, err)
+	}
+	return prefix, nil
+}
+
+// renameOnly returns a relocate rename func that gives n the new ID and keeps
+// every other ID in its subtree.
+func renameOnly(n *Network, id string) func(Item) string {
+	return func(item Item) string {
+		if item == Item(n) {
+			return id
+		}
+		return item.RawID()
+	}
+}
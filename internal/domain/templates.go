@@ -0,0 +1,191 @@
+package domain
+
+import (
+	"fmt"
+	"math/big"
+	"net/netip"
+	"slices"
+	"strings"
+)
+
+// Allocation modes as written in layout templates.
+const (
+	TemplateModeHosts   = "hosts"
+	TemplateModeSubnets = "subnets"
+)
+
+// TemplateGatewayName is the name given to gateway reservations created from templates.
+const TemplateGatewayName = "gateway"
+
+// LayoutTemplate is a named layout of child networks that can be applied to a
+// Subnet Container when it is allocated. Templates are read from the
+// templates directory next to the catalog data and are never edited by the app.
+type LayoutTemplate struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	// PrefixLen is the size of the Subnet Container the template is made for.
+	PrefixLen int              `json:"prefix_len"`
+	Subnets   []TemplateSubnet `json:"subnets"`
+}
+
+// TemplateSubnet is one child network of a LayoutTemplate.
+type TemplateSubnet struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	PrefixLen   int    `json:"prefix_len"`
+	// Offset is the position of the subnet in the container, counted in
+	// blocks of the subnet's own size: offset 2 of a /26 starts 128 addresses in.
+	Offset int64 `json:"offset"`
+	// Mode is TemplateModeHosts (the default) or TemplateModeSubnets.
+	Mode   string `json:"mode,omitempty"`
+	VLANID int    `json:"vlan_id,omitempty"`
+	// GatewayOffset reserves the host at this offset in a host pool as the
+	// gateway. Zero means no gateway is reserved.
+	GatewayOffset int64 `json:"gateway_offset,omitempty"`
+}
+
+// Validate checks the template on its own; whether it fits a particular
+// container is checked when it is applied.
+func (t *LayoutTemplate) Validate() error {
+	if strings.TrimSpace(t.Name) == "" {
+		return fmt.Errorf("template name must be set")
+	}
+	if t.PrefixLen < 1 || t.PrefixLen > 127 {
+		return fmt.Errorf("template %s: prefix_len must be between 1 and 127, got %d", t.Name, t.PrefixLen)
+	}
+	if len(t.Subnets) == 0 {
+		return fmt.Errorf("template %s: at least one subnet is required", t.Name)
+	}
+
+	// Place subnets in a 128-bit space relative to the container start so
+	// overlaps can be checked without knowing the address family.
+	type span struct {
+		name       string
+		start, end *big.Int
+	}
+	spans := make([]span, 0, len(t.Subnets))
+	for _, s := range t.Subnets {
+		if strings.TrimSpace(s.Name) == "" {
+			return fmt.Errorf("template %s: subnet name must be set", t.Name)
+		}
+		if s.PrefixLen <= t.PrefixLen || s.PrefixLen > 128 {
+			return fmt.Errorf("template %s: subnet %s: prefix_len must be between %d and 128, got %d", t.Name, s.Name, t.PrefixLen+1, s.PrefixLen)
+		}
+		blocks := new(big.Int).Lsh(big.NewInt(1), uint(s.PrefixLen-t.PrefixLen))
+		if s.Offset < 0 || big.NewInt(s.Offset).Cmp(blocks) >= 0 {
+			return fmt.Errorf("template %s: subnet %s: offset must be between 0 and %s", t.Name, s.Name, new(big.Int).Sub(blocks, big.NewInt(1)))
+		}
+		if s.Mode != "" && s.Mode != TemplateModeHosts && s.Mode != TemplateModeSubnets {
+			return fmt.Errorf("template %s: subnet %s: mode must be %q or %q", t.Name, s.Name, TemplateModeHosts, TemplateModeSubnets)
+		}
+		if s.VLANID < 0 || s.VLANID > 4094 {
+			return fmt.Errorf("template %s: subnet %s: vlan_id must be between 1 and 4094", t.Name, s.Name)
+		}
+		size := new(big.Int).Lsh(big.NewInt(1), uint(128-s.PrefixLen))
+		if s.GatewayOffset != 0 {
+			if s.Mode == TemplateModeSubnets {
+				return fmt.Errorf("template %s: subnet %s: gateway_offset is only allowed for host pools", t.Name, s.Name)
+			}
+			if s.GatewayOffset < 0 {
+				return fmt.Errorf("template %s: subnet %s: gateway_offset must not be negative", t.Name, s.Name)
+			}
+		}
+		start := new(big.Int).Mul(big.NewInt(s.Offset), size)
+		spans = append(spans, span{name: s.Name, start: start, end: new(big.Int).Add(start, size)})
+	}
+	slices.SortFunc(spans, func(l, r span) int { return l.start.Cmp(r.start) })
+	for i := 1; i < len(spans); i++ {
+		if spans[i].start.Cmp(spans[i-1].end) < 0 {
+			return fmt.Errorf("template %s: subnets %s and %s overlap", t.Name, spans[i-1].name, spans[i].name)
+		}
+	}
+	return nil
+}
+
+// Template returns the layout template with the given name, or nil.
+func (c *Catalog) Template(name string) *LayoutTemplate {
+	for _, t := range c.Templates {
+		if t.Name == name {
+			return t
+		}
+	}
+	return nil
+}
+
+// TemplatesFor returns the templates made for containers of the given prefix length.
+func (c *Catalog) TemplatesFor(prefixLen int) []*LayoutTemplate {
+	var templates []*LayoutTemplate
+	for _, t := range c.Templates {
+		if t.PrefixLen == prefixLen {
+			templates = append(templates, t)
+		}
+	}
+	return templates
+}
+
+// ApplyTemplate allocates the subnets of t inside container, an allocated
+// Subnet Container of the template's size, and reserves their gateways.
+// Everything is checked before the catalog is changed.
+func (c *Catalog) ApplyTemplate(container *Network, t *LayoutTemplate) error {
+	if container.AllocationMode != AllocationModeSubnets {
+		return fmt.Errorf("%s is not a Subnet Container", container.GetPath())
+	}
+	if err := t.Validate(); err != nil {
+		return err
+	}
+	prefix, err := netip.ParsePrefix(container.ID)
+	if err != nil {
+		return fmt.Errorf("invalid CIDR %s: %w", container.ID, err)
+	}
+	if prefix.Bits() != t.PrefixLen {
+		return fmt.Errorf("template %s is made for a /%d, not a /%d", t.Name, t.PrefixLen, prefix.Bits())
+	}
+	maxBits := prefix.Addr().BitLen()
+
+	cidrs := make([]netip.Prefix, 0, len(t.Subnets))
+	for _, s := range t.Subnets {
+		if s.PrefixLen >= maxBits {
+			return fmt.Errorf("template %s: subnet %s: /%d does not fit a network of %s", t.Name, s.Name, s.PrefixLen, container.ID)
+		}
+		if s.VLANID > 0 && c.FindVLANByID(s.VLANID) == nil {
+			return fmt.Errorf("template %s: subnet %s: VLAN ID %d not found", t.Name, s.Name, s.VLANID)
+		}
+		size := new(big.Int).Lsh(big.NewInt(1), uint(maxBits-s.PrefixLen))
+		start := new(big.Int).Add(IPToBigInt(prefix.Masked().Addr()), new(big.Int).Mul(big.NewInt(s.Offset), size))
+		cidr := netip.PrefixFrom(BigIntToAddr(start, prefix.Addr().Is4()), s.PrefixLen)
+		if s.GatewayOffset > 0 {
+			gateway := BigIntToAddr(new(big.Int).Add(start, big.NewInt(s.GatewayOffset)), prefix.Addr().Is4())
+			if big.NewInt(s.GatewayOffset).Cmp(size) >= 0 || !isUsableHost(cidr, gateway) {
+				return fmt.Errorf("template %s: subnet %s: gateway_offset %d is not a usable host of %s", t.Name, s.Name, s.GatewayOffset, cidr)
+			}
+		}
+		if other := c.overlappingAllocatedChild(container, cidr); other != nil {
+			return fmt.Errorf("template %s: subnet %s: %s overlaps allocated network %s", t.Name, s.Name, cidr, other.GetPath())
+		}
+		cidrs = append(cidrs, cidr)
+	}
+
+	for i, s := range t.Subnets {
+		n, err := c.CarveFreeBlock(container, cidrs[i].String())
+		if err != nil {
+			return err
+		}
+		n.AllocationMode = AllocationModeHosts
+		if s.Mode == TemplateModeSubnets {
+			n.AllocationMode = AllocationModeSubnets
+		}
+		n.DisplayName = s.Name
+		n.Description = s.Description
+		n.VLANID = s.VLANID
+		c.Put(n)
+		if s.GatewayOffset > 0 {
+			addr := new(big.Int).Add(IPToBigInt(cidrs[i].Addr()), big.NewInt(s.GatewayOffset))
+			n.Gateway = BigIntToAddr(addr, prefix.Addr().Is4()).String()
+			c.Put(&IP{
+				Base:        Base{ID: n.Gateway, ParentPath: n.GetPath()},
+				DisplayName: TemplateGatewayName,
+			})
+		}
+	}
+	return nil
+}
@@ -55,6 +55,7 @@ type Base struct {
 }
 
 func (b *Base) RawID() string         { return b.ID }
+func (b *Base) base() *Base           { return b }
 func (b *Base) GetParentPath() string { return b.ParentPath }
 func (b *Base) GetPath() string {
 	if b.ParentPath == "" {
@@ -94,6 +95,39 @@ type Network struct {
 	DisplayName    string         `json:"display_name"`
 	Description    string         `json:"description"`
 	VLANID         int            `json:"vlan_id,omitempty"`
+	// SplitBits is the prefix length an unallocated network was split at. The
+	// network stays stored as one entry and is listed as its subnets of that
+	// length; see Network.Split.
+	SplitBits int `json:"split_bits,omitempty"`
+	// Gateway is the designated gateway address of a Host Pool.
+	Gateway string `json:"gateway,omitempty"`
+	// ExcludedRanges are address ranges of a Host Pool that must not be
+	// reserved, e.g. "10.0.0.250-10.0.0.254". See ParseAddressRange.
+	ExcludedRanges []string `json:"excluded_ranges,omitempty"`
+	// DHCPRanges are the dynamic ranges a DHCP server hands out from a Host
+	// Pool; the rest of the pool is left for static reservations.
+	DHCPRanges []string `json:"dhcp_ranges,omitempty"`
+	// DHCPOptions are the options a DHCP server hands out for a Host Pool.
+	DHCPOptions *DHCPOptions `json:"dhcp_options,omitempty"`
+}
+
+// DHCPOptions are the DHCP options of a Host Pool.
+type DHCPOptions struct {
+	// Router is the default gateway handed out; empty means the pool's Gateway.
+	Router        string   `json:"router,omitempty"`
+	DNSServers    []string `json:"dns_servers,omitempty"`
+	DomainName    string   `json:"domain_name,omitempty"`
+	SearchDomains []string `json:"search_domains,omitempty"`
+	NTPServers    []string `json:"ntp_servers,omitempty"`
+	// LeaseTime is a Go duration such as "12h" or "30m".
+	LeaseTime string             `json:"lease_time,omitempty"`
+	Custom    []DHCPCustomOption `json:"custom,omitempty"`
+}
+
+// DHCPCustomOption is a DHCP option by code, such as 66 (TFTP server name).
+type DHCPCustomOption struct {
+	Code  int    `json:"code"`
+	Value string `json:"value"`
 }
 
 func (n *Network) DisplayID() string {
@@ -331,22 +365,34 @@ func (p *Port) Compare(other Item) int {
 
 // ---------- DNSRecord ----------
 
-// DNSRecord represents a DNS record or an alias to a reserved IP.
+// DNSRecord represents a DNS record or an alias to reserved IPs. An alias
+// either lists reservations or names a logical host, following every
+// reservation with that hostname.
 type DNSRecord struct {
 	Base
 	RecordType     string `json:"record_type,omitempty"`
 	RecordValue    string `json:"record_value,omitempty"`
 	ReservedIPPath string `json:"reserved_ip,omitempty"`
-	Description    string `json:"description"`
+	// ReservedIPPaths are further reservations the alias points to, such as
+	// the IPv6 address of a dual-stack host.
+	ReservedIPPaths []string `json:"reserved_ips,omitempty"`
+	ReservedHost    string   `json:"reserved_host,omitempty"`
+	// TTL overrides the default TTL of the record's zone; 0 keeps it.
+	TTL         int    `json:"ttl,omitempty"`
+	Description string `json:"description"`
 }
 
 func (d *DNSRecord) DisplayID() string {
-	if strings.TrimSpace(d.ReservedIPPath) != "" {
-		target := d.ReservedIPPath
-		if parts := strings.Split(target, " -> "); len(parts) > 0 {
-			target = parts[len(parts)-1]
+	if host := strings.TrimSpace(d.ReservedHost); host != "" {
+		return fmt.Sprintf("%s (host -> %s)", d.ID, host)
+	}
+	if paths := d.AliasTargetPaths(); len(paths) > 0 {
+		targets := make([]string, 0, len(paths))
+		for _, path := range paths {
+			parts := strings.Split(path, " -> ")
+			targets = append(targets, parts[len(parts)-1])
 		}
-		return fmt.Sprintf("%s (alias -> %s)", d.ID, target)
+		return fmt.Sprintf("%s (alias -> %s)", d.ID, strings.Join(targets, ", "))
 	}
 	if strings.TrimSpace(d.RecordType) != "" || strings.TrimSpace(d.RecordValue) != "" {
 		return fmt.Sprintf("%s (%s -> %s)", d.ID, d.RecordType, d.RecordValue)
@@ -358,9 +404,42 @@ func (d *DNSRecord) Compare(other Item) int {
 	if other == nil {
 		return 1
 	}
+	if _, ok := other.(*DNSZone); ok {
+		return 1
+	}
 	otherDNS, ok := other.(*DNSRecord)
 	if !ok {
 		return cmp.Compare(d.DisplayID(), other.DisplayID())
 	}
 	return CompareNaturalNumberOrder(d.ID, otherDNS.ID)
 }
+
+// ---------- DNSZone ----------
+
+// DNSZone is a DNS zone under the DNS folder, holding the records nested
+// beneath it. Its settings override the defaults of config.yaml for the zone.
+type DNSZone struct {
+	Base
+	// TTL is the default TTL of the zone's records; 0 uses the configured one.
+	TTL int `json:"ttl,omitempty"`
+	// Contact is the SOA contact mailbox, such as hostmaster@example.com.
+	Contact     string   `json:"contact,omitempty"`
+	NameServers []string `json:"name_servers,omitempty"`
+	Description string   `json:"description"`
+}
+
+func (z *DNSZone) DisplayID() string { return z.ID + " (zone)" }
+
+func (z *DNSZone) Compare(other Item) int {
+	if other == nil {
+		return 1
+	}
+	otherZone, ok := other.(*DNSZone)
+	if !ok {
+		if _, isRecord := other.(*DNSRecord); isRecord {
+			return -1
+		}
+		return cmp.Compare(z.DisplayID(), other.DisplayID())
+	}
+	return CompareNaturalNumberOrder(z.ID, otherZone.ID)
+}
@@ -0,0 +1,217 @@
+package domain
+
+import (
+	"fmt"
+	"math/big"
+	"net/netip"
+	"slices"
+)
+
+// Utilization holds address usage metrics for a network, rolled up through its descendants.
+type Utilization struct {
+	// Total is the number of addresses in the network.
+	Total *big.Int
+	// Allocated is the number of addresses held by allocated child networks (Subnet Containers only).
+	Allocated *big.Int
+	// Usable is the number of usable host addresses across all Host Pools in the subtree.
+	Usable *big.Int
+	// Reserved is the number of reserved IPs across all Host Pools in the subtree.
+	Reserved int
+	// LargestFree is the largest unallocated block in the subtree, or "" if there is none.
+	LargestFree string
+
+	largestFreeSize *big.Int
+}
+
+// Unallocated returns the number of addresses not held by allocated child networks.
+func (u Utilization) Unallocated() *big.Int {
+	return new(big.Int).Sub(u.Total, u.Allocated)
+}
+
+// AllocatedPercent returns the allocated share of the address space.
+func (u Utilization) AllocatedPercent() float64 {
+	return percentOf(u.Allocated, u.Total)
+}
+
+// ReservedPercent returns the reserved share of usable host addresses.
+func (u Utilization) ReservedPercent() float64 {
+	return percentOf(big.NewInt(int64(u.Reserved)), u.Usable)
+}
+
+// Utilization computes usage metrics for n and everything below it.
+func (c *Catalog) Utilization(n *Network) (Utilization, error) {
+	return c.NewUtilizationIndex().Utilization(n)
+}
+
+// UtilizationIndex computes Utilization for many networks of one catalog
+// state, such as every row of a rendered tree: children are indexed by parent
+// once and each result is kept by network path. Build a new index after the
+// catalog changes.
+type UtilizationIndex struct {
+	children map[string][]Item
+	results  map[string]Utilization
+}
+
+// NewUtilizationIndex indexes the children of every item in the catalog.
+func (c *Catalog) NewUtilizationIndex() *UtilizationIndex {
+	x := &UtilizationIndex{
+		children: map[string][]Item{},
+		results:  map[string]Utilization{},
+	}
+	for _, item := range c.items {
+		x.children[item.GetParentPath()] = append(x.children[item.GetParentPath()], item)
+	}
+	for _, children := range x.children {
+		slices.SortStableFunc(children, func(a, b Item) int {
+			return a.Compare(b)
+		})
+	}
+	return x
+}
+
+func (x *UtilizationIndex) childrenOf(item Item) []Item {
+	return x.children[item.GetPath()]
+}
+
+// Utilization computes usage metrics for n and everything below it, like
+// Catalog.Utilization.
+func (x *UtilizationIndex) Utilization(n *Network) (Utilization, error) {
+	if u, ok := x.results[n.GetPath()]; ok {
+		return u, nil
+	}
+	prefix, err := netip.ParsePrefix(n.ID)
+	if err != nil {
+		return Utilization{}, fmt.Errorf("invalid CIDR %s: %w", n.ID, err)
+	}
+	u := Utilization{
+		Total:     prefixAddressCount(prefix),
+		Allocated: new(big.Int),
+		Usable:    new(big.Int),
+	}
+
+	switch n.AllocationMode {
+	case AllocationModeUnallocated:
+		u.LargestFree = n.ID
+		u.largestFreeSize = u.Total
+	case AllocationModeHosts:
+		u.Usable = usableHostCount(prefix)
+		for _, child := range x.childrenOf(n) {
+			if _, ok := child.(*IP); ok {
+				u.Reserved++
+			}
+		}
+	case AllocationModeSubnets:
+		blocks, err := addressMap(n, x.childrenOf)
+		if err != nil {
+			return Utilization{}, err
+		}
+		for _, block := range blocks {
+			if block.Network == nil {
+				u.considerFree(block.CIDR, block.Size)
+				continue
+			}
+			child, err := x.Utilization(block.Network)
+			if err != nil {
+				return Utilization{}, err
+			}
+			if block.Network.AllocationMode != AllocationModeUnallocated {
+				u.Allocated.Add(u.Allocated, block.Size)
+			}
+			u.Usable.Add(u.Usable, child.Usable)
+			u.Reserved += child.Reserved
+			if child.LargestFree != "" {
+				u.considerFree(child.LargestFree, child.largestFreeSize)
+			}
+		}
+	}
+	x.results[n.GetPath()] = u
+	return u, nil
+}
+
+// IsNearlyExhausted reports whether a Host Pool has reached the configured warning threshold.
+func (c *Catalog) IsNearlyExhausted(n *Network, u Utilization) bool {
+	if n.AllocationMode != AllocationModeHosts || u.Usable.Sign() == 0 {
+		return false
+	}
+	return u.ReservedPercent() >= float64(c.Config.PoolWarnThreshold())
+}
+
+func (u *Utilization) considerFree(cidr string, size *big.Int) {
+	if u.largestFreeSize == nil || size.Cmp(u.largestFreeSize) > 0 {
+		u.LargestFree = cidr
+		u.largestFreeSize = size
+	}
+}
+
+// usableHostCount returns the number of assignable host addresses in prefix,
+// excluding the network and broadcast addresses of IPv4 networks larger than /31.
+func usableHostCount(prefix netip.Prefix) *big.Int {
+	count := prefixAddressCount(prefix)
+	if prefix.Addr().Is4() && prefix.Bits() < 31 {
+		count.Sub(count, big.NewInt(2))
+	}
+	return count
+}
+
+// excludedHostCount returns how many usable hosts of n lie in its excluded
+// ranges, counting overlapping ranges once. The gateway may sit in one and
+// stays usable.
+func (n *Network) excludedHostCount() *big.Int {
+	count := new(big.Int)
+	prefix, err := netip.ParsePrefix(n.ID)
+	if err != nil {
+		return count
+	}
+	var ranges []AddressRange
+	for _, text := range n.ExcludedRanges {
+		if r, err := ParseAddressRange(prefix, text); err == nil {
+			ranges = append(ranges, r)
+		}
+	}
+	slices.SortFunc(ranges, func(l, r AddressRange) int { return l.Start.Compare(r.Start) })
+	var merged []AddressRange
+	for _, r := range ranges {
+		if last := len(merged) - 1; last >= 0 && r.Start.Compare(merged[last].End.Next()) <= 0 {
+			if r.End.Compare(merged[last].End) > 0 {
+				merged[last].End = r.End
+			}
+			continue
+		}
+		merged = append(merged, r)
+	}
+
+	gateway, _ := netip.ParseAddr(n.Gateway)
+	for _, r := range merged {
+		size := new(big.Int).Sub(IPToBigInt(r.End), IPToBigInt(r.Start))
+		count.Add(count, size.Add(size, big.NewInt(1)))
+		for _, addr := range []netip.Addr{prefix.Masked().Addr(), LastAddr(prefix)} {
+			if r.Contains(addr) && !isUsableHost(prefix, addr) {
+				count.Sub(count, big.NewInt(1))
+			}
+		}
+		if r.Contains(gateway) {
+			count.Sub(count, big.NewInt(1))
+		}
+	}
+	return count
+}
+
+// isUsableHost reports whether addr is an assignable host address of prefix,
+// following the same rules as usableHostCount.
+func isUsableHost(prefix netip.Prefix, addr netip.Addr) bool {
+	if !prefix.Contains(addr) {
+		return false
+	}
+	if prefix.Addr().Is4() && prefix.Bits() < 31 {
+		return addr != prefix.Masked().Addr() && addr != LastAddr(prefix)
+	}
+	return true
+}
+
+func percentOf(part, whole *big.Int) float64 {
+	if whole.Sign() == 0 {
+		return 0
+	}
+	ratio, _ := new(big.Float).Quo(new(big.Float).SetInt(part), new(big.Float).SetInt(whole)).Float64()
+	return ratio * 100
+}
@@ -47,9 +47,15 @@ func (n *Network) Validate(c *Catalog) error {
 		if strings.TrimSpace(n.DisplayName) == "" {
 			return fmt.Errorf("display name must be set for allocated network %s", n.ID)
 		}
+		if n.SplitBits != 0 {
+			return fmt.Errorf("allocated network %s cannot be split", n.ID)
+		}
+	}
+	if n.SplitBits != 0 && (n.SplitBits <= prefix.Bits() || n.SplitBits >= maxBits) {
+		return fmt.Errorf("split prefix length /%d of network %s must be longer than /%d and shorter than /%d", n.SplitBits, n.ID, prefix.Bits(), maxBits)
 	}
 
-	return nil
+	return n.validateHostPoolSettings(c, prefix)
 }
 
 // Validate checks IP invariants.
@@ -71,14 +77,31 @@ func (i *IP) Validate(c *Catalog) error {
 			return fmt.Errorf("invalid MAC address %q for IP %s: %w", i.MACAddress, i.ID, err)
 		}
 	}
+	if c != nil {
+		if pool, ok := c.Get(i.ParentPath).(*Network); ok {
+			if r := pool.ExcludedRangeOf(netip.MustParseAddr(i.ID)); r != "" {
+				return fmt.Errorf("IP %s falls in excluded range %s of %s", i.ID, r, pool.ID)
+			}
+		}
+		if cname := c.cnameNamed(i.DisplayName); cname != nil {
+			return &RecordDataError{fmt.Errorf("hostname %s of IP %s is already a CNAME to %s", i.DisplayName, i.ID, cname.RecordValue)}
+		}
+	}
 	return nil
 }
 
 // Validate checks DNSRecord invariants.
 func (d *DNSRecord) Validate(c *Catalog) error {
-	if err := ValidateHostname(d.ID); err != nil {
+	if err := ValidateOwnerName(d.ID); err != nil {
 		return fmt.Errorf("invalid FQDN %q: %w", d.ID, err)
 	}
+	// Address records name hosts, so "_" labels are only for other types.
+	recordType := strings.ToUpper(strings.TrimSpace(d.RecordType))
+	if d.IsAlias() || recordType == "A" || recordType == "AAAA" {
+		if err := ValidateHostname(d.ID); err != nil {
+			return fmt.Errorf("invalid FQDN %q for an address record: %w", d.ID, err)
+		}
+	}
 	if d.ParentPath == "" {
 		return fmt.Errorf("parent path must be set for DNS record %s", d.ID)
 	}
@@ -87,34 +110,92 @@ func (d *DNSRecord) Validate(c *Catalog) error {
 		if parent == nil {
 			return fmt.Errorf("parent not found for DNS record %s", d.ID)
 		}
-		parentStatic, ok := parent.(*StaticFolder)
-		if !ok || parentStatic.ID != FolderDNS {
-			return fmt.Errorf("parent must be DNS for DNS record %s", d.ID)
+		if zone, ok := parent.(*DNSZone); ok {
+			if !zone.Contains(d.ID) {
+				return fmt.Errorf("DNS record %s is outside its zone %s", d.ID, zone.ID)
+			}
+		} else if parentStatic, ok := parent.(*StaticFolder); !ok || parentStatic.ID != FolderDNS {
+			return fmt.Errorf("parent must be DNS or a DNS zone for DNS record %s", d.ID)
+		}
+	}
+	if err := validateTTL(d.TTL); err != nil {
+		return fmt.Errorf("DNS record %s: %w", d.ID, err)
+	}
+	if c != nil {
+		if err := c.validateCNAMEExclusive(d); err != nil {
+			return &RecordDataError{err}
 		}
 	}
 
-	recordType := strings.TrimSpace(d.RecordType)
+	recordType = strings.TrimSpace(d.RecordType)
 	recordValue := strings.TrimSpace(d.RecordValue)
-	reservedIPPath := strings.TrimSpace(d.ReservedIPPath)
 	hasRecord := recordType != "" || recordValue != ""
-	hasAlias := reservedIPPath != ""
-	if hasRecord == hasAlias {
+	if hasRecord == d.IsAlias() {
 		return fmt.Errorf("DNS record %s must be either a record (type+value) or an alias", d.ID)
 	}
 	if hasRecord {
 		if recordType == "" || recordValue == "" {
 			return fmt.Errorf("DNS record %s must set both record type and value", d.ID)
 		}
+		if err := ValidateRecordValue(recordType, recordValue); err != nil {
+			return &RecordDataError{fmt.Errorf("DNS record %s: %w", d.ID, err)}
+		}
 		return nil
 	}
 
-	if c != nil {
-		item := c.Get(reservedIPPath)
+	paths := d.AliasTargetPaths()
+	if host := strings.TrimSpace(d.ReservedHost); host != "" {
+		if len(paths) > 0 {
+			return fmt.Errorf("DNS record %s must alias either reserved IPs or a host, not both", d.ID)
+		}
+		if err := ValidateHostname(host); err != nil {
+			return fmt.Errorf("invalid host for DNS record %s: %w", d.ID, err)
+		}
+		return nil
+	}
+	for i, path := range paths {
+		if slices.Contains(paths[:i], path) {
+			return fmt.Errorf("reserved IP %q is listed twice for DNS record %s", path, d.ID)
+		}
+		if c == nil {
+			continue
+		}
+		item := c.Get(path)
 		if item == nil {
-			return fmt.Errorf("reserved IP reference %q not found for DNS record %s", reservedIPPath, d.ID)
+			return fmt.Errorf("reserved IP reference %q not found for DNS record %s", path, d.ID)
 		}
 		if _, ok := item.(*IP); !ok {
-			return fmt.Errorf("reserved IP reference %q is not an IP for DNS record %s", reservedIPPath, d.ID)
+			return fmt.Errorf("reserved IP reference %q is not an IP for DNS record %s", path, d.ID)
+		}
+	}
+	return nil
+}
+
+// Validate checks DNSZone invariants.
+func (z *DNSZone) Validate(c *Catalog) error {
+	if err := ValidateDNSName(z.ID); err != nil {
+		return fmt.Errorf("invalid DNS zone %q: %w", z.ID, err)
+	}
+	if strings.HasSuffix(z.ID, ".") {
+		return fmt.Errorf("DNS zone %s must not end with a dot", z.ID)
+	}
+	if c != nil {
+		parent, ok := c.Get(z.GetParentPath()).(*StaticFolder)
+		if !ok || parent.ID != FolderDNS {
+			return fmt.Errorf("parent must be DNS for DNS zone %s", z.ID)
+		}
+	}
+	if err := validateTTL(z.TTL); err != nil {
+		return fmt.Errorf("DNS zone %s: %w", z.ID, err)
+	}
+	if contact := strings.TrimSpace(z.Contact); contact != "" {
+		if err := ValidateDNSName(strings.Replace(contact, "@", ".", 1)); err != nil {
+			return fmt.Errorf("invalid SOA contact %q for DNS zone %s: %w", contact, z.ID, err)
+		}
+	}
+	for _, ns := range z.NameServers {
+		if err := ValidateDNSName(ns); err != nil {
+			return fmt.Errorf("invalid name server for DNS zone %s: %w", z.ID, err)
 		}
 	}
 	return nil
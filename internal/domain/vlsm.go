@@ -0,0 +1,266 @@
+package domain
+
+import (
+	"cmp"
+	"fmt"
+	"math/big"
+	"net/netip"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// SubnetRequirement is one subnet the VLSM planner has to place.
+type SubnetRequirement struct {
+	Name string
+	// PrefixLen is the requested prefix length, or 0 when HostCount is used instead.
+	PrefixLen int
+	// HostCount is the number of usable addresses the subnet must provide.
+	HostCount int
+	VLANID    int
+	// Container allocates the subnet as a Subnet Container instead of a Host Pool.
+	Container bool
+}
+
+// PlannedSubnet is a requirement placed at a concrete CIDR.
+type PlannedSubnet struct {
+	SubnetRequirement
+	CIDR string
+}
+
+// ParseSubnetRequirements parses one requirement per line:
+//
+//	[<count>x] <name> <size> [<vlan>] [container]
+//
+// size is either a prefix length such as /24 or a usable host count such as 500.
+// Blank lines and lines starting with # are ignored. Requirements repeated
+// with a count are named <name>-1, <name>-2 and so on.
+func ParseSubnetRequirements(text string) ([]SubnetRequirement, error) {
+	var reqs []SubnetRequirement
+	for i, line := range strings.Split(text, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		lineErr := func(format string, args ...any) error {
+			return fmt.Errorf("line %d: %s", i+1, fmt.Sprintf(format, args...))
+		}
+
+		count := 1
+		if countText, ok := strings.CutSuffix(strings.ToLower(fields[0]), "x"); ok {
+			if n, err := strconv.Atoi(countText); err == nil {
+				if n < 1 {
+					return nil, lineErr("count must be at least 1")
+				}
+				count = n
+				fields = fields[1:]
+			}
+		}
+		if len(fields) < 2 {
+			return nil, lineErr("expected <name> <size> [<vlan>] [container]")
+		}
+
+		req := SubnetRequirement{Name: fields[0]}
+		if prefixText, ok := strings.CutPrefix(fields[1], "/"); ok {
+			bits, err := strconv.Atoi(prefixText)
+			if err != nil || bits < 1 {
+				return nil, lineErr("invalid prefix length %q", fields[1])
+			}
+			req.PrefixLen = bits
+		} else {
+			hosts, err := strconv.Atoi(fields[1])
+			if err != nil || hosts < 1 {
+				return nil, lineErr("invalid size %q: use a prefix length like /24 or a host count", fields[1])
+			}
+			req.HostCount = hosts
+		}
+		for _, field := range fields[2:] {
+			if strings.EqualFold(field, "container") {
+				req.Container = true
+				continue
+			}
+			vlanID, err := strconv.Atoi(field)
+			if err != nil || vlanID < 1 || vlanID > 4094 {
+				return nil, lineErr("invalid VLAN ID %q", field)
+			}
+			req.VLANID = vlanID
+		}
+
+		for n := range count {
+			r := req
+			if count > 1 {
+				r.Name = fmt.Sprintf("%s-%d", req.Name, n+1)
+			}
+			reqs = append(reqs, r)
+		}
+	}
+	if len(reqs) == 0 {
+		return nil, fmt.Errorf("no requirements given")
+	}
+	return reqs, nil
+}
+
+// PlanSubnets places reqs into the free space of container, largest first,
+// each at the lowest aligned address where it fits. The plan is returned in
+// address order; nothing is changed in the catalog.
+func (c *Catalog) PlanSubnets(container *Network, reqs []SubnetRequirement) ([]PlannedSubnet, error) {
+	if container.AllocationMode != AllocationModeSubnets {
+		return nil, fmt.Errorf("%s is not a Subnet Container", container.GetPath())
+	}
+	prefix, err := netip.ParsePrefix(container.ID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid CIDR %s: %w", container.ID, err)
+	}
+	isIPv4 := prefix.Addr().Is4()
+	maxBits := prefix.Addr().BitLen()
+
+	type placement struct {
+		req  SubnetRequirement
+		bits int
+	}
+	placements := make([]placement, 0, len(reqs))
+	for _, req := range reqs {
+		if req.VLANID > 0 && c.FindVLANByID(req.VLANID) == nil {
+			return nil, fmt.Errorf("%s: VLAN ID %d not found", req.Name, req.VLANID)
+		}
+		bits := req.PrefixLen
+		if bits == 0 {
+			bits = prefixForHostCount(prefix, req.HostCount)
+		}
+		if bits <= prefix.Bits() || bits >= maxBits {
+			return nil, fmt.Errorf("%s: /%d does not fit as a child of %s", req.Name, bits, container.ID)
+		}
+		placements = append(placements, placement{req: req, bits: bits})
+	}
+	slices.SortStableFunc(placements, func(l, r placement) int {
+		return cmp.Compare(l.bits, r.bits)
+	})
+
+	type freeRange struct{ start, end *big.Int }
+	var free []freeRange
+	blocks, err := c.AddressMap(container)
+	if err != nil {
+		return nil, err
+	}
+	one := big.NewInt(1)
+	for _, block := range blocks {
+		if block.Network != nil && block.Network.AllocationMode != AllocationModeUnallocated {
+			continue
+		}
+		blockPrefix, err := netip.ParsePrefix(block.CIDR)
+		if err != nil {
+			return nil, fmt.Errorf("invalid CIDR %s: %w", block.CIDR, err)
+		}
+		start, end := IPToBigInt(blockPrefix.Masked().Addr()), IPToBigInt(LastAddr(blockPrefix))
+		if last := len(free) - 1; last >= 0 && new(big.Int).Add(free[last].end, one).Cmp(start) == 0 {
+			free[last].end = end
+			continue
+		}
+		free = append(free, freeRange{start: start, end: end})
+	}
+
+	plan := make([]PlannedSubnet, 0, len(placements))
+	for _, p := range placements {
+		size := new(big.Int).Lsh(one, uint(maxBits-p.bits))
+		placed := false
+		for i, r := range free {
+			// Round the range start up to the next multiple of size.
+			aligned := new(big.Int).Add(r.start, new(big.Int).Sub(size, one))
+			aligned.Div(aligned, size).Mul(aligned, size)
+			last := new(big.Int).Add(aligned, new(big.Int).Sub(size, one))
+			if last.Cmp(r.end) > 0 {
+				continue
+			}
+			plan = append(plan, PlannedSubnet{
+				SubnetRequirement: p.req,
+				CIDR:              netip.PrefixFrom(BigIntToAddr(aligned, isIPv4), p.bits).String(),
+			})
+			var rest []freeRange
+			if aligned.Cmp(r.start) > 0 {
+				rest = append(rest, freeRange{start: r.start, end: new(big.Int).Sub(aligned, one)})
+			}
+			if last.Cmp(r.end) < 0 {
+				rest = append(rest, freeRange{start: new(big.Int).Add(last, one), end: r.end})
+			}
+			free = slices.Replace(free, i, i+1, rest...)
+			placed = true
+			break
+		}
+		if !placed {
+			return nil, fmt.Errorf("%s: no free /%d left in %s", p.req.Name, p.bits, container.ID)
+		}
+	}
+
+	slices.SortFunc(plan, func(l, r PlannedSubnet) int {
+		return netip.MustParsePrefix(l.CIDR).Addr().Compare(netip.MustParsePrefix(r.CIDR).Addr())
+	})
+	return plan, nil
+}
+
+// prefixForHostCount returns the longest prefix length, in the address family
+// of container, whose subnets provide at least hosts usable addresses.
+func prefixForHostCount(container netip.Prefix, hosts int) int {
+	want := big.NewInt(int64(hosts))
+	for bits := container.Addr().BitLen() - 1; bits > 0; bits-- {
+		if usableHostCount(netip.PrefixFrom(container.Addr(), bits)).Cmp(want) >= 0 {
+			return bits
+		}
+	}
+	return 0
+}
+
+// ApplySubnetPlan allocates every planned subnet inside container as a Host
+// Pool or, when requested, a Subnet Container. Every CIDR, name and VLAN is
+// checked before the catalog is changed, so a plan is applied in full or not
+// at all.
+func (c *Catalog) ApplySubnetPlan(container *Network, plan []PlannedSubnet) error {
+	if container.AllocationMode != AllocationModeSubnets {
+		return fmt.Errorf("%s is not a Subnet Container", container.GetPath())
+	}
+	containerPrefix, err := netip.ParsePrefix(container.ID)
+	if err != nil {
+		return fmt.Errorf("invalid CIDR %s: %w", container.ID, err)
+	}
+
+	networks := make([]*Network, 0, len(plan))
+	prefixes := make([]netip.Prefix, 0, len(plan))
+	for _, p := range plan {
+		prefix, err := netip.ParsePrefix(p.CIDR)
+		if err != nil {
+			return fmt.Errorf("%s: invalid CIDR %s: %w", p.Name, p.CIDR, err)
+		}
+		if prefix.Bits() <= containerPrefix.Bits() || !containerPrefix.Contains(prefix.Addr()) {
+			return fmt.Errorf("%s: %s is not a subnet of %s", p.Name, p.CIDR, container.ID)
+		}
+		if other := c.overlappingAllocatedChild(container, prefix); other != nil {
+			return fmt.Errorf("%s: %s overlaps allocated network %s", p.Name, p.CIDR, other.GetPath())
+		}
+		for i, planned := range prefixes {
+			if planned.Overlaps(prefix) {
+				return fmt.Errorf("%s: %s overlaps planned subnet %s", p.Name, p.CIDR, networks[i].DisplayName)
+			}
+		}
+		n := &Network{
+			Base:           Base{ID: p.CIDR, ParentPath: container.GetPath()},
+			AllocationMode: AllocationModeHosts,
+			DisplayName:    p.Name,
+			VLANID:         p.VLANID,
+		}
+		if p.Container {
+			n.AllocationMode = AllocationModeSubnets
+		}
+		if err := n.Validate(c); err != nil {
+			return fmt.Errorf("%s: %w", p.Name, err)
+		}
+		networks = append(networks, n)
+		prefixes = append(prefixes, prefix)
+	}
+
+	for _, n := range networks {
+		if _, err := c.CarveFreeBlock(container, n.ID); err != nil {
+			return err
+		}
+		c.Put(n)
+	}
+	return nil
+}
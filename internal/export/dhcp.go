@@ -0,0 +1,282 @@
+package export
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"net"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/plumber-cd/ez-ipam/internal/domain"
+)
+
+// PoolLeases are the static DHCP leases of one Host Pool: every reservation
+// with a MAC address.
+type PoolLeases struct {
+	Pool   *domain.Network
+	Prefix netip.Prefix
+	Leases []*domain.IP
+}
+
+// StaticLeases returns the static leases of every Host Pool under root, in
+// tree order. root is a Host Pool, a Subnet Container or, when nil, the
+// Networks folder. Pools without leases are left out.
+func StaticLeases(catalog *domain.Catalog, root domain.Item) ([]PoolLeases, error) {
+	if root == nil {
+		root = catalog.GetByParentAndDisplayID(nil, domain.FolderNetworks)
+	}
+	var hostPools []*domain.Network
+	var walk func(item domain.Item)
+	walk = func(item domain.Item) {
+		if n, ok := item.(*domain.Network); ok && n.AllocationMode == domain.AllocationModeHosts {
+			hostPools = append(hostPools, n)
+			return
+		}
+		for _, child := range catalog.GetChildren(item) {
+			if n, ok := child.(*domain.Network); ok && n.AllocationMode != domain.AllocationModeUnallocated {
+				walk(n)
+			}
+		}
+	}
+	walk(root)
+
+	var pools []PoolLeases
+	for _, n := range hostPools {
+		prefix, err := netip.ParsePrefix(n.ID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid CIDR %s: %w", n.ID, err)
+		}
+		pool := PoolLeases{Pool: n, Prefix: prefix}
+		for _, child := range catalog.GetChildren(n) {
+			if ip, ok := child.(*domain.IP); ok && strings.TrimSpace(ip.MACAddress) != "" {
+				pool.Leases = append(pool.Leases, ip)
+			}
+		}
+		if len(pool.Leases) > 0 {
+			pools = append(pools, pool)
+		}
+	}
+	return pools, nil
+}
+
+// ErrDhcpdMixedFamilies is returned when ISC dhcpd leases of both address
+// families are rendered together: dhcpd -4 and dhcpd -6 each reject the
+// other family's subnets, so they need separate files.
+var ErrDhcpdMixedFamilies = errors.New("ISC dhcpd reads IPv4 and IPv6 subnets from separate files")
+
+// RenderDHCPLeases renders the static leases of pools in one of
+// domain.DHCPFormats. For dhcpd, pools must be of a single address family;
+// see SplitPoolsByFamily and WriteDhcpdFiles.
+func RenderDHCPLeases(pools []PoolLeases, format string) (string, error) {
+	switch format {
+	case domain.DHCPFormatKea:
+		return renderKea(pools)
+	case domain.DHCPFormatDnsmasq:
+		return renderDnsmasq(pools), nil
+	case domain.DHCPFormatDhcpd:
+		if v4, v6 := SplitPoolsByFamily(pools); len(v4) > 0 && len(v6) > 0 {
+			return "", ErrDhcpdMixedFamilies
+		}
+		return renderDhcpd(pools), nil
+	}
+	return "", fmt.Errorf("unknown DHCP format %q: must be one of %s", format, strings.Join(domain.DHCPFormats, ", "))
+}
+
+// SplitPoolsByFamily separates IPv4 pools from IPv6 pools, keeping their order.
+func SplitPoolsByFamily(pools []PoolLeases) (v4, v6 []PoolLeases) {
+	for _, pool := range pools {
+		if pool.Prefix.Addr().Is4() {
+			v4 = append(v4, pool)
+		} else {
+			v6 = append(v6, pool)
+		}
+	}
+	return v4, v6
+}
+
+// DhcpdIPv6Path returns the file the IPv6 half of a dhcpd export at path is
+// written to: a 6 is appended to the base name, as in dhcpd.conf and dhcpd6.conf.
+func DhcpdIPv6Path(path string) string {
+	ext := filepath.Ext(path)
+	return strings.TrimSuffix(path, ext) + "6" + ext
+}
+
+// WriteDhcpdFiles writes the IPv4 host blocks of pools to path and the IPv6
+// ones to DhcpdIPv6Path(path). Both files are always written, so a family
+// without leases does not leave stale hosts behind.
+func WriteDhcpdFiles(path string, pools []PoolLeases) error {
+	v4, v6 := SplitPoolsByFamily(pools)
+	if err := writeExportFile(path, renderDhcpd(v4)); err != nil {
+		return err
+	}
+	return writeExportFile(DhcpdIPv6Path(path), renderDhcpd(v6))
+}
+
+type keaReservation struct {
+	HWAddress   string   `json:"hw-address"`
+	IPAddress   string   `json:"ip-address,omitempty"`
+	IPAddresses []string `json:"ip-addresses,omitempty"`
+	Hostname    string   `json:"hostname,omitempty"`
+}
+
+type keaSubnet struct {
+	ID           uint32           `json:"id"`
+	Subnet       string           `json:"subnet"`
+	Comment      string           `json:"comment,omitempty"`
+	Reservations []keaReservation `json:"reservations"`
+}
+
+// maxKeaSubnetID is the largest subnet ID Kea accepts.
+const maxKeaSubnetID = 1<<32 - 2
+
+// keaSubnetID derives the Kea subnet ID of prefix from its CIDR, so adding a
+// pool or exporting a subset does not renumber the others and break their
+// leases. An ID already taken by another pool is probed upwards.
+func keaSubnetID(prefix netip.Prefix, used map[uint32]bool) uint32 {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(prefix.String()))
+	id := h.Sum32()%maxKeaSubnetID + 1
+	for used[id] {
+		id = id%maxKeaSubnetID + 1
+	}
+	used[id] = true
+	return id
+}
+
+// renderKea renders Kea "subnet4" and "subnet6" lists with reservations.
+// Subnet IDs are derived from the pool CIDRs.
+func renderKea(pools []PoolLeases) (string, error) {
+	config := map[string]map[string][]keaSubnet{}
+	used := map[uint32]bool{}
+	for _, pool := range pools {
+		subnet := keaSubnet{ID: keaSubnetID(pool.Prefix, used), Subnet: pool.Prefix.String(), Comment: pool.Pool.DisplayName}
+		for _, ip := range pool.Leases {
+			reservation := keaReservation{HWAddress: ip.MACAddress, Hostname: ip.DisplayName}
+			if pool.Prefix.Addr().Is4() {
+				reservation.IPAddress = ip.ID
+			} else {
+				reservation.IPAddresses = []string{ip.ID}
+			}
+			subnet.Reservations = append(subnet.Reservations, reservation)
+		}
+		server, list := "Dhcp4", "subnet4"
+		if pool.Prefix.Addr().Is6() {
+			server, list = "Dhcp6", "subnet6"
+		}
+		if config[server] == nil {
+			config[server] = map[string][]keaSubnet{}
+		}
+		config[server][list] = append(config[server][list], subnet)
+	}
+	data, err := json.MarshalIndent(config, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("marshal Kea reservations: %w", err)
+	}
+	return string(data) + "\n", nil
+}
+
+// renderDnsmasq renders dhcp-host lines grouped by pool.
+func renderDnsmasq(pools []PoolLeases) string {
+	sb := new(strings.Builder)
+	for i, pool := range pools {
+		if i > 0 {
+			sb.WriteString("\n")
+		}
+		fmt.Fprintf(sb, "# %s\n", poolComment(pool))
+		for _, ip := range pool.Leases {
+			addr := ip.ID
+			if pool.Prefix.Addr().Is6() {
+				addr = "[" + addr + "]"
+			}
+			fmt.Fprintf(sb, "dhcp-host=%s,%s,%s\n", ip.MACAddress, addr, ip.DisplayName)
+		}
+	}
+	return sb.String()
+}
+
+// renderDhcpd renders ISC dhcpd subnet declarations holding host blocks. Host
+// block names must be unique, so repeated hostnames get the address appended.
+func renderDhcpd(pools []PoolLeases) string {
+	sb := new(strings.Builder)
+	seen := map[string]bool{}
+	for i, pool := range pools {
+		if i > 0 {
+			sb.WriteString("\n")
+		}
+		fmt.Fprintf(sb, "# %s\n", poolComment(pool))
+		if pool.Prefix.Addr().Is4() {
+			mask := net.CIDRMask(pool.Prefix.Bits(), 32)
+			fmt.Fprintf(sb, "subnet %s netmask %s {\n", pool.Prefix.Addr(), net.IP(mask))
+		} else {
+			fmt.Fprintf(sb, "subnet6 %s {\n", pool.Prefix)
+		}
+		for _, ip := range pool.Leases {
+			name := ip.DisplayName
+			if seen[name] {
+				name += "-" + strings.NewReplacer(".", "-", ":", "-").Replace(ip.ID)
+			}
+			seen[name] = true
+			fmt.Fprintf(sb, "  host %s {\n", name)
+			fmt.Fprintf(sb, "    hardware ethernet %s;\n", ip.MACAddress)
+			if pool.Prefix.Addr().Is4() {
+				fmt.Fprintf(sb, "    fixed-address %s;\n", ip.ID)
+			} else {
+				fmt.Fprintf(sb, "    fixed-address6 %s;\n", ip.ID)
+			}
+			fmt.Fprintf(sb, "    option host-name \"%s\";\n", ip.DisplayName)
+			sb.WriteString("  }\n")
+		}
+		sb.WriteString("}\n")
+	}
+	return sb.String()
+}
+
+func poolComment(pool PoolLeases) string {
+	if pool.Pool.DisplayName == "" {
+		return pool.Prefix.String()
+	}
+	return fmt.Sprintf("%s (%s)", pool.Prefix, pool.Pool.DisplayName)
+}
+
+// WriteDHCPExports writes every static lease export configured in the
+// catalog's config below dir.
+func WriteDHCPExports(dir string, catalog *domain.Catalog) error {
+	if len(catalog.Config.DHCPExports) == 0 {
+		return nil
+	}
+	pools, err := StaticLeases(catalog, nil)
+	if err != nil {
+		return err
+	}
+	for _, target := range catalog.Config.DHCPExports {
+		path := filepath.Join(dir, target.Path)
+		if target.Format == domain.DHCPFormatDhcpd {
+			if err := WriteDhcpdFiles(path, pools); err != nil {
+				return err
+			}
+			continue
+		}
+		content, err := RenderDHCPLeases(pools, target.Format)
+		if err != nil {
+			return err
+		}
+		if err := writeExportFile(path, content); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeExportFile(path, content string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("create %s: %w", filepath.Dir(path), err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		return fmt.Errorf("write %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,538 @@
+package export
+
+import (
+	"bytes"
+	"cmp"
+	"errors"
+	"fmt"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/plumber-cd/ez-ipam/internal/domain"
+)
+
+// ResourceRecord is one DNS record ready for export. Name is a fully
+// qualified name without the trailing dot.
+type ResourceRecord struct {
+	Name  string
+	Type  string
+	Value string
+	// TTL overrides the zone's default TTL when not 0.
+	TTL int
+	// Source is the catalog path the record was derived from.
+	Source string
+}
+
+// Zone is a DNS zone with the records that belong to it.
+type Zone struct {
+	Name    string
+	Records []ResourceRecord
+	// NSDomain is the domain of the default ns1 and hostmaster names; the
+	// zone itself when empty.
+	NSDomain string
+	// TTL, Contact and NameServers come from the DNS zone entity of the same
+	// name and override the DNS defaults when set.
+	TTL         int
+	Contact     string
+	NameServers []string
+}
+
+// DNSResourceRecords collects the records to export: every DNS record, with
+// aliases resolved to an A or AAAA record for each of their reservations by
+// its family, and an A or
+// AAAA record for every reservation whose hostname is fully qualified.
+// Identical records are listed once.
+func DNSResourceRecords(catalog *domain.Catalog) ([]ResourceRecord, error) {
+	var records []ResourceRecord
+	for _, item := range catalog.All() {
+		switch v := item.(type) {
+		case *domain.DNSRecord:
+			if v.IsAlias() {
+				for _, path := range v.AliasTargetPaths() {
+					if _, ok := catalog.Get(path).(*domain.IP); !ok {
+						return nil, fmt.Errorf("alias %s: reserved IP %q not found", v.ID, path)
+					}
+				}
+				for _, ip := range catalog.AliasTargets(v) {
+					record := addressRecord(v.ID, ip.ID, v.GetPath())
+					record.TTL = v.TTL
+					records = append(records, record)
+				}
+				continue
+			}
+			records = append(records, ResourceRecord{
+				Name:   normalizeDNSName(v.ID),
+				Type:   strings.ToUpper(strings.TrimSpace(v.RecordType)),
+				Value:  strings.TrimSpace(v.RecordValue),
+				TTL:    v.TTL,
+				Source: v.GetPath(),
+			})
+		case *domain.IP:
+			if strings.Contains(strings.Trim(v.DisplayName, "."), ".") {
+				records = append(records, addressRecord(v.DisplayName, v.ID, v.GetPath()))
+			}
+		}
+	}
+	slices.SortFunc(records, compareRecords)
+	return slices.CompactFunc(records, func(l, r ResourceRecord) bool {
+		return l.Name == r.Name && l.Type == r.Type && l.Value == r.Value
+	}), nil
+}
+
+func addressRecord(name, address, source string) ResourceRecord {
+	recordType := "A"
+	if addr, err := netip.ParseAddr(address); err == nil && addr.Is6() {
+		recordType = "AAAA"
+	}
+	return ResourceRecord{Name: normalizeDNSName(name), Type: recordType, Value: address, Source: source}
+}
+
+func normalizeDNSName(name string) string {
+	return strings.ToLower(strings.TrimSuffix(strings.TrimSpace(name), "."))
+}
+
+func compareRecords(l, r ResourceRecord) int {
+	return cmp.Or(
+		cmp.Compare(reverseLabels(l.Name), reverseLabels(r.Name)),
+		cmp.Compare(l.Type, r.Type),
+		cmp.Compare(l.Value, r.Value),
+	)
+}
+
+// reverseLabels orders names so that a zone apex sorts before its subdomains.
+func reverseLabels(name string) string {
+	labels := strings.Split(name, ".")
+	slices.Reverse(labels)
+	return strings.Join(labels, "\x00")
+}
+
+// isInZone reports whether name is zone or one of its subdomains.
+func isInZone(name, zone string) bool {
+	return name == zone || strings.HasSuffix(name, "."+zone)
+}
+
+// GroupZones places each record in the deepest of zoneNames containing it.
+// Without zoneNames, one zone is inferred per top-level domain: the longest
+// parent domain shared by every name below it, so names directly under a
+// single-label domain such as "home" share that zone. Names outside every
+// zone are returned separately.
+func GroupZones(records []ResourceRecord, zoneNames []string) ([]Zone, []string) {
+	if len(zoneNames) == 0 {
+		zoneNames = inferZoneNames(records)
+	}
+	names := make([]string, 0, len(zoneNames))
+	for _, name := range zoneNames {
+		names = append(names, normalizeDNSName(name))
+	}
+	// Longest first, so the deepest zone containing a name wins.
+	slices.SortFunc(names, func(l, r string) int {
+		return cmp.Or(cmp.Compare(len(r), len(l)), cmp.Compare(l, r))
+	})
+
+	byName := map[string]*Zone{}
+	var outside []string
+	for _, record := range records {
+		idx := slices.IndexFunc(names, func(zone string) bool { return isInZone(record.Name, zone) })
+		if idx < 0 {
+			outside = append(outside, record.Name)
+			continue
+		}
+		zone := byName[names[idx]]
+		if zone == nil {
+			zone = &Zone{Name: names[idx]}
+			byName[names[idx]] = zone
+		}
+		zone.Records = append(zone.Records, record)
+	}
+	zones := make([]Zone, 0, len(byName))
+	for _, zone := range byName {
+		zones = append(zones, *zone)
+	}
+	slices.SortFunc(zones, func(l, r Zone) int { return cmp.Compare(reverseLabels(l.Name), reverseLabels(r.Name)) })
+	return zones, slices.Compact(outside)
+}
+
+// inferZoneNames returns, per top-level domain, the longest domain that
+// every record name below it ends in. A lone name stands for a host, so its
+// parent domain is used instead.
+func inferZoneNames(records []ResourceRecord) []string {
+	common := map[string][]string{}
+	var tlds []string
+	for _, record := range records {
+		labels := strings.Split(record.Name, ".")
+		tld := labels[len(labels)-1]
+		shared, ok := common[tld]
+		if !ok {
+			tlds = append(tlds, tld)
+			common[tld] = labels
+			continue
+		}
+		n := 0
+		for n < len(shared) && n < len(labels) && shared[len(shared)-1-n] == labels[len(labels)-1-n] {
+			n++
+		}
+		common[tld] = shared[len(shared)-n:]
+	}
+	names := make([]string, 0, len(tlds))
+	for _, tld := range tlds {
+		labels := common[tld]
+		zone := strings.Join(labels, ".")
+		if len(labels) > 1 && !slices.ContainsFunc(records, func(record ResourceRecord) bool {
+			return record.Name != zone && isInZone(record.Name, zone)
+		}) {
+			zone = strings.Join(labels[1:], ".")
+		}
+		names = append(names, zone)
+	}
+	return names
+}
+
+// zoneSOA holds the resolved SOA and NS data of a zone.
+type zoneSOA struct {
+	primary     string
+	admin       string
+	nameServers []string
+	// defaultNS is set when the only name server is the made-up ns1 name.
+	defaultNS bool
+}
+
+func resolveSOA(zone Zone, d domain.DNSConfig) zoneSOA {
+	nsDomain := cmp.Or(zone.NSDomain, zone.Name)
+	nameServers := d.NameServers
+	primary := d.PrimaryNS
+	if len(zone.NameServers) > 0 {
+		nameServers = zone.NameServers
+		primary = zone.NameServers[0]
+	}
+	soa := zoneSOA{primary: normalizeDNSName(primary), admin: normalizeDNSName(cmp.Or(zone.Contact, d.AdminEmail))}
+	if soa.primary == "" {
+		soa.primary = "ns1." + nsDomain
+	}
+	if soa.admin == "" {
+		soa.admin = "hostmaster." + nsDomain
+	}
+	// The SOA RNAME is the mailbox with "@" written as a dot.
+	soa.admin = strings.Replace(soa.admin, "@", ".", 1)
+	for _, ns := range nameServers {
+		soa.nameServers = append(soa.nameServers, normalizeDNSName(ns))
+	}
+	if len(soa.nameServers) == 0 {
+		soa.nameServers = []string{soa.primary}
+		soa.defaultNS = primary == ""
+	}
+	return soa
+}
+
+// CheckZone validates a zone the way named-checkzone would before it is
+// loaded: record data must parse for its type, a CNAME must be the only
+// record at its name and not at the apex, and configured name servers inside
+// the zone need address records. The default ns1 name is only reported by
+// ZoneWarnings.
+func CheckZone(zone Zone, d domain.DNSConfig) error {
+	var errs []error
+	types := map[string][]string{}
+	for _, record := range zone.Records {
+		types[record.Name] = append(types[record.Name], record.Type)
+		if err := checkRecord(record); err != nil {
+			errs = append(errs, fmt.Errorf("%s %s (%s): %w", record.Name, record.Type, record.Source, err))
+		}
+	}
+	for name, nameTypes := range types {
+		if !slices.Contains(nameTypes, "CNAME") {
+			continue
+		}
+		if name == zone.Name {
+			errs = append(errs, fmt.Errorf("%s: CNAME is not allowed at the zone apex", name))
+		} else if len(nameTypes) > 1 {
+			errs = append(errs, fmt.Errorf("%s: CNAME and other data", name))
+		}
+	}
+	if soa := resolveSOA(zone, d); !soa.defaultNS {
+		for _, ns := range missingGlue(zone, soa) {
+			errs = append(errs, fmt.Errorf("NS %s has no address records; add one or set dns.name_servers", ns))
+		}
+	}
+	if len(errs) > 0 {
+		slices.SortFunc(errs, func(l, r error) int { return cmp.Compare(l.Error(), r.Error()) })
+		return fmt.Errorf("zone %s: %w", zone.Name, errors.Join(errs...))
+	}
+	return nil
+}
+
+// ZoneWarnings reports a zone served by the default ns1 name when that name
+// has no address records. The zone still loads, but nobody can resolve its
+// name server until one is added or the name servers are configured.
+func ZoneWarnings(zone Zone, d domain.DNSConfig) []string {
+	soa := resolveSOA(zone, d)
+	if !soa.defaultNS {
+		return nil
+	}
+	var warnings []string
+	for _, ns := range missingGlue(zone, soa) {
+		warnings = append(warnings, fmt.Sprintf("zone %s: default NS %s has no address records; add one, set dns.name_servers or add a DNS zone", zone.Name, ns))
+	}
+	return warnings
+}
+
+// missingGlue returns the name servers of a zone that are inside it but have
+// no address records in it.
+func missingGlue(zone Zone, soa zoneSOA) []string {
+	var missing []string
+	for _, ns := range soa.nameServers {
+		if !isInZone(ns, zone.Name) {
+			continue
+		}
+		if !slices.ContainsFunc(zone.Records, func(record ResourceRecord) bool {
+			return record.Name == ns && (record.Type == "A" || record.Type == "AAAA")
+		}) {
+			missing = append(missing, ns)
+		}
+	}
+	return missing
+}
+
+// checkRecord checks the name and data of record. Owner names may hold "_"
+// labels, and reverse names may hold RFC 2317 labels, so names under .arpa
+// are only checked for length.
+func checkRecord(record ResourceRecord) error {
+	if err := checkDNSName(record.Name); err != nil {
+		return err
+	}
+	switch record.Type {
+	case "CNAME", "NS", "PTR":
+		return checkDNSName(normalizeDNSName(record.Value))
+	}
+	return domain.ValidateRecordValue(record.Type, record.Value)
+}
+
+func checkDNSName(name string) error {
+	if !strings.HasSuffix(name, ".arpa") {
+		return domain.ValidateOwnerName(name)
+	}
+	for _, label := range strings.Split(name, ".") {
+		if label == "" || len(label) > 63 {
+			return fmt.Errorf("label %q of %q must be 1 to 63 characters long", label, name)
+		}
+	}
+	return nil
+}
+
+// RenderBINDZone renders zone as an RFC 1035 master file. Record names are
+// written relative to the origin and name targets as absolute names.
+func RenderBINDZone(zone Zone, d domain.DNSConfig, serial uint32) string {
+	soa := resolveSOA(zone, d)
+	sb := new(strings.Builder)
+	fmt.Fprintf(sb, "; Zone %s, generated by ez-ipam. Do not edit.\n", zone.Name)
+	fmt.Fprintf(sb, "$ORIGIN %s.\n", zone.Name)
+	fmt.Fprintf(sb, "$TTL %d\n", cmp.Or(zone.TTL, d.TTL))
+	fmt.Fprintf(sb, "@\tIN\tSOA\t%s. %s. (\n", soa.primary, soa.admin)
+	fmt.Fprintf(sb, "\t\t\t%d ; serial\n", serial)
+	fmt.Fprintf(sb, "\t\t\t%d ; refresh\n", d.Refresh)
+	fmt.Fprintf(sb, "\t\t\t%d ; retry\n", d.Retry)
+	fmt.Fprintf(sb, "\t\t\t%d ; expire\n", d.Expire)
+	fmt.Fprintf(sb, "\t\t\t%d ) ; minimum\n", d.Minimum)
+	for _, ns := range soa.nameServers {
+		fmt.Fprintf(sb, "@\tIN\tNS\t%s.\n", ns)
+	}
+	for _, record := range zone.Records {
+		name := relativeName(record.Name, zone.Name)
+		if record.TTL > 0 {
+			name += "\t" + strconv.Itoa(record.TTL)
+		}
+		fmt.Fprintf(sb, "%s\tIN\t%s\t%s\n", name, record.Type, bindValue(record))
+	}
+	return sb.String()
+}
+
+func relativeName(name, zone string) string {
+	if name == zone {
+		return "@"
+	}
+	return strings.TrimSuffix(name, "."+zone)
+}
+
+// bindValue writes name targets as absolute names and quotes TXT values.
+func bindValue(record ResourceRecord) string {
+	fields := strings.Fields(record.Value)
+	absolute := func(name string) string {
+		if name == "." || strings.HasSuffix(name, ".") {
+			return name
+		}
+		return name + "."
+	}
+	switch record.Type {
+	case "CNAME", "NS", "PTR":
+		return absolute(record.Value)
+	case "MX", "SRV":
+		if len(fields) > 0 {
+			fields[len(fields)-1] = absolute(fields[len(fields)-1])
+		}
+		return strings.Join(fields, " ")
+	case "TXT":
+		if strings.HasPrefix(record.Value, `"`) {
+			return record.Value
+		}
+		return `"` + strings.ReplaceAll(record.Value, `\`, `\\`) + `"`
+	}
+	return record.Value
+}
+
+// DNSZones builds and checks the forward zones of catalog: one per DNS zone
+// entity and configured zone, or zones inferred from the record names when
+// none are configured. Zone entities take their settings along, and a zone
+// entity inside another zone is delegated from it with NS and glue records.
+// Names outside every zone are returned separately.
+func DNSZones(catalog *domain.Catalog) ([]Zone, []string, error) {
+	records, err := DNSResourceRecords(catalog)
+	if err != nil {
+		return nil, nil, err
+	}
+	d := catalog.Config.DNSDefaults()
+	entities := catalog.DNSZones()
+	zoneNames := slices.Clone(d.Zones)
+	for _, entity := range entities {
+		zoneNames = append(zoneNames, entity.ID)
+	}
+	zones, outside := GroupZones(records, zoneNames)
+	if len(d.Zones) == 0 && len(entities) > 0 && len(outside) > 0 {
+		rest := slices.DeleteFunc(slices.Clone(records), func(record ResourceRecord) bool {
+			return !slices.Contains(outside, record.Name)
+		})
+		var inferred []Zone
+		inferred, outside = GroupZones(rest, nil)
+		zones = append(zones, inferred...)
+	}
+	for _, entity := range entities {
+		name := normalizeDNSName(entity.ID)
+		idx := slices.IndexFunc(zones, func(zone Zone) bool { return zone.Name == name })
+		if idx < 0 {
+			zones = append(zones, Zone{Name: name})
+			idx = len(zones) - 1
+		}
+		zones[idx].TTL = entity.TTL
+		zones[idx].Contact = entity.Contact
+		zones[idx].NameServers = entity.NameServers
+	}
+	slices.SortFunc(zones, func(l, r Zone) int { return cmp.Compare(reverseLabels(l.Name), reverseLabels(r.Name)) })
+	delegateZones(zones, d)
+
+	var errs []error
+	for _, zone := range zones {
+		if err := CheckZone(zone, d); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return zones, outside, errors.Join(errs...)
+}
+
+// delegateZones adds to every zone the NS records of the zones directly
+// below it, with glue address records for name servers inside them. zones
+// must be sorted so that parents come first.
+func delegateZones(zones []Zone, d domain.DNSConfig) {
+	for i := len(zones) - 1; i >= 0; i-- {
+		child := zones[i]
+		parent := -1
+		for j := range zones {
+			if j != i && isInZone(child.Name, zones[j].Name) && (parent < 0 || len(zones[j].Name) > len(zones[parent].Name)) {
+				parent = j
+			}
+		}
+		if parent < 0 {
+			continue
+		}
+		source := "delegation of " + child.Name
+		for _, ns := range resolveSOA(child, d).nameServers {
+			zones[parent].Records = append(zones[parent].Records, ResourceRecord{Name: child.Name, Type: "NS", Value: ns, Source: source})
+			if !isInZone(ns, child.Name) {
+				continue
+			}
+			for _, record := range child.Records {
+				if record.Name == ns && (record.Type == "A" || record.Type == "AAAA") {
+					glue := record
+					glue.Source = source
+					zones[parent].Records = append(zones[parent].Records, glue)
+				}
+			}
+		}
+		slices.SortFunc(zones[parent].Records, compareRecords)
+	}
+}
+
+// BINDZoneFileName returns the file name a zone is written to. The "/" of
+// RFC 2317 zone names is written as "-".
+func BINDZoneFileName(zone string) string {
+	return strings.ReplaceAll(zone, "/", "-") + ".zone"
+}
+
+// WriteBINDZones writes one checked zone file per zone into dir. The serial is
+// the current Unix time, but a file whose records did not change is left
+// alone so unchanged zones keep their serial.
+func WriteBINDZones(dir string, catalog *domain.Catalog, zones []Zone, now time.Time) error {
+	d := catalog.Config.DNSDefaults()
+	for _, zone := range zones {
+		path := filepath.Join(dir, BINDZoneFileName(zone.Name))
+		content := RenderBINDZone(zone, d, uint32(now.Unix()))
+		if existing, err := os.ReadFile(path); err == nil && bytes.Equal(withoutSerial(existing), withoutSerial([]byte(content))) {
+			continue
+		}
+		if err := writeExportFile(path, content); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func withoutSerial(content []byte) []byte {
+	lines := bytes.Split(content, []byte("\n"))
+	return bytes.Join(slices.DeleteFunc(lines, func(line []byte) bool {
+		return bytes.HasSuffix(line, []byte("; serial"))
+	}), []byte("\n"))
+}
+
+// WriteDNSExports writes every DNS export configured in the catalog's config
+// below dir. Nothing is written for a target whose records fail their checks.
+func WriteDNSExports(dir string, catalog *domain.Catalog) error {
+	for _, target := range catalog.Config.DNSExports {
+		path := filepath.Join(dir, target.Path)
+		if target.Format == domain.DNSFormatBIND {
+			zones, err := AllZones(catalog)
+			if err != nil {
+				return err
+			}
+			if err := WriteBINDZones(path, catalog, zones, time.Now()); err != nil {
+				return err
+			}
+			continue
+		}
+		records, err := LocalDNSRecords(catalog)
+		if err != nil {
+			return err
+		}
+		content, _, err := RenderLocalDNS(records, target.Format, catalog.Config.DNSDefaults().TTL)
+		if err != nil {
+			return err
+		}
+		if err := writeExportFile(path, content); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// AllZones returns the checked forward zones followed by the reverse zones.
+func AllZones(catalog *domain.Catalog) ([]Zone, error) {
+	zones, _, err := DNSZones(catalog)
+	if err != nil {
+		return nil, err
+	}
+	reverse, _, err := ReverseZones(catalog)
+	if err != nil {
+		return nil, err
+	}
+	return append(zones, reverse...), nil
+}
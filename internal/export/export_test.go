@@ -1,10 +1,15 @@
 package export
 
 import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
 	"github.com/plumber-cd/ez-ipam/internal/domain"
+	"github.com/plumber-cd/ez-ipam/internal/store"
 )
 
 func TestRenderMarkdownEmpty(t *testing.T) {
@@ -64,6 +69,13 @@ func TestRenderMarkdownWithData(t *testing.T) {
 		DisplayName:    "Office LAN",
 		Description:    "Office network",
 		VLANID:         100,
+		Gateway:        "10.0.0.1",
+		ExcludedRanges: []string{"10.0.0.250-10.0.0.254"},
+		DHCPRanges:     []string{"10.0.0.100-10.0.0.199"},
+		DHCPOptions: &domain.DHCPOptions{
+			DNSServers: []string{"10.0.0.53"},
+			LeaseTime:  "12h",
+		},
 	}
 	c.Put(net)
 
@@ -132,6 +144,15 @@ func TestRenderMarkdownWithData(t *testing.T) {
 		"gateway.example.com",
 		"`10.0.0.1` (Gateway `00:11:22:33:44:55`)",
 		"`10 mail.provider.home`",
+		"| Utilization |",
+		"1/254 reserved (0.4%)",
+		"| **Gateway** | `10.0.0.1` |",
+		"| **Excluded Ranges** | `10.0.0.250-10.0.0.254` |",
+		"| **DHCP Ranges** | `10.0.0.100-10.0.0.199` |",
+		"| **Address Split** | `100 dynamic (DHCP), 149 static, 5 excluded` |",
+		"| **DHCP Router** | `10.0.0.1` |",
+		"| **DHCP DNS Servers** | `10.0.0.53` |",
+		"| **DHCP Lease Time** | `12h` |",
 		"100",
 		"Office",
 		"Switch-1",
@@ -184,4 +205,595 @@ func TestRenderMarkdownNetworkHierarchy(t *testing.T) {
 	if !strings.Contains(md, "10.0.0.0/24") {
 		t.Error("expected child network in markdown")
 	}
+	if !strings.Contains(md, "0.4% allocated") {
+		t.Error("expected container utilization in markdown")
+	}
+	if !strings.Contains(md, "Largest Free Block") {
+		t.Error("expected largest free block in network details")
+	}
+
+	c.Config.PoolWarnThresholdPercent = 1
+	c.Put(&domain.IP{
+		Base:        domain.Base{ID: "10.0.0.1", ParentPath: parent.GetPath() + " -> 10.0.0.0/24"},
+		DisplayName: "gw",
+	})
+	c.Put(&domain.IP{
+		Base:        domain.Base{ID: "10.0.0.2", ParentPath: parent.GetPath() + " -> 10.0.0.0/24"},
+		DisplayName: "dns",
+	})
+	c.Put(&domain.IP{
+		Base:        domain.Base{ID: "10.0.0.3", ParentPath: parent.GetPath() + " -> 10.0.0.0/24"},
+		DisplayName: "ntp",
+	})
+	md, err = RenderMarkdown(c)
+	if err != nil {
+		t.Fatalf("RenderMarkdown() error: %v", err)
+	}
+	if !strings.Contains(md, "**Nearly exhausted:** 3/254 reserved") {
+		t.Error("expected nearly exhausted pool to be flagged")
+	}
+}
+
+func TestRenderDHCPLeases(t *testing.T) {
+	c := domain.NewCatalog()
+	c.Put(&domain.StaticFolder{Base: domain.Base{ID: domain.FolderNetworks}, Index: 0})
+	site := &domain.Network{
+		Base:           domain.Base{ID: "10.0.0.0/16", ParentPath: domain.FolderNetworks},
+		AllocationMode: domain.AllocationModeSubnets,
+		DisplayName:    "Site",
+	}
+	c.Put(site)
+	lan := &domain.Network{
+		Base:           domain.Base{ID: "10.0.1.0/24", ParentPath: site.GetPath()},
+		AllocationMode: domain.AllocationModeHosts,
+		DisplayName:    "LAN",
+	}
+	c.Put(lan)
+	v6 := &domain.Network{
+		Base:           domain.Base{ID: "fd00::/64", ParentPath: domain.FolderNetworks},
+		AllocationMode: domain.AllocationModeHosts,
+		DisplayName:    "LAN6",
+	}
+	c.Put(v6)
+	c.Put(&domain.IP{Base: domain.Base{ID: "10.0.1.10", ParentPath: lan.GetPath()}, DisplayName: "nas", MACAddress: "00:11:22:33:44:55"})
+	c.Put(&domain.IP{Base: domain.Base{ID: "10.0.1.20", ParentPath: lan.GetPath()}, DisplayName: "static"})
+	c.Put(&domain.IP{Base: domain.Base{ID: "fd00::10", ParentPath: v6.GetPath()}, DisplayName: "nas", MACAddress: "00:11:22:33:44:66"})
+
+	pools, err := StaticLeases(c, nil)
+	if err != nil {
+		t.Fatalf("StaticLeases() error: %v", err)
+	}
+	if len(pools) != 2 || len(pools[0].Leases) != 1 {
+		t.Fatalf("StaticLeases() = %+v, want two pools with one lease each", pools)
+	}
+
+	kea, err := RenderDHCPLeases(pools, domain.DHCPFormatKea)
+	if err != nil {
+		t.Fatalf("RenderDHCPLeases(kea) error: %v", err)
+	}
+	for _, want := range []string{
+		`"subnet4": [`,
+		`"subnet": "10.0.1.0/24"`,
+		`"hw-address": "00:11:22:33:44:55"`,
+		`"ip-address": "10.0.1.10"`,
+		`"hostname": "nas"`,
+		`"subnet6": [`,
+		`"ip-addresses": [`,
+	} {
+		if !strings.Contains(kea, want) {
+			t.Errorf("Kea output missing %s:\n%s", want, kea)
+		}
+	}
+	if strings.Contains(kea, "10.0.1.20") {
+		t.Error("reservations without a MAC address are not leases")
+	}
+	// Subnet IDs follow the pool, not its position in the export.
+	keaV6, err := RenderDHCPLeases(pools[1:], domain.DHCPFormatKea)
+	if err != nil {
+		t.Fatalf("RenderDHCPLeases(kea) error: %v", err)
+	}
+	id := fmt.Sprintf(`"id": %d`, keaSubnetID(pools[1].Prefix, map[uint32]bool{}))
+	if !strings.Contains(kea, id) || !strings.Contains(keaV6, id) {
+		t.Errorf("expected the IPv6 subnet to keep %s:\n%s\n%s", id, kea, keaV6)
+	}
+	if used := map[uint32]bool{}; keaSubnetID(pools[0].Prefix, used) == keaSubnetID(pools[0].Prefix, used) {
+		t.Error("a taken subnet ID must not be reused")
+	}
+
+	dnsmasq, _ := RenderDHCPLeases(pools, domain.DHCPFormatDnsmasq)
+	want := "# 10.0.1.0/24 (LAN)\ndhcp-host=00:11:22:33:44:55,10.0.1.10,nas\n\n# fd00::/64 (LAN6)\ndhcp-host=00:11:22:33:44:66,[fd00::10],nas\n"
+	if dnsmasq != want {
+		t.Errorf("dnsmasq output =\n%s\nwant\n%s", dnsmasq, want)
+	}
+
+	if _, err := RenderDHCPLeases(pools, domain.DHCPFormatDhcpd); !errors.Is(err, ErrDhcpdMixedFamilies) {
+		t.Errorf("expected dhcpd to refuse mixed address families, got %v", err)
+	}
+	dir := t.TempDir()
+	if err := WriteDhcpdFiles(filepath.Join(dir, "dhcpd.conf"), pools); err != nil {
+		t.Fatalf("WriteDhcpdFiles() error: %v", err)
+	}
+	for file, wants := range map[string][]string{
+		"dhcpd.conf": {
+			"subnet 10.0.1.0 netmask 255.255.255.0 {",
+			"  host nas {\n    hardware ethernet 00:11:22:33:44:55;\n    fixed-address 10.0.1.10;\n    option host-name \"nas\";\n  }",
+		},
+		"dhcpd6.conf": {
+			"subnet6 fd00::/64 {",
+			"  host nas {",
+			"fixed-address6 fd00::10;",
+		},
+	} {
+		content, err := os.ReadFile(filepath.Join(dir, file))
+		if err != nil {
+			t.Fatalf("read %s: %v", file, err)
+		}
+		for _, want := range wants {
+			if !strings.Contains(string(content), want) {
+				t.Errorf("%s missing %q:\n%s", file, want, content)
+			}
+		}
+		if strings.Contains(string(content), "subnet ") == strings.Contains(string(content), "subnet6 ") {
+			t.Errorf("%s must hold a single address family:\n%s", file, content)
+		}
+	}
+
+	if _, err := RenderDHCPLeases(pools, "isc"); err == nil {
+		t.Error("expected error for unknown format")
+	}
+
+	c.Config.DHCPExports = []domain.ExportTarget{
+		{Format: domain.DHCPFormatDnsmasq, Path: "dhcp/static.conf"},
+		{Format: domain.DHCPFormatDhcpd, Path: "dhcp/hosts.conf"},
+	}
+	if err := WriteDHCPExports(dir, c); err != nil {
+		t.Fatalf("WriteDHCPExports() error: %v", err)
+	}
+	if got, err := os.ReadFile(filepath.Join(dir, "dhcp", "static.conf")); err != nil || string(got) != want {
+		t.Errorf("written export = %q, %v", got, err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "dhcp", "hosts6.conf")); err != nil {
+		t.Errorf("dhcpd IPv6 export not written: %v", err)
+	}
+}
+
+func TestDNSZones(t *testing.T) {
+	c := domain.NewCatalog()
+	c.Put(&domain.StaticFolder{Base: domain.Base{ID: domain.FolderNetworks}, Index: 0})
+	c.Put(&domain.StaticFolder{Base: domain.Base{ID: domain.FolderDNS}, Index: 5})
+	lan := &domain.Network{
+		Base:           domain.Base{ID: "10.0.1.0/24", ParentPath: domain.FolderNetworks},
+		AllocationMode: domain.AllocationModeHosts,
+	}
+	c.Put(lan)
+	v6 := &domain.Network{
+		Base:           domain.Base{ID: "fd00::/64", ParentPath: domain.FolderNetworks},
+		AllocationMode: domain.AllocationModeHosts,
+	}
+	c.Put(v6)
+	ns := &domain.IP{Base: domain.Base{ID: "10.0.1.2", ParentPath: lan.GetPath()}, DisplayName: "ns1.example.com"}
+	c.Put(ns)
+	nas := &domain.IP{Base: domain.Base{ID: "fd00::10", ParentPath: v6.GetPath()}, DisplayName: "nas"}
+	c.Put(nas)
+	c.Put(&domain.DNSRecord{Base: domain.Base{ID: "nas.lab.example.com", ParentPath: domain.FolderDNS}, ReservedIPPath: nas.GetPath()})
+	c.Put(&domain.DNSRecord{Base: domain.Base{ID: "www.example.com", ParentPath: domain.FolderDNS}, RecordType: "CNAME", RecordValue: "nas.lab.example.com"})
+	c.Put(&domain.DNSRecord{Base: domain.Base{ID: "example.com", ParentPath: domain.FolderDNS}, RecordType: "MX", RecordValue: "10 mail.example.net"})
+	c.Put(&domain.DNSRecord{Base: domain.Base{ID: "example.com", ParentPath: domain.FolderDNS + " -> txt"}, RecordType: "TXT", RecordValue: "v=spf1 -all"})
+	c.Put(&domain.DNSRecord{Base: domain.Base{ID: "_sip._tcp.example.com", ParentPath: domain.FolderDNS}, RecordType: "SRV", RecordValue: "10 5 5060 ns1.example.com"})
+
+	zones, outside, err := DNSZones(c)
+	if err != nil {
+		t.Fatalf("DNSZones() error: %v", err)
+	}
+	if len(zones) != 1 || zones[0].Name != "example.com" || len(outside) != 0 {
+		t.Fatalf("DNSZones() = %+v, %v; want a single example.com zone", zones, outside)
+	}
+
+	zone := RenderBINDZone(zones[0], c.Config.DNSDefaults(), 42)
+	for _, want := range []string{
+		"$ORIGIN example.com.\n$TTL 3600\n",
+		"@\tIN\tSOA\tns1.example.com. hostmaster.example.com. (\n\t\t\t42 ; serial\n",
+		"@\tIN\tNS\tns1.example.com.\n",
+		"@\tIN\tMX\t10 mail.example.net.\n",
+		"@\tIN\tTXT\t\"v=spf1 -all\"\n",
+		"ns1\tIN\tA\t10.0.1.2\n",
+		"nas.lab\tIN\tAAAA\tfd00::10\n",
+		"www\tIN\tCNAME\tnas.lab.example.com.\n",
+		"_sip._tcp\tIN\tSRV\t10 5 5060 ns1.example.com.\n",
+	} {
+		if !strings.Contains(zone, want) {
+			t.Errorf("zone file missing %q:\n%s", want, zone)
+		}
+	}
+	if strings.Contains(zone, "\tnas\t") {
+		t.Error("single-label reservation names must not be exported")
+	}
+
+	// Configured zones split the records; names outside them are reported.
+	c.Config.DNS = &domain.DNSConfig{Zones: []string{"lab.example.com"}, NameServers: []string{"ns.example.net"}}
+	zones, outside, err = DNSZones(c)
+	if err != nil || len(zones) != 1 || zones[0].Name != "lab.example.com" || len(outside) != 4 {
+		t.Errorf("DNSZones() with configured zones = %+v, %v, %v", zones, outside, err)
+	}
+
+	c.Config.DNS = &domain.DNSConfig{NameServers: []string{"ns2.example.com"}}
+	c.Put(&domain.DNSRecord{Base: domain.Base{ID: "www.example.com", ParentPath: domain.FolderDNS + " -> a"}, RecordType: "A", RecordValue: "fd00::1"})
+	_, _, err = DNSZones(c)
+	for _, want := range []string{"CNAME and other data", "must be an IPv4 address", "NS ns2.example.com has no address records"} {
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("DNSZones() error = %v, want %q", err, want)
+		}
+	}
+
+	// Names directly under a single-label domain share its zone, and a
+	// zone without glue for its default name server only warns.
+	zones, outside = GroupZones([]ResourceRecord{
+		{Name: "nas.home", Type: "A", Value: "10.0.1.10"},
+		{Name: "gateway.iot.home", Type: "A", Value: "10.0.2.1"},
+		{Name: "gateway.az-a.demo", Type: "A", Value: "10.0.3.1"},
+	}, nil)
+	if len(zones) != 2 || zones[0].Name != "az-a.demo" || zones[1].Name != "home" || len(zones[1].Records) != 2 || len(outside) != 0 {
+		t.Fatalf("GroupZones() = %+v, %v; want az-a.demo and home", zones, outside)
+	}
+	d := domain.Config{}.DNSDefaults()
+	if err := CheckZone(zones[1], d); err != nil {
+		t.Errorf("CheckZone() error: %v", err)
+	}
+	if warnings := ZoneWarnings(zones[1], d); len(warnings) != 1 || !strings.Contains(warnings[0], "default NS ns1.home has no address records") {
+		t.Errorf("ZoneWarnings() = %v", warnings)
+	}
+}
+
+func TestDNSZoneEntities(t *testing.T) {
+	c := domain.NewCatalog()
+	c.Put(&domain.StaticFolder{Base: domain.Base{ID: domain.FolderNetworks}, Index: 0})
+	c.Put(&domain.StaticFolder{Base: domain.Base{ID: domain.FolderDNS}, Index: 5})
+	parent := &domain.DNSZone{Base: domain.Base{ID: "example.com", ParentPath: domain.FolderDNS}, TTL: 600, Contact: "admin@example.com", NameServers: []string{"ns1.example.com"}, Description: "Public zone"}
+	lab := &domain.DNSZone{Base: domain.Base{ID: "lab.example.com", ParentPath: domain.FolderDNS}, NameServers: []string{"ns.lab.example.com"}}
+	c.Put(parent)
+	c.Put(lab)
+	c.Put(&domain.DNSRecord{Base: domain.Base{ID: "ns1.example.com", ParentPath: parent.GetPath()}, RecordType: "A", RecordValue: "10.0.0.2"})
+	c.Put(&domain.DNSRecord{Base: domain.Base{ID: "www.example.com", ParentPath: parent.GetPath()}, RecordType: "A", RecordValue: "10.0.0.80", TTL: 60})
+	c.Put(&domain.DNSRecord{Base: domain.Base{ID: "ns.lab.example.com", ParentPath: lab.GetPath()}, RecordType: "A", RecordValue: "10.0.1.2"})
+
+	zones, outside, err := DNSZones(c)
+	if err != nil {
+		t.Fatalf("DNSZones() error: %v", err)
+	}
+	if len(zones) != 2 || zones[0].Name != "example.com" || zones[1].Name != "lab.example.com" || len(outside) != 0 {
+		t.Fatalf("DNSZones() = %+v, %v; want example.com and lab.example.com", zones, outside)
+	}
+
+	zone := RenderBINDZone(zones[0], c.Config.DNSDefaults(), 1)
+	for _, want := range []string{
+		"$TTL 600\n",
+		"@\tIN\tSOA\tns1.example.com. admin.example.com. (",
+		"www\t60\tIN\tA\t10.0.0.80\n",
+		"lab\tIN\tNS\tns.lab.example.com.\n",
+		"ns.lab\tIN\tA\t10.0.1.2\n",
+	} {
+		if !strings.Contains(zone, want) {
+			t.Errorf("zone file missing %q:\n%s", want, zone)
+		}
+	}
+	if zone := RenderBINDZone(zones[1], c.Config.DNSDefaults(), 1); !strings.Contains(zone, "$TTL 3600\n") || !strings.Contains(zone, "@\tIN\tNS\tns.lab.example.com.\n") {
+		t.Errorf("lab zone must use the default TTL and its own name server:\n%s", zone)
+	}
+
+	md, err := RenderMarkdown(c)
+	if err != nil {
+		t.Fatalf("RenderMarkdown() error: %v", err)
+	}
+	for _, want := range []string{"## DNS Zones", "### example.com", "> Public zone", "| 600 | admin@example.com | ns1.example.com |", "| `www.example.com` | A | `10.0.0.80` | 60 |"} {
+		if !strings.Contains(md, want) {
+			t.Errorf("markdown missing %q:\n%s", want, md)
+		}
+	}
+}
+
+func TestHostAliases(t *testing.T) {
+	c := domain.NewCatalog()
+	c.Put(&domain.StaticFolder{Base: domain.Base{ID: domain.FolderNetworks}, Index: 0})
+	c.Put(&domain.StaticFolder{Base: domain.Base{ID: domain.FolderDNS}, Index: 5})
+	v4 := &domain.Network{Base: domain.Base{ID: "10.0.0.0/24", ParentPath: domain.FolderNetworks}, AllocationMode: domain.AllocationModeHosts}
+	v6 := &domain.Network{Base: domain.Base{ID: "fd00::/64", ParentPath: domain.FolderNetworks}, AllocationMode: domain.AllocationModeHosts}
+	c.Put(v4)
+	c.Put(v6)
+	c.Put(&domain.IP{Base: domain.Base{ID: "10.0.0.10", ParentPath: v4.GetPath()}, DisplayName: "nas"})
+	c.Put(&domain.IP{Base: domain.Base{ID: "fd00::10", ParentPath: v6.GetPath()}, DisplayName: "nas"})
+	c.Put(&domain.DNSRecord{Base: domain.Base{ID: "nas.example.com", ParentPath: domain.FolderDNS}, ReservedHost: "nas"})
+
+	records, err := DNSResourceRecords(c)
+	if err != nil {
+		t.Fatalf("DNSResourceRecords() error: %v", err)
+	}
+	var got []string
+	for _, record := range records {
+		got = append(got, record.Name+" "+record.Type+" "+record.Value)
+	}
+	if strings.Join(got, "\n") != "nas.example.com A 10.0.0.10\nnas.example.com AAAA fd00::10" {
+		t.Errorf("DNSResourceRecords() = %v, want an A and an AAAA record", got)
+	}
+
+	md, err := RenderMarkdown(c)
+	if err != nil {
+		t.Fatalf("RenderMarkdown() error: %v", err)
+	}
+	if !strings.Contains(md, "| `nas.example.com` | Host | `10.0.0.10` (nas)<br>`fd00::10` (nas) |") {
+		t.Errorf("markdown DNS table must list both addresses:\n%s", md)
+	}
+}
+
+func TestWriteDNSExports(t *testing.T) {
+	c := domain.NewCatalog()
+	c.Put(&domain.StaticFolder{Base: domain.Base{ID: domain.FolderDNS}, Index: 5})
+	c.Put(&domain.DNSRecord{Base: domain.Base{ID: "ns1.example.com", ParentPath: domain.FolderDNS}, RecordType: "A", RecordValue: "10.0.0.2"})
+	c.Config.DNSExports = []domain.ExportTarget{{Format: domain.DNSFormatBIND, Path: "zones"}}
+
+	dir := t.TempDir()
+	if err := WriteDNSExports(dir, c); err != nil {
+		t.Fatalf("WriteDNSExports() error: %v", err)
+	}
+	path := filepath.Join(dir, "zones", "example.com.zone")
+	first, err := os.ReadFile(path)
+	if err != nil || !strings.Contains(string(first), "ns1\tIN\tA\t10.0.0.2") {
+		t.Fatalf("zone file = %q, %v", first, err)
+	}
+
+	// An unchanged zone keeps its file and serial.
+	stale := strings.Join(strings.SplitAfterN(string(first), "\n", 5)[:4], "") + "\t\t\t1 ; serial\n" + strings.SplitAfterN(string(first), "\n", 6)[5]
+	if err := os.WriteFile(path, []byte(stale), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteDNSExports(dir, c); err != nil {
+		t.Fatalf("WriteDNSExports() error: %v", err)
+	}
+	if got, _ := os.ReadFile(path); string(got) != stale {
+		t.Errorf("unchanged zone was rewritten:\n%s", got)
+	}
+
+	c.Put(&domain.DNSRecord{Base: domain.Base{ID: "bad.example.com", ParentPath: domain.FolderDNS}, RecordType: "MX", RecordValue: "mail"})
+	if err := WriteDNSExports(dir, c); err == nil {
+		t.Error("expected zone check error")
+	}
+}
+
+func TestSave(t *testing.T) {
+	dir := t.TempDir()
+	c, err := store.Load(dir)
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	c.Put(&domain.DNSRecord{Base: domain.Base{ID: "ns1.example.com", ParentPath: domain.FolderDNS}, RecordType: "A", RecordValue: "10.0.0.2"})
+	c.Config.DHCPExports = []domain.ExportTarget{{Format: domain.DHCPFormatDnsmasq, Path: "dhcp/static.conf"}}
+	c.Config.DNSExports = []domain.ExportTarget{{Format: domain.DNSFormatBIND, Path: "zones"}}
+
+	if err := Save(dir, c); err != nil {
+		t.Fatalf("Save() error: %v", err)
+	}
+	for _, path := range []string{".ez-ipam/dns/ns1_example_com.yaml", "EZ-IPAM.md", "dhcp/static.conf", "zones/example.com.zone"} {
+		if _, err := os.Stat(filepath.Join(dir, path)); err != nil {
+			t.Errorf("Save() did not write %s: %v", path, err)
+		}
+	}
+
+	c.Put(&domain.DNSRecord{Base: domain.Base{ID: "bad.example.com", ParentPath: domain.FolderDNS}, RecordType: "MX", RecordValue: "mail"})
+	if err := Save(dir, c); err == nil || !strings.HasPrefix(err.Error(), "writing DNS exports: ") {
+		t.Errorf("Save() error = %v, want the DNS export error", err)
+	}
+}
+
+func TestReverseZones(t *testing.T) {
+	c := domain.NewCatalog()
+	c.Put(&domain.StaticFolder{Base: domain.Base{ID: domain.FolderNetworks}, Index: 0})
+	c.Put(&domain.StaticFolder{Base: domain.Base{ID: domain.FolderDNS}, Index: 5})
+	lan := &domain.Network{
+		Base:           domain.Base{ID: "10.0.0.0/23", ParentPath: domain.FolderNetworks},
+		AllocationMode: domain.AllocationModeHosts,
+		DHCPOptions:    &domain.DHCPOptions{DomainName: "lan.example.com"},
+	}
+	c.Put(lan)
+	dmz := &domain.Network{
+		Base:           domain.Base{ID: "192.168.1.64/26", ParentPath: domain.FolderNetworks},
+		AllocationMode: domain.AllocationModeHosts,
+	}
+	c.Put(dmz)
+	v6 := &domain.Network{
+		Base:           domain.Base{ID: "fd00:0:0:10::/62", ParentPath: domain.FolderNetworks},
+		AllocationMode: domain.AllocationModeHosts,
+	}
+	c.Put(v6)
+	nas := &domain.IP{Base: domain.Base{ID: "10.0.1.10", ParentPath: lan.GetPath()}, DisplayName: "nas"}
+	c.Put(nas)
+	c.Put(&domain.IP{Base: domain.Base{ID: "10.0.0.20", ParentPath: lan.GetPath()}, DisplayName: "Printer Room 2"})
+	c.Put(&domain.IP{Base: domain.Base{ID: "192.168.1.70", ParentPath: dmz.GetPath()}, DisplayName: "ns1.example.com"})
+	web := &domain.IP{Base: domain.Base{ID: "fd00:0:0:11::5", ParentPath: v6.GetPath()}, DisplayName: "web"}
+	c.Put(web)
+	c.Put(&domain.DNSRecord{Base: domain.Base{ID: "files.example.com", ParentPath: domain.FolderDNS}, ReservedIPPath: nas.GetPath()})
+	c.Put(&domain.DNSRecord{Base: domain.Base{ID: "www.example.com", ParentPath: domain.FolderDNS}, ReservedIPPath: web.GetPath()})
+
+	zones, conflicts, err := ReverseZones(c)
+	if err != nil {
+		t.Fatalf("ReverseZones() error: %v", err)
+	}
+	got := map[string]string{}
+	for _, zone := range zones {
+		got[zone.Name] = RenderBINDZone(zone, c.Config.DNSDefaults(), 1)
+	}
+	for name, want := range map[string]string{
+		"1.0.10.in-addr.arpa":                      "10\tIN\tPTR\tnas.lan.example.com.\n",
+		"64/26.1.168.192.in-addr.arpa":             "70\tIN\tPTR\tns1.example.com.\n",
+		"1.168.192.in-addr.arpa":                   "64/26\tIN\tNS\tns1.example.com.\n",
+		"1.1.0.0.0.0.0.0.0.0.0.0.0.0.d.f.ip6.arpa": "5.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0\tIN\tPTR\twww.example.com.\n",
+	} {
+		if !strings.Contains(got[name], want) {
+			t.Errorf("zone %s missing %q:\n%s", name, want, got[name])
+		}
+	}
+	if len(zones) != 4 {
+		t.Errorf("expected 4 reverse zones, got %v", got)
+	}
+	if strings.Contains(got["0.0.10.in-addr.arpa"], "Printer") {
+		t.Error("names that are not hostnames must not get PTR records")
+	}
+	if !strings.Contains(got["1.168.192.in-addr.arpa"], "70\tIN\tCNAME\t70.64/26.1.168.192.in-addr.arpa.\n") ||
+		!strings.Contains(got["1.168.192.in-addr.arpa"], "127\tIN\tCNAME") || strings.Contains(got["1.168.192.in-addr.arpa"], "128\tIN") {
+		t.Error("RFC 2317 CNAMEs must cover exactly the delegated block")
+	}
+	if len(conflicts) != 1 || conflicts[0].Address != "10.0.1.10" || conflicts[0].Chosen != "nas.lan.example.com" ||
+		strings.Join(conflicts[0].Names, ",") != "files.example.com,nas.lan.example.com" {
+		t.Errorf("conflicts = %+v", conflicts)
+	}
+	if BINDZoneFileName("64/26.1.168.192.in-addr.arpa") != "64-26.1.168.192.in-addr.arpa.zone" {
+		t.Error("RFC 2317 zone file names must not contain a slash")
+	}
+
+	// With several forward zones the name servers come from the DNS
+	// settings, never from whichever zone sorts first.
+	c.Put(&domain.DNSRecord{Base: domain.Base{ID: "www.aaa.com", ParentPath: domain.FolderDNS}, RecordType: "A", RecordValue: "10.0.0.80"})
+	c.Config.DNS = &domain.DNSConfig{Zones: []string{"example.com", "aaa.com"}}
+	zones, _, err = ReverseZones(c)
+	if err != nil {
+		t.Fatalf("ReverseZones() error: %v", err)
+	}
+	for _, zone := range zones {
+		if rendered := RenderBINDZone(zone, c.Config.DNSDefaults(), 1); !strings.Contains(rendered, "@\tIN\tNS\tns1.example.com.\n") {
+			t.Errorf("zone %s must be served by ns1.example.com:\n%s", zone.Name, rendered)
+		}
+	}
+
+	c.Put(&domain.DNSRecord{Base: domain.Base{ID: "mail.example.com", ParentPath: domain.FolderDNS}, RecordType: "A", RecordValue: "fd00::1"})
+	if _, _, err := ReverseZones(c); err == nil || !strings.Contains(err.Error(), "must be an IPv4 address") {
+		t.Errorf("ReverseZones() must report forward zone errors, got %v", err)
+	}
+}
+
+func TestRenderLocalDNS(t *testing.T) {
+	c := domain.NewCatalog()
+	c.Put(&domain.StaticFolder{Base: domain.Base{ID: domain.FolderNetworks}, Index: 0})
+	c.Put(&domain.StaticFolder{Base: domain.Base{ID: domain.FolderDNS}, Index: 5})
+	lan := &domain.Network{
+		Base:           domain.Base{ID: "10.0.0.0/24", ParentPath: domain.FolderNetworks},
+		AllocationMode: domain.AllocationModeHosts,
+		DHCPOptions:    &domain.DHCPOptions{DomainName: "lan"},
+	}
+	c.Put(lan)
+	nas := &domain.IP{Base: domain.Base{ID: "10.0.0.10", ParentPath: lan.GetPath()}, DisplayName: "nas"}
+	c.Put(nas)
+	c.Put(&domain.DNSRecord{Base: domain.Base{ID: "files.example.com", ParentPath: domain.FolderDNS}, ReservedIPPath: nas.GetPath()})
+	c.Put(&domain.DNSRecord{Base: domain.Base{ID: "share.example.com", ParentPath: domain.FolderDNS}, RecordType: "CNAME", RecordValue: "files.example.com"})
+	c.Put(&domain.DNSRecord{Base: domain.Base{ID: "docs.example.com", ParentPath: domain.FolderDNS}, RecordType: "CNAME", RecordValue: "share.example.com"})
+	c.Put(&domain.DNSRecord{Base: domain.Base{ID: "cdn.example.com", ParentPath: domain.FolderDNS}, RecordType: "CNAME", RecordValue: "example.net"})
+	c.Put(&domain.DNSRecord{Base: domain.Base{ID: "example.com", ParentPath: domain.FolderDNS}, RecordType: "TXT", RecordValue: "v=spf1 -all"})
+
+	records, err := LocalDNSRecords(c)
+	if err != nil {
+		t.Fatalf("LocalDNSRecords() error: %v", err)
+	}
+
+	unbound, skipped, err := RenderLocalDNS(records, domain.DNSFormatUnbound, 300)
+	if err != nil || len(skipped) != 0 {
+		t.Fatalf("RenderLocalDNS(unbound) = %v, %v", skipped, err)
+	}
+	for _, want := range []string{
+		`local-data: "files.example.com. 300 IN A 10.0.0.10"`,
+		`local-data: "nas.lan. 300 IN A 10.0.0.10"`,
+		`local-data: "docs.example.com. 300 IN CNAME share.example.com."`,
+		`local-data: "example.com. 300 IN TXT \"v=spf1 -all\""`,
+	} {
+		if !strings.Contains(unbound, want) {
+			t.Errorf("Unbound output missing %s:\n%s", want, unbound)
+		}
+	}
+
+	hosts, skipped, _ := RenderLocalDNS(records, domain.DNSFormatHosts, 300)
+	if !strings.Contains(hosts, "10.0.0.10\tfiles.example.com nas.lan docs.example.com share.example.com\n") {
+		t.Errorf("hosts output does not flatten CNAMEs:\n%s", hosts)
+	}
+	if strings.Contains(hosts, "spf1") || len(skipped) != 1 || skipped[0] != "cdn.example.com" {
+		t.Errorf("expected only cdn.example.com to be skipped, got %v:\n%s", skipped, hosts)
+	}
+	if coredns, _, _ := RenderLocalDNS(records, domain.DNSFormatCoreDNS, 300); coredns != hosts {
+		t.Errorf("CoreDNS hosts file should match /etc/hosts:\n%s", coredns)
+	}
+
+	pihole, _, _ := RenderLocalDNS(records, domain.DNSFormatPihole, 300)
+	if !strings.Contains(pihole, "10.0.0.10 files.example.com\n10.0.0.10 nas.lan\n10.0.0.10 docs.example.com\n") {
+		t.Errorf("unexpected Pi-hole output:\n%s", pihole)
+	}
+
+	if _, _, err := RenderLocalDNS(records, domain.DNSFormatBIND, 300); err == nil {
+		t.Error("expected error for the bind format")
+	}
+
+	dir := t.TempDir()
+	c.Config.DNSExports = []domain.ExportTarget{{Format: domain.DNSFormatPihole, Path: "dns/custom.list"}}
+	if err := WriteDNSExports(dir, c); err != nil {
+		t.Fatalf("WriteDNSExports() error: %v", err)
+	}
+	if got, err := os.ReadFile(filepath.Join(dir, "dns", "custom.list")); err != nil || string(got) != pihole {
+		t.Errorf("written export = %q, %v", got, err)
+	}
+}
+
+func TestInventory(t *testing.T) {
+	c := domain.NewCatalog()
+	for i, folder := range []string{domain.FolderNetworks, domain.FolderZones, domain.FolderVLANs, domain.FolderSSIDs, domain.FolderEquipment, domain.FolderDNS} {
+		c.Put(&domain.StaticFolder{Base: domain.Base{ID: folder}, Index: i})
+	}
+	c.Put(&domain.VLAN{Base: domain.Base{ID: "10", ParentPath: domain.FolderVLANs}, DisplayName: "mgmt"})
+	c.Put(&domain.Zone{Base: domain.Base{ID: "Trusted", ParentPath: domain.FolderZones}, DisplayName: "Trusted", VLANIDs: []int{10}})
+	site := &domain.Network{Base: domain.Base{ID: "10.0.0.0/16", ParentPath: domain.FolderNetworks}, AllocationMode: domain.AllocationModeSubnets, DisplayName: "Site"}
+	pool := &domain.Network{Base: domain.Base{ID: "10.0.0.0/24", ParentPath: site.GetPath()}, AllocationMode: domain.AllocationModeHosts, VLANID: 10}
+	c.Put(site)
+	c.Put(pool)
+	nas := &domain.IP{Base: domain.Base{ID: "10.0.0.10", ParentPath: pool.GetPath()}, DisplayName: "nas"}
+	c.Put(nas)
+	c.Put(&domain.DNSRecord{Base: domain.Base{ID: "files.home", ParentPath: domain.FolderDNS}, ReservedIPPath: nas.GetPath()})
+	c.Put(&domain.DNSRecord{Base: domain.Base{ID: "storage.home", ParentPath: domain.FolderDNS}, ReservedHost: nas.DisplayName})
+	sw := &domain.Equipment{Base: domain.Base{ID: "Switch-1", ParentPath: domain.FolderEquipment}, DisplayName: "Switch-1"}
+	c.Put(sw)
+	c.Put(&domain.Port{Base: domain.Base{ID: "1", ParentPath: sw.GetPath()}, PortType: "RJ45", Speed: "1G", LAGGroup: 1, LAGMode: "802.3ad", NativeVLANID: 10, TaggedVLANMode: domain.TaggedVLANModeCustom, TaggedVLANIDs: []int{10}})
+	c.Put(&domain.Port{Base: domain.Base{ID: "2", ParentPath: sw.GetPath()}, PortType: "RJ45", Speed: "1G", LAGGroup: 1, LAGMode: "802.3ad"})
+
+	inv := BuildInventory(c)
+	if len(inv.Networks) != 2 || inv.Networks[1].ParentCIDR != "10.0.0.0/16" || inv.Networks[1].Allocation != "Host Pool" || inv.Networks[1].VLANName != "mgmt" {
+		t.Errorf("Networks = %+v", inv.Networks)
+	}
+	if len(inv.IPs) != 1 || inv.IPs[0].Network != "10.0.0.0/24" || inv.IPs[0].VLANName != "mgmt" {
+		t.Errorf("IPs = %+v", inv.IPs)
+	}
+	if len(inv.VLANs) != 1 || len(inv.VLANs[0].Zones) != 1 || inv.VLANs[0].Zones[0] != "Trusted" {
+		t.Errorf("VLANs = %+v, want VLAN 10 in Trusted", inv.VLANs)
+	}
+	if len(inv.Ports) != 2 || inv.Ports[1].NativeVLANName != "mgmt" || inv.Ports[1].TaggedMode != "Custom" {
+		t.Errorf("Ports = %+v, want the LAG member to show the master's VLANs", inv.Ports)
+	}
+	if len(inv.DNSRecords) != 2 || inv.DNSRecords[0].Type != "ALIAS" || inv.DNSRecords[0].Value != "10.0.0.10" {
+		t.Errorf("DNSRecords = %+v", inv.DNSRecords)
+	} else if host := inv.DNSRecords[1]; host.Type != "HOST" || host.Value != "10.0.0.10" || host.Host != nas.DisplayName {
+		t.Errorf("host alias = %+v, want its resolved address", host)
+	}
+
+	table, err := RenderInventory(inv, InventoryFormatCSV, "ports")
+	if err != nil {
+		t.Fatalf("RenderInventory() error: %v", err)
+	}
+	if want := "Equipment -> Switch-1 -> 2,Switch-1,2,,false,RJ45,1G,,1,802.3ad,10,mgmt,Custom,10,,\n"; !strings.HasSuffix(table, want) {
+		t.Errorf("ports CSV = %q, want suffix %q", table, want)
+	}
+	doc, err := RenderInventory(inv, InventoryFormatJSON, "")
+	if err != nil || !strings.Contains(doc, `"path": "Networks -> 10.0.0.0/16"`) || !strings.Contains(doc, `"ssids": []`) {
+		t.Errorf("JSON = %s, %v", doc, err)
+	}
+	doc, err = RenderInventory(inv, InventoryFormatYAML, "vlans")
+	if err != nil || !strings.Contains(doc, "- Trusted") {
+		t.Errorf("YAML = %s, %v", doc, err)
+	}
+	if _, err := RenderInventory(inv, InventoryFormatCSV, ""); err == nil {
+		t.Error("expected error for csv without a table")
+	}
+	if _, err := RenderInventory(inv, InventoryFormatJSON, "routes"); err == nil {
+		t.Error("expected error for an unknown table")
+	}
 }
@@ -0,0 +1,383 @@
+package export
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/plumber-cd/ez-ipam/internal/domain"
+	"sigs.k8s.io/yaml"
+)
+
+// Inventory export formats.
+const (
+	InventoryFormatCSV  = "csv"
+	InventoryFormatJSON = "json"
+	InventoryFormatYAML = "yaml"
+)
+
+// InventoryFormats lists the supported inventory export formats.
+var InventoryFormats = []string{InventoryFormatCSV, InventoryFormatJSON, InventoryFormatYAML}
+
+// InventoryTables lists the inventory tables in document order; the names
+// are the JSON keys and the CSV file names.
+var InventoryTables = []string{"networks", "ips", "vlans", "zones", "equipment", "ports", "ssids", "dns_zones", "dns_records"}
+
+// Inventory is every catalog item as flat rows with the fields other tools
+// would otherwise have to resolve from the hierarchy.
+type Inventory struct {
+	Networks   []NetworkRow   `json:"networks"`
+	IPs        []IPRow        `json:"ips"`
+	VLANs      []VLANRow      `json:"vlans"`
+	Zones      []ZoneRow      `json:"zones"`
+	Equipment  []EquipmentRow `json:"equipment"`
+	Ports      []PortRow      `json:"ports"`
+	SSIDs      []SSIDRow      `json:"ssids"`
+	DNSZones   []DNSZoneRow   `json:"dns_zones"`
+	DNSRecords []DNSRecordRow `json:"dns_records"`
+}
+
+// NetworkRow is a network. ParentCIDR is empty for top-level networks.
+type NetworkRow struct {
+	Path        string `json:"path"`
+	CIDR        string `json:"cidr"`
+	ParentCIDR  string `json:"parent_cidr"`
+	Allocation  string `json:"allocation"`
+	Name        string `json:"name"`
+	VLANID      int    `json:"vlan_id"`
+	VLANName    string `json:"vlan_name"`
+	Gateway     string `json:"gateway"`
+	Description string `json:"description"`
+}
+
+// IPRow is a reserved IP with the VLAN of its Host Pool.
+type IPRow struct {
+	Path        string `json:"path"`
+	Address     string `json:"address"`
+	Network     string `json:"network"`
+	Name        string `json:"name"`
+	MAC         string `json:"mac"`
+	VLANID      int    `json:"vlan_id"`
+	VLANName    string `json:"vlan_name"`
+	Description string `json:"description"`
+}
+
+// VLANRow is a VLAN with the names of the zones it belongs to.
+type VLANRow struct {
+	Path        string   `json:"path"`
+	ID          int      `json:"id"`
+	Name        string   `json:"name"`
+	Zones       []string `json:"zones"`
+	Description string   `json:"description"`
+}
+
+// ZoneRow is a security zone.
+type ZoneRow struct {
+	Path        string `json:"path"`
+	Name        string `json:"name"`
+	VLANIDs     []int  `json:"vlan_ids"`
+	Description string `json:"description"`
+}
+
+// EquipmentRow is a piece of equipment.
+type EquipmentRow struct {
+	Path        string `json:"path"`
+	Name        string `json:"name"`
+	Model       string `json:"model"`
+	Description string `json:"description"`
+}
+
+// PortRow is a port. The VLAN fields are the effective settings, taken from
+// the LAG master for LAG members.
+type PortRow struct {
+	Path           string `json:"path"`
+	Equipment      string `json:"equipment"`
+	Port           int    `json:"port"`
+	Name           string `json:"name"`
+	Disabled       bool   `json:"disabled"`
+	Type           string `json:"type"`
+	Speed          string `json:"speed"`
+	PoE            string `json:"poe"`
+	LAGGroup       int    `json:"lag_group"`
+	LAGMode        string `json:"lag_mode"`
+	NativeVLANID   int    `json:"native_vlan_id"`
+	NativeVLANName string `json:"native_vlan_name"`
+	TaggedMode     string `json:"tagged_mode"`
+	TaggedVLANIDs  []int  `json:"tagged_vlan_ids"`
+	ConnectedTo    string `json:"connected_to"`
+	Notes          string `json:"notes"`
+}
+
+// SSIDRow is a WiFi SSID.
+type SSIDRow struct {
+	Path        string `json:"path"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// DNSZoneRow is a DNS zone. A TTL of 0 means the configured default.
+type DNSZoneRow struct {
+	Path        string   `json:"path"`
+	Name        string   `json:"name"`
+	TTL         int      `json:"ttl"`
+	Contact     string   `json:"contact"`
+	NameServers []string `json:"name_servers"`
+	Description string   `json:"description"`
+}
+
+// DNSRecordRow is a DNS record. Aliases have the type ALIAS and their
+// target addresses, space-separated, as value; host aliases have the type
+// HOST, the addresses of every reservation named Host as value, and Host set.
+// A TTL of 0 means the zone default.
+type DNSRecordRow struct {
+	Path  string `json:"path"`
+	FQDN  string `json:"fqdn"`
+	Zone  string `json:"zone"`
+	Type  string `json:"type"`
+	Value string `json:"value"`
+	// Host is the reservation hostname a HOST alias follows.
+	Host        string `json:"host"`
+	TTL         int    `json:"ttl"`
+	Description string `json:"description"`
+}
+
+// BuildInventory returns the rows of every catalog item, in tree order.
+func BuildInventory(catalog *domain.Catalog) Inventory {
+	inv := Inventory{
+		Networks:   []NetworkRow{},
+		IPs:        []IPRow{},
+		VLANs:      []VLANRow{},
+		Zones:      []ZoneRow{},
+		Equipment:  []EquipmentRow{},
+		Ports:      []PortRow{},
+		SSIDs:      []SSIDRow{},
+		DNSZones:   []DNSZoneRow{},
+		DNSRecords: []DNSRecordRow{},
+	}
+	var walk func(parent domain.Item)
+	walk = func(parent domain.Item) {
+		for _, item := range catalog.GetChildren(parent) {
+			inv.add(catalog, parent, item)
+			walk(item)
+		}
+	}
+	walk(nil)
+	return inv
+}
+
+func (inv *Inventory) add(catalog *domain.Catalog, parent, item domain.Item) {
+	vlanName := func(id int) string {
+		if vlan := catalog.FindVLANByID(id); vlan != nil {
+			return vlan.DisplayName
+		}
+		return ""
+	}
+	switch v := item.(type) {
+	case *domain.Network:
+		row := NetworkRow{
+			Path:        v.GetPath(),
+			CIDR:        v.ID,
+			Allocation:  allocationName(v.AllocationMode),
+			Name:        v.DisplayName,
+			VLANID:      v.VLANID,
+			VLANName:    vlanName(v.VLANID),
+			Gateway:     v.Gateway,
+			Description: v.Description,
+		}
+		if p, ok := parent.(*domain.Network); ok {
+			row.ParentCIDR = p.ID
+		}
+		inv.Networks = append(inv.Networks, row)
+	case *domain.IP:
+		row := IPRow{
+			Path:        v.GetPath(),
+			Address:     v.ID,
+			Name:        v.DisplayName,
+			MAC:         v.MACAddress,
+			Description: v.Description,
+		}
+		if pool, ok := parent.(*domain.Network); ok {
+			row.Network = pool.ID
+			row.VLANID = pool.VLANID
+			row.VLANName = vlanName(pool.VLANID)
+		}
+		inv.IPs = append(inv.IPs, row)
+	case *domain.VLAN:
+		id, _ := strconv.Atoi(v.ID)
+		row := VLANRow{Path: v.GetPath(), ID: id, Name: v.DisplayName, Zones: []string{}, Description: v.Description}
+		zonesFolder := catalog.GetByParentAndDisplayID(nil, domain.FolderZones)
+		for _, child := range catalog.GetChildren(zonesFolder) {
+			if zone, ok := child.(*domain.Zone); ok && slices.Contains(zone.VLANIDs, id) {
+				row.Zones = append(row.Zones, zone.DisplayName)
+			}
+		}
+		inv.VLANs = append(inv.VLANs, row)
+	case *domain.Zone:
+		inv.Zones = append(inv.Zones, ZoneRow{Path: v.GetPath(), Name: v.DisplayName, VLANIDs: append([]int{}, v.VLANIDs...), Description: v.Description})
+	case *domain.Equipment:
+		inv.Equipment = append(inv.Equipment, EquipmentRow{Path: v.GetPath(), Name: v.DisplayName, Model: v.Model, Description: v.Description})
+	case *domain.Port:
+		native, mode, tagged := catalog.GetEffectivePortVLANSettings(v)
+		row := PortRow{
+			Path:           v.GetPath(),
+			Port:           v.Number(),
+			Name:           v.Name,
+			Disabled:       v.Disabled,
+			Type:           v.PortType,
+			Speed:          v.Speed,
+			PoE:            v.PoE,
+			LAGGroup:       v.LAGGroup,
+			LAGMode:        v.LAGMode,
+			NativeVLANID:   native,
+			NativeVLANName: vlanName(native),
+			TaggedMode:     string(mode),
+			TaggedVLANIDs:  append([]int{}, tagged...),
+			ConnectedTo:    v.ConnectedTo,
+			Notes:          v.DestinationNotes,
+		}
+		if equipment, ok := parent.(*domain.Equipment); ok {
+			row.Equipment = equipment.DisplayName
+		}
+		inv.Ports = append(inv.Ports, row)
+	case *domain.SSID:
+		inv.SSIDs = append(inv.SSIDs, SSIDRow{Path: v.GetPath(), Name: v.ID, Description: v.Description})
+	case *domain.DNSZone:
+		inv.DNSZones = append(inv.DNSZones, DNSZoneRow{
+			Path:        v.GetPath(),
+			Name:        v.ID,
+			TTL:         v.TTL,
+			Contact:     v.Contact,
+			NameServers: append([]string{}, v.NameServers...),
+			Description: v.Description,
+		})
+	case *domain.DNSRecord:
+		row := DNSRecordRow{Path: v.GetPath(), FQDN: v.ID, Type: v.RecordType, Value: v.RecordValue, TTL: v.TTL, Description: v.Description}
+		if zone := catalog.ZoneOf(v); zone != nil {
+			row.Zone = zone.ID
+		}
+		if v.IsAlias() {
+			var addresses []string
+			for _, ip := range catalog.AliasTargets(v) {
+				addresses = append(addresses, ip.ID)
+			}
+			row.Type, row.Value = "ALIAS", strings.Join(addresses, " ")
+			if v.ReservedHost != "" {
+				row.Type, row.Host = "HOST", v.ReservedHost
+			}
+		}
+		inv.DNSRecords = append(inv.DNSRecords, row)
+	}
+}
+
+func allocationName(mode domain.AllocationMode) string {
+	switch mode {
+	case domain.AllocationModeSubnets:
+		return "Subnet Container"
+	case domain.AllocationModeHosts:
+		return "Host Pool"
+	default:
+		return "Unallocated"
+	}
+}
+
+// table returns the rows of the named table.
+func (inv Inventory) table(name string) (any, error) {
+	v := reflect.ValueOf(inv)
+	for i := range v.NumField() {
+		if jsonName(v.Type().Field(i)) == name {
+			return v.Field(i).Interface(), nil
+		}
+	}
+	return nil, fmt.Errorf("unknown table %q: must be one of %s", name, strings.Join(InventoryTables, ", "))
+}
+
+// RenderInventory renders inv in format. table selects a single table and
+// is required for csv; json and yaml render the whole inventory when it is
+// empty.
+func RenderInventory(inv Inventory, format, table string) (string, error) {
+	var doc any = inv
+	if table != "" {
+		rows, err := inv.table(table)
+		if err != nil {
+			return "", err
+		}
+		doc = rows
+	}
+	switch format {
+	case InventoryFormatJSON:
+		var buf bytes.Buffer
+		enc := json.NewEncoder(&buf)
+		enc.SetEscapeHTML(false)
+		enc.SetIndent("", "  ")
+		err := enc.Encode(doc)
+		return buf.String(), err
+	case InventoryFormatYAML:
+		out, err := yaml.Marshal(doc)
+		return string(out), err
+	case InventoryFormatCSV:
+		if table == "" {
+			return "", fmt.Errorf("csv renders one table at a time: choose one of %s", strings.Join(InventoryTables, ", "))
+		}
+		return renderCSVTable(doc)
+	default:
+		return "", fmt.Errorf("unknown inventory format %q: must be one of %s", format, strings.Join(InventoryFormats, ", "))
+	}
+}
+
+// renderCSVTable renders a slice of row structs with a header of their JSON
+// field names. Lists are comma-separated and zero numbers are left empty.
+func renderCSVTable(rows any) (string, error) {
+	v := reflect.ValueOf(rows)
+	rowType := v.Type().Elem()
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	header := make([]string, rowType.NumField())
+	for i := range header {
+		header[i] = jsonName(rowType.Field(i))
+	}
+	if err := w.Write(header); err != nil {
+		return "", err
+	}
+	for i := range v.Len() {
+		row := v.Index(i)
+		record := make([]string, row.NumField())
+		for j := range record {
+			record[j] = csvValue(row.Field(j))
+		}
+		if err := w.Write(record); err != nil {
+			return "", err
+		}
+	}
+	w.Flush()
+	return buf.String(), w.Error()
+}
+
+func csvValue(v reflect.Value) string {
+	switch v.Kind() {
+	case reflect.Slice:
+		parts := make([]string, v.Len())
+		for i := range parts {
+			parts[i] = csvValue(v.Index(i))
+		}
+		return strings.Join(parts, ",")
+	case reflect.Int:
+		if v.Int() == 0 {
+			return ""
+		}
+		return strconv.FormatInt(v.Int(), 10)
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool())
+	default:
+		return v.String()
+	}
+}
+
+func jsonName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	return name
+}
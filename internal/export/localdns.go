@@ -0,0 +1,162 @@
+package export
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"net/netip"
+	"slices"
+	"strings"
+
+	"github.com/plumber-cd/ez-ipam/internal/domain"
+)
+
+// maxCNAMEChain bounds how many CNAMEs are followed when flattening.
+const maxCNAMEChain = 8
+
+// LocalDNSRecords returns the records for local resolvers: every record of
+// DNSResourceRecords plus the reservations with single-label hostnames,
+// qualified with the DHCP domain of their Host Pool when it has one. Each
+// record is checked on its own; there are no zones to check.
+func LocalDNSRecords(catalog *domain.Catalog) ([]ResourceRecord, error) {
+	records, err := DNSResourceRecords(catalog)
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range catalog.All() {
+		ip, ok := item.(*domain.IP)
+		if !ok || strings.Contains(ip.DisplayName, ".") {
+			continue
+		}
+		name := ptrTarget(ip.DisplayName, catalog.Get(ip.ParentPath))
+		if name == "" && domain.ValidateHostname(ip.DisplayName) == nil {
+			name = ip.DisplayName
+		}
+		if name != "" {
+			records = append(records, addressRecord(name, ip.ID, ip.GetPath()))
+		}
+	}
+	var errs []error
+	for _, record := range records {
+		if err := checkRecord(record); err != nil {
+			errs = append(errs, fmt.Errorf("%s %s (%s): %w", record.Name, record.Type, record.Source, err))
+		}
+	}
+	if len(errs) > 0 {
+		slices.SortFunc(errs, func(l, r error) int { return cmp.Compare(l.Error(), r.Error()) })
+		return nil, errors.Join(errs...)
+	}
+	slices.SortFunc(records, compareRecords)
+	return slices.CompactFunc(records, func(l, r ResourceRecord) bool {
+		return l.Name == r.Name && l.Type == r.Type && l.Value == r.Value
+	}), nil
+}
+
+// RenderLocalDNS renders records for a local resolver in one of the
+// non-BIND domain.DNSFormats. Unbound keeps every record, CNAMEs included.
+// The hosts-file formats can only hold addresses, so CNAMEs are flattened to
+// the addresses of their targets and other record types are left out. The
+// names of CNAMEs whose target has no known address are returned as skipped.
+func RenderLocalDNS(records []ResourceRecord, format string, ttl int) (string, []string, error) {
+	switch format {
+	case domain.DNSFormatUnbound:
+		return renderUnbound(records, ttl), nil, nil
+	case domain.DNSFormatCoreDNS, domain.DNSFormatHosts, domain.DNSFormatPihole:
+		hosts, skipped := flattenHosts(records)
+		return renderHosts(hosts, format), skipped, nil
+	}
+	return "", nil, fmt.Errorf("unknown local DNS format %q: must be one of %s, %s, %s, %s", format,
+		domain.DNSFormatUnbound, domain.DNSFormatCoreDNS, domain.DNSFormatPihole, domain.DNSFormatHosts)
+}
+
+// renderUnbound renders local-data statements for the server: clause.
+func renderUnbound(records []ResourceRecord, ttl int) string {
+	sb := new(strings.Builder)
+	sb.WriteString("# Generated by ez-ipam. Include from the server: clause.\n")
+	for _, record := range records {
+		value := strings.ReplaceAll(bindValue(record), `"`, `\"`)
+		fmt.Fprintf(sb, "local-data: \"%s. %d IN %s %s\"\n", record.Name, cmp.Or(record.TTL, ttl), record.Type, value)
+	}
+	return sb.String()
+}
+
+// hostEntry is an address with the names resolving to it, the address
+// record names first.
+type hostEntry struct {
+	addr  netip.Addr
+	names []string
+}
+
+// flattenHosts maps every A and AAAA record and every CNAME that leads to
+// one to its address.
+func flattenHosts(records []ResourceRecord) ([]hostEntry, []string) {
+	addresses := map[string][]netip.Addr{}
+	cnames := map[string]string{}
+	for _, record := range records {
+		switch record.Type {
+		case "A", "AAAA":
+			if addr, err := netip.ParseAddr(record.Value); err == nil {
+				addresses[record.Name] = append(addresses[record.Name], addr)
+			}
+		case "CNAME":
+			cnames[record.Name] = normalizeDNSName(record.Value)
+		}
+	}
+
+	entries := map[netip.Addr]*hostEntry{}
+	addName := func(addr netip.Addr, name string) {
+		if entries[addr] == nil {
+			entries[addr] = &hostEntry{addr: addr}
+		}
+		if !slices.Contains(entries[addr].names, name) {
+			entries[addr].names = append(entries[addr].names, name)
+		}
+	}
+	for _, record := range records {
+		for _, addr := range addresses[record.Name] {
+			addName(addr, record.Name)
+		}
+	}
+	var skipped []string
+	for _, record := range records {
+		if record.Type != "CNAME" {
+			continue
+		}
+		target := cnames[record.Name]
+		for i := 0; i < maxCNAMEChain && cnames[target] != ""; i++ {
+			target = cnames[target]
+		}
+		if len(addresses[target]) == 0 {
+			skipped = append(skipped, record.Name)
+			continue
+		}
+		for _, addr := range addresses[target] {
+			addName(addr, record.Name)
+		}
+	}
+
+	result := make([]hostEntry, 0, len(entries))
+	for _, entry := range entries {
+		result = append(result, *entry)
+	}
+	slices.SortFunc(result, func(l, r hostEntry) int { return l.addr.Compare(r.addr) })
+	return result, skipped
+}
+
+// renderHosts renders entries as a hosts file. Pi-hole's custom.list takes
+// one name per line; /etc/hosts and the CoreDNS hosts plugin list every name
+// of an address on one line.
+func renderHosts(entries []hostEntry, format string) string {
+	sb := new(strings.Builder)
+	sb.WriteString("# Generated by ez-ipam. Do not edit.\n")
+	for _, entry := range entries {
+		if format == domain.DNSFormatPihole {
+			for _, name := range entry.names {
+				fmt.Fprintf(sb, "%s %s\n", entry.addr, name)
+			}
+			continue
+		}
+		fmt.Fprintf(sb, "%s\t%s\n", entry.addr, strings.Join(entry.names, " "))
+	}
+	return sb.String()
+}
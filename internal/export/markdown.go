@@ -27,6 +27,7 @@ func RenderMarkdown(catalog *domain.Catalog) (string, error) {
 	networksReservedIPs := map[string][]map[string]string{}
 	summaryRows := []map[string]string{}
 	dnsRows := []map[string]string{}
+	usage := catalog.NewUtilizationIndex()
 
 	buildTreePrefix := func(ancestorHasNext []bool, isLast bool) string {
 		sb := new(strings.Builder)
@@ -63,7 +64,7 @@ func RenderMarkdown(catalog *domain.Catalog) (string, error) {
 		if walkErr != nil {
 			return
 		}
-		index, data, err := n.RenderDetailsMap(catalog)
+		index, data, err := n.RenderDetailsMap(catalog, usage)
 		if err != nil {
 			walkErr = fmt.Errorf("render details for %s: %w", n.GetPath(), err)
 			return
@@ -110,7 +111,7 @@ func RenderMarkdown(catalog *domain.Catalog) (string, error) {
 			}
 		}
 
-		children := catalog.GetChildren(n)
+		children := catalog.ChildrenWithFreeSpace(n)
 
 		reserved := []map[string]string{}
 		networkChildren := make([]*domain.Network, 0, len(children))
@@ -130,12 +131,19 @@ func RenderMarkdown(catalog *domain.Catalog) (string, error) {
 		networksHasReservedIPs[path] = len(reserved) > 0
 		networksReservedIPs[path] = reserved
 
+		utilization, err := renderUtilizationCell(catalog, usage, n)
+		if err != nil {
+			walkErr = fmt.Errorf("render utilization for %s: %w", n.GetPath(), err)
+			return
+		}
+
 		networkCell := fmt.Sprintf("%s [link](#%s)", markdownCode(networkTreePrefix+n.ID), networksAnchor[path])
 		summaryRows = append(summaryRows, map[string]string{
 			"Network":     networkCell,
 			"Name":        markdownInline(defaultIfEmpty(n.DisplayName, "-")),
 			"Allocation":  markdownInline(defaultIfEmpty(networksMode[path], "-")),
 			"VLAN":        markdownInline(vlanLabel(n.VLANID)),
+			"Utilization": utilization,
 			"Description": markdownInline(clampOverviewDescription(defaultIfEmpty(n.Description, "-"), 60)),
 		})
 
@@ -148,6 +156,7 @@ func RenderMarkdown(catalog *domain.Catalog) (string, error) {
 					"Name":        markdownInline(defaultIfEmpty(ip["DisplayName"], "-")),
 					"Allocation":  "Reserved IP",
 					"VLAN":        markdownInline(vlanLabel(n.VLANID)),
+					"Utilization": "-",
 					"Description": markdownInline(clampOverviewDescription(defaultIfEmpty(ip["Description"], "-"), 60)),
 				})
 			}
@@ -160,7 +169,7 @@ func RenderMarkdown(catalog *domain.Catalog) (string, error) {
 
 	networksMenuItem := catalog.GetByParentAndDisplayID(nil, domain.FolderNetworks)
 	topLevelNetworks := make([]*domain.Network, 0)
-	for _, item := range catalog.GetChildren(networksMenuItem) {
+	for _, item := range catalog.ChildrenWithFreeSpace(networksMenuItem) {
 		n, ok := item.(*domain.Network)
 		if !ok {
 			return "", fmt.Errorf("expected network under Networks folder, got %T at %s", item, item.GetPath())
@@ -190,31 +199,46 @@ func RenderMarkdown(catalog *domain.Catalog) (string, error) {
 
 	ssidRows := []map[string]string{}
 	dnsMenuItem := catalog.GetByParentAndDisplayID(nil, domain.FolderDNS)
+	dnsZoneRows := []map[string]string{}
+	dnsZoneRecords := map[string][]map[string]string{}
+	d := catalog.Config.DNSDefaults()
 	for _, item := range catalog.GetChildren(dnsMenuItem) {
-		record, ok := item.(*domain.DNSRecord)
-		if !ok {
-			continue
-		}
-		recordType := record.RecordType
-		recordValue := record.RecordValue
-		valueCell := "-"
-		if strings.TrimSpace(record.ReservedIPPath) != "" {
-			recordType = "Alias"
-			recordValue = "<missing>"
-			if ip, ok := catalog.Get(record.ReservedIPPath).(*domain.IP); ok {
-				recordValue = formatDNSAliasValue(ip)
+		switch v := item.(type) {
+		case *domain.DNSRecord:
+			dnsRows = append(dnsRows, dnsRecordRow(catalog, v))
+		case *domain.DNSZone:
+			rows := []map[string]string{}
+			for _, child := range catalog.GetChildren(v) {
+				if record, ok := child.(*domain.DNSRecord); ok {
+					rows = append(rows, dnsRecordRow(catalog, record))
+				}
+			}
+			dnsZoneRecords[v.GetPath()] = rows
+			ttl := strconv.Itoa(v.TTL)
+			if v.TTL == 0 {
+				ttl = strconv.Itoa(d.TTL) + " (default)"
 			}
-			valueCell = markdownTableCell(defaultIfEmpty(recordValue, "-"))
-		} else if strings.TrimSpace(recordValue) != "" {
-			// Keep normal DNS record values copy-friendly in the report.
-			valueCell = markdownCode(recordValue)
+			contact := v.Contact
+			if contact == "" {
+				contact = defaultIfEmpty(d.AdminEmail, "hostmaster."+v.ID) + " (default)"
+			}
+			nameServers := strings.Join(v.NameServers, "\n")
+			if nameServers == "" {
+				nameServers = strings.Join(d.NameServers, "\n")
+				if nameServers == "" {
+					nameServers = defaultIfEmpty(d.PrimaryNS, "ns1."+v.ID)
+				}
+				nameServers += " (default)"
+			}
+			dnsZoneRows = append(dnsZoneRows, map[string]string{
+				"Name":        markdownInline(v.ID),
+				"TTL":         markdownInline(ttl),
+				"Contact":     markdownTableCell(contact),
+				"NameServers": markdownTableCell(nameServers),
+				"Description": markdownBlockquote(strings.TrimSpace(v.Description)),
+				"ZonePath":    v.GetPath(),
+			})
 		}
-		dnsRows = append(dnsRows, map[string]string{
-			"FQDN":        markdownCode(record.ID),
-			"Type":        markdownInline(defaultIfEmpty(recordType, "-")),
-			"Value":       valueCell,
-			"Description": markdownTableCell(defaultIfEmpty(record.Description, "-")),
-		})
 	}
 
 	ssidsMenuItem := catalog.GetByParentAndDisplayID(nil, domain.FolderSSIDs)
@@ -338,6 +362,8 @@ func RenderMarkdown(catalog *domain.Catalog) (string, error) {
 		"NetworksReservedIPs":    networksReservedIPs,
 		"VLANRows":               vlanRows,
 		"DNSRows":                dnsRows,
+		"DNSZoneRows":            dnsZoneRows,
+		"DNSZoneRecords":         dnsZoneRecords,
 		"SSIDRows":               ssidRows,
 		"ZoneRows":               zoneRows,
 		"EquipmentRows":          equipmentRows,
@@ -402,6 +428,61 @@ func defaultIfEmpty(value, fallback string) string { //nolint:unparam // fallbac
 	return value
 }
 
+// renderUtilizationCell summarizes how full a network is for the overview table.
+func renderUtilizationCell(catalog *domain.Catalog, usage *domain.UtilizationIndex, n *domain.Network) (string, error) {
+	if n.AllocationMode == domain.AllocationModeUnallocated {
+		return "-", nil
+	}
+	u, err := usage.Utilization(n)
+	if err != nil {
+		return "", err
+	}
+	if n.AllocationMode == domain.AllocationModeSubnets {
+		return fmt.Sprintf("%.1f%% allocated", u.AllocatedPercent()), nil
+	}
+	cell := fmt.Sprintf("%d/%s reserved (%.1f%%)", u.Reserved, u.Usable, u.ReservedPercent())
+	if catalog.IsNearlyExhausted(n, u) {
+		cell = "**Nearly exhausted:** " + cell
+	}
+	return cell, nil
+}
+
+// dnsRecordRow returns the DNS table row of record.
+func dnsRecordRow(catalog *domain.Catalog, record *domain.DNSRecord) map[string]string {
+	recordType := record.RecordType
+	recordValue := record.RecordValue
+	valueCell := "-"
+	if record.IsAlias() {
+		recordType = "Alias"
+		if strings.TrimSpace(record.ReservedHost) != "" {
+			recordType = "Host"
+		}
+		values := []string{}
+		for _, ip := range catalog.AliasTargets(record) {
+			values = append(values, formatDNSAliasValue(ip))
+		}
+		if len(values) < len(record.AliasTargetPaths()) || len(values) == 0 {
+			values = append(values, "<missing>")
+		}
+		recordValue = strings.Join(values, "\n")
+		valueCell = markdownTableCell(defaultIfEmpty(recordValue, "-"))
+	} else if strings.TrimSpace(recordValue) != "" {
+		// Keep normal DNS record values copy-friendly in the report.
+		valueCell = markdownCode(recordValue)
+	}
+	ttl := "-"
+	if record.TTL > 0 {
+		ttl = strconv.Itoa(record.TTL)
+	}
+	return map[string]string{
+		"FQDN":        markdownCode(record.ID),
+		"Type":        markdownInline(defaultIfEmpty(recordType, "-")),
+		"Value":       valueCell,
+		"TTL":         ttl,
+		"Description": markdownTableCell(defaultIfEmpty(record.Description, "-")),
+	}
+}
+
 func formatDNSAliasValue(ip *domain.IP) string {
 	if ip == nil {
 		return "<missing>"
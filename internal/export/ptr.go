@@ -0,0 +1,216 @@
+package export
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"net/netip"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/plumber-cd/ez-ipam/internal/domain"
+)
+
+// PTRConflict is an address claimed by more than one name. Only Chosen gets
+// a PTR record: the reservation's own hostname, otherwise the first alias.
+type PTRConflict struct {
+	Address string
+	Names   []string
+	Chosen  string
+}
+
+// ptrClaim collects the names pointing at one reserved address.
+type ptrClaim struct {
+	pool    *domain.Network
+	source  string
+	own     string
+	aliases []string
+}
+
+// ReverseZones builds and checks the reverse zones of every Host Pool holding
+// named reservations or aliased addresses. IPv4 zones end on octet
+// boundaries and IPv6 zones on nibble boundaries, so a pool that does not
+// end on one is split over several zones. IPv4 pools smaller than a /24 get
+// an RFC 2317 zone such as 0/26.1.168.192.in-addr.arpa, plus the delegating
+// NS and CNAME records in the /24 zone.
+func ReverseZones(catalog *domain.Catalog) ([]Zone, []PTRConflict, error) {
+	claims := map[netip.Addr]*ptrClaim{}
+	claim := func(ip *domain.IP) *ptrClaim {
+		pool, ok := catalog.Get(ip.ParentPath).(*domain.Network)
+		addr, err := netip.ParseAddr(ip.ID)
+		if !ok || pool.AllocationMode != domain.AllocationModeHosts || err != nil {
+			return nil
+		}
+		if claims[addr] == nil {
+			claims[addr] = &ptrClaim{pool: pool, source: ip.GetPath()}
+		}
+		return claims[addr]
+	}
+	for _, item := range catalog.All() {
+		switch v := item.(type) {
+		case *domain.IP:
+			if name := ptrTarget(v.DisplayName, catalog.Get(v.ParentPath)); name != "" {
+				if c := claim(v); c != nil {
+					c.own = name
+				}
+			}
+		case *domain.DNSRecord:
+			for _, ip := range catalog.AliasTargets(v) {
+				if c := claim(ip); c != nil {
+					c.aliases = append(c.aliases, normalizeDNSName(v.ID))
+				}
+			}
+		}
+	}
+
+	d := catalog.Config.DNSDefaults()
+	forward, _, err := DNSZones(catalog)
+	if err != nil {
+		return nil, nil, err
+	}
+	ns := reverseNSZone(d, forward)
+	nsDomain := ns.Name
+
+	zones := map[string]*Zone{}
+	add := func(zoneName string, record ResourceRecord) {
+		if zones[zoneName] == nil {
+			zones[zoneName] = &Zone{Name: zoneName, NSDomain: nsDomain, Contact: ns.Contact, NameServers: ns.NameServers}
+		}
+		zones[zoneName].Records = append(zones[zoneName].Records, record)
+	}
+	delegated := map[*domain.Network]bool{}
+	var conflicts []PTRConflict
+	addrs := make([]netip.Addr, 0, len(claims))
+	for addr := range claims {
+		addrs = append(addrs, addr)
+	}
+	slices.SortFunc(addrs, netip.Addr.Compare)
+	for _, addr := range addrs {
+		c := claims[addr]
+		names := slices.Clone(c.aliases)
+		if c.own != "" {
+			names = append(names, c.own)
+		}
+		slices.Sort(names)
+		names = slices.Compact(names)
+		chosen := cmp.Or(c.own, names[0])
+		if len(names) > 1 {
+			conflicts = append(conflicts, PTRConflict{Address: addr.String(), Names: names, Chosen: chosen})
+		}
+
+		prefix, err := netip.ParsePrefix(c.pool.ID)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid CIDR %s: %w", c.pool.ID, err)
+		}
+		labels := reverseLabelsOf(addr)
+		if addr.Is4() && prefix.Bits() > 24 {
+			parent := strings.Join(labels[1:], ".")
+			child := fmt.Sprintf("%d/%d.%s", prefix.Masked().Addr().As4()[3], prefix.Bits(), parent)
+			add(child, ResourceRecord{Name: labels[0] + "." + child, Type: "PTR", Value: chosen, Source: c.source})
+			if !delegated[c.pool] {
+				delegated[c.pool] = true
+				delegateRFC2317(add, prefix, parent, child, d, nsDomain, ns.NameServers)
+			}
+			continue
+		}
+		zoneLabels := zoneLabelCount(addr, prefix.Bits())
+		add(strings.Join(labels[len(labels)-zoneLabels-2:], "."), ResourceRecord{
+			Name: strings.Join(labels, "."), Type: "PTR", Value: chosen, Source: c.source,
+		})
+	}
+
+	result := make([]Zone, 0, len(zones))
+	var errs []error
+	for _, zone := range zones {
+		slices.SortFunc(zone.Records, compareRecords)
+		if err := CheckZone(*zone, d); err != nil {
+			errs = append(errs, err)
+		}
+		result = append(result, *zone)
+	}
+	slices.SortFunc(result, func(l, r Zone) int { return cmp.Compare(reverseLabels(l.Name), reverseLabels(r.Name)) })
+	slices.SortFunc(errs, func(l, r error) int { return cmp.Compare(l.Error(), r.Error()) })
+	return result, conflicts, errors.Join(errs...)
+}
+
+// reverseNSZone returns the forward zone whose name servers and contact the
+// reverse zones use: the first zone of the DNS settings, or the only forward
+// zone. Otherwise reverse zones fall back to the configured name servers or
+// their own ns1 name.
+func reverseNSZone(d domain.DNSConfig, forward []Zone) Zone {
+	if len(d.Zones) > 0 {
+		name := normalizeDNSName(d.Zones[0])
+		if idx := slices.IndexFunc(forward, func(zone Zone) bool { return zone.Name == name }); idx >= 0 {
+			return forward[idx]
+		}
+		return Zone{Name: name}
+	}
+	if len(forward) == 1 {
+		return forward[0]
+	}
+	return Zone{}
+}
+
+// delegateRFC2317 adds to the /24 zone parent the NS records delegating
+// child and a CNAME into child for every address of prefix.
+func delegateRFC2317(add func(string, ResourceRecord), prefix netip.Prefix, parent, child string, d domain.DNSConfig, nsDomain string, nameServers []string) {
+	for _, ns := range resolveSOA(Zone{Name: child, NSDomain: nsDomain, NameServers: nameServers}, d).nameServers {
+		add(parent, ResourceRecord{Name: child, Type: "NS", Value: ns, Source: "RFC 2317 delegation"})
+	}
+	size := int64(1) << (32 - prefix.Bits())
+	first := int64(prefix.Masked().Addr().As4()[3])
+	for octet := first; octet < first+size; octet++ {
+		label := strconv.FormatInt(octet, 10)
+		add(parent, ResourceRecord{
+			Name:   label + "." + parent,
+			Type:   "CNAME",
+			Value:  label + "." + child,
+			Source: "RFC 2317 delegation",
+		})
+	}
+}
+
+// reverseLabelsOf returns the labels of the reverse name of addr, ending in
+// "in-addr", "arpa" or "ip6", "arpa".
+func reverseLabelsOf(addr netip.Addr) []string {
+	var labels []string
+	if addr.Is4() {
+		for _, b := range addr.As4() {
+			labels = append(labels, strconv.Itoa(int(b)))
+		}
+		slices.Reverse(labels)
+		return append(labels, "in-addr", "arpa")
+	}
+	for _, b := range addr.As16() {
+		labels = append(labels, strconv.FormatInt(int64(b>>4), 16), strconv.FormatInt(int64(b&0xf), 16))
+	}
+	slices.Reverse(labels)
+	return append(labels, "ip6", "arpa")
+}
+
+// zoneLabelCount returns how many octets or nibbles name the reverse zone of
+// a network of the given prefix length, rounding up to the next boundary.
+func zoneLabelCount(addr netip.Addr, bits int) int {
+	if addr.Is4() {
+		return max(1, (bits+7)/8)
+	}
+	return max(1, (bits+3)/4)
+}
+
+// ptrTarget returns the name a reservation's PTR record points to: its
+// hostname when fully qualified, or qualified with the DHCP domain of its
+// Host Pool. Names that are not valid hostnames get no PTR record.
+func ptrTarget(hostname string, pool domain.Item) string {
+	name := normalizeDNSName(hostname)
+	if name == "" || domain.ValidateHostname(name) != nil {
+		return ""
+	}
+	if strings.Contains(name, ".") {
+		return name
+	}
+	if n, ok := pool.(*domain.Network); ok && n.DHCPOptions != nil && n.DHCPOptions.DomainName != "" {
+		return name + "." + normalizeDNSName(n.DHCPOptions.DomainName)
+	}
+	return ""
+}
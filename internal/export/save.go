@@ -0,0 +1,34 @@
+package export
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/plumber-cd/ez-ipam/internal/domain"
+	"github.com/plumber-cd/ez-ipam/internal/store"
+)
+
+// Save writes catalog to dir and regenerates everything derived from it:
+// the markdown report and the configured DHCP and DNS exports. Both the
+// terminal UI and the CLI save through it, so a new export only needs to be
+// added here.
+func Save(dir string, catalog *domain.Catalog) error {
+	if err := store.Save(dir, catalog); err != nil {
+		return fmt.Errorf("saving data: %w", err)
+	}
+	md, err := RenderMarkdown(catalog)
+	if err != nil {
+		return fmt.Errorf("rendering markdown: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, store.MarkdownFileName), []byte(md), 0644); err != nil {
+		return fmt.Errorf("writing markdown: %w", err)
+	}
+	if err := WriteDHCPExports(dir, catalog); err != nil {
+		return fmt.Errorf("writing DHCP exports: %w", err)
+	}
+	if err := WriteDNSExports(dir, catalog); err != nil {
+		return fmt.Errorf("writing DNS exports: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,349 @@
+// Package importer adds items read from external files to a catalog.
+package importer
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/netip"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/plumber-cd/ez-ipam/internal/domain"
+)
+
+// CSV import types.
+const (
+	TypeIPs   = "ips"
+	TypeVLANs = "vlans"
+	TypeDNS   = "dns"
+	TypePorts = "ports"
+)
+
+// Types lists the supported CSV import types.
+var Types = []string{TypeIPs, TypeVLANs, TypeDNS, TypePorts}
+
+// Column is a CSV column understood by an import type.
+type Column struct {
+	Name     string
+	Required bool
+	Help     string
+}
+
+// Columns lists the columns of each import type. The header row names the
+// columns in any order; optional columns may be left out.
+var Columns = map[string][]Column{
+	TypeIPs: {
+		{Name: "address", Required: true, Help: "IP address; reserved in the deepest Host Pool containing it"},
+		{Name: "name", Required: true, Help: "hostname"},
+		{Name: "mac", Help: "MAC address"},
+		{Name: "description"},
+	},
+	TypeVLANs: {
+		{Name: "id", Required: true, Help: "VLAN ID, 1-4094"},
+		{Name: "name", Required: true},
+		{Name: "description"},
+	},
+	TypeDNS: {
+		{Name: "fqdn", Required: true, Help: "nested in the deepest DNS zone containing it"},
+		{Name: "type", Required: true, Help: "record type, ALIAS for reserved IP addresses or HOST for a reservation hostname"},
+		{Name: "value", Required: true, Help: "record value; space-separated addresses for ALIAS"},
+		{Name: "ttl", Help: "seconds; empty keeps the zone default"},
+		{Name: "description"},
+	},
+	TypePorts: {
+		{Name: "equipment", Required: true, Help: "equipment name"},
+		{Name: "port", Required: true, Help: "port number"},
+		{Name: "type", Required: true, Help: "port type, e.g. RJ45"},
+		{Name: "speed", Required: true},
+		{Name: "name"},
+		{Name: "poe"},
+		{Name: "lag_group", Help: "LAG group number or \"self\""},
+		{Name: "lag_mode"},
+		{Name: "native_vlan", Help: "VLAN ID"},
+		{Name: "tagged_mode", Help: "AllowAll, BlockAll or Custom"},
+		{Name: "tagged_vlans", Help: "comma-separated VLAN IDs for Custom"},
+		{Name: "disabled", Help: "true or false"},
+		{Name: "notes", Help: "destination notes"},
+	},
+}
+
+// RowError is a row that was not imported.
+type RowError struct {
+	Line int
+	Err  error
+}
+
+func (e RowError) Error() string {
+	return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+}
+
+// Result is the outcome of an import.
+type Result struct {
+	Rows   int
+	Added  []domain.Item
+	Errors []RowError
+}
+
+// rowBuilder turns a row, keyed by column name, into a new catalog item.
+type rowBuilder func(c *domain.Catalog, row map[string]string) (domain.Item, error)
+
+var rowBuilders = map[string]rowBuilder{
+	TypeIPs:   ipFromRow,
+	TypeVLANs: vlanFromRow,
+	TypeDNS:   dnsRecordFromRow,
+	TypePorts: portFromRow,
+}
+
+// CSV adds the rows of r to catalog as items of importType. Every row is
+// validated against the catalog, including the rows added before it. Rows
+// that fail are reported in the result and the others are still added; an
+// error is returned only when the file itself cannot be used.
+func CSV(catalog *domain.Catalog, importType string, r io.Reader) (Result, error) {
+	build, ok := rowBuilders[importType]
+	if !ok {
+		return Result{}, fmt.Errorf("unknown import type %q: must be one of %s", importType, strings.Join(Types, ", "))
+	}
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	reader.Comment = '#'
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return Result{}, errors.New("missing header row")
+	}
+	if err != nil {
+		return Result{}, err
+	}
+	if err := checkHeader(header, Columns[importType]); err != nil {
+		return Result{}, err
+	}
+
+	var result Result
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return result, nil
+		}
+		if err != nil {
+			return result, err
+		}
+		line, _ := reader.FieldPos(0)
+		result.Rows++
+		if len(record) != len(header) {
+			result.Errors = append(result.Errors, RowError{Line: line, Err: fmt.Errorf("expected %d fields, got %d", len(header), len(record))})
+			continue
+		}
+		row := make(map[string]string, len(header))
+		for i, name := range header {
+			row[strings.ToLower(strings.TrimSpace(name))] = strings.TrimSpace(record[i])
+		}
+		item, err := build(catalog, row)
+		if err == nil {
+			err = catalog.Add(item)
+		}
+		if err != nil {
+			result.Errors = append(result.Errors, RowError{Line: line, Err: err})
+			continue
+		}
+		result.Added = append(result.Added, item)
+	}
+}
+
+// checkHeader rejects unknown and repeated columns and requires the
+// required ones.
+func checkHeader(header []string, columns []Column) error {
+	seen := map[string]bool{}
+	for _, name := range header {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if !slices.ContainsFunc(columns, func(c Column) bool { return c.Name == name }) {
+			return fmt.Errorf("unknown column %q", name)
+		}
+		if seen[name] {
+			return fmt.Errorf("column %q is repeated", name)
+		}
+		seen[name] = true
+	}
+	for _, column := range columns {
+		if column.Required && !seen[column.Name] {
+			return fmt.Errorf("missing column %q", column.Name)
+		}
+	}
+	return nil
+}
+
+func ipFromRow(c *domain.Catalog, row map[string]string) (domain.Item, error) {
+	addr, err := netip.ParseAddr(row["address"])
+	if err != nil {
+		return nil, fmt.Errorf("invalid address %q", row["address"])
+	}
+	return newReservation(c, addr, row["name"], row["mac"], row["description"])
+}
+
+// newReservation returns a reservation of addr in the deepest Host Pool
+// containing it.
+func newReservation(c *domain.Catalog, addr netip.Addr, name, mac, description string) (*domain.IP, error) {
+	pool := c.HostPoolContaining(addr)
+	if pool == nil {
+		return nil, fmt.Errorf("no Host Pool contains %s", addr)
+	}
+	normalizedMAC, err := domain.NormalizeMACAddress(mac)
+	if err != nil {
+		return nil, fmt.Errorf("invalid MAC address %q: %w", mac, err)
+	}
+	ip := &domain.IP{
+		Base:        domain.Base{ID: addr.String(), ParentPath: pool.GetPath()},
+		DisplayName: name,
+		MACAddress:  normalizedMAC,
+		Description: description,
+	}
+	if c.Get(ip.GetPath()) != nil {
+		return nil, fmt.Errorf("%s is already reserved in %s", addr, pool.ID)
+	}
+	return ip, nil
+}
+
+func vlanFromRow(c *domain.Catalog, row map[string]string) (domain.Item, error) {
+	id, err := domain.ParsePositiveIntID(row["id"])
+	if err != nil {
+		return nil, fmt.Errorf("invalid VLAN ID %q: %w", row["id"], err)
+	}
+	if c.FindVLANByID(id) != nil {
+		return nil, fmt.Errorf("VLAN %d already exists", id)
+	}
+	return &domain.VLAN{
+		Base:        domain.Base{ID: strconv.Itoa(id), ParentPath: domain.FolderVLANs},
+		DisplayName: row["name"],
+		Description: row["description"],
+	}, nil
+}
+
+func dnsRecordFromRow(c *domain.Catalog, row map[string]string) (domain.Item, error) {
+	ttl, err := domain.ParseTTL(row["ttl"])
+	if err != nil {
+		return nil, err
+	}
+	record := &domain.DNSRecord{
+		Base:        domain.Base{ID: row["fqdn"], ParentPath: domain.FolderDNS},
+		TTL:         ttl,
+		Description: row["description"],
+	}
+	if zone := c.ZoneContaining(record.ID); zone != nil {
+		record.ParentPath = zone.GetPath()
+	}
+	switch recordType := strings.ToUpper(row["type"]); recordType {
+	case "ALIAS":
+		for i, text := range strings.Fields(row["value"]) {
+			addr, err := netip.ParseAddr(text)
+			if err != nil {
+				return nil, fmt.Errorf("invalid alias address %q", text)
+			}
+			ips := c.ReservationsAt(addr)
+			if len(ips) != 1 {
+				return nil, fmt.Errorf("alias address %s must be reserved exactly once, found %d reservations", addr, len(ips))
+			}
+			if i == 0 {
+				record.ReservedIPPath = ips[0].GetPath()
+			} else {
+				record.ReservedIPPaths = append(record.ReservedIPPaths, ips[0].GetPath())
+			}
+		}
+		if record.ReservedIPPath == "" {
+			return nil, errors.New("alias must list at least one reserved address")
+		}
+	case "HOST":
+		record.ReservedHost = row["value"]
+	default:
+		record.RecordType = recordType
+		record.RecordValue = row["value"]
+	}
+	if existing := c.DNSRecordNamed(record.ID); existing != nil {
+		return nil, fmt.Errorf("FQDN %s already exists at %s", record.ID, existing.GetPath())
+	}
+	return record, nil
+}
+
+func portFromRow(c *domain.Catalog, row map[string]string) (domain.Item, error) {
+	equipment := findEquipment(c, row["equipment"])
+	if equipment == nil {
+		return nil, fmt.Errorf("equipment %q not found", row["equipment"])
+	}
+	number, err := domain.ParsePositiveIntID(row["port"])
+	if err != nil {
+		return nil, fmt.Errorf("invalid port number %q: %w", row["port"], err)
+	}
+	disabled := false
+	if row["disabled"] != "" {
+		if disabled, err = strconv.ParseBool(row["disabled"]); err != nil {
+			return nil, fmt.Errorf("invalid disabled value %q: must be true or false", row["disabled"])
+		}
+	}
+	lagGroupText := row["lag_group"]
+	if strings.EqualFold(lagGroupText, "self") {
+		lagGroupText = strconv.Itoa(number)
+	}
+	lagGroup, err := domain.ParseOptionalIntField(lagGroupText)
+	if err != nil || lagGroup < 0 {
+		return nil, fmt.Errorf("invalid LAG group %q", row["lag_group"])
+	}
+	nativeVLANID, err := domain.ParseOptionalVLANID(row["native_vlan"])
+	if err != nil {
+		return nil, err
+	}
+	mode := domain.ParseTaggedMode(row["tagged_mode"])
+	if mode == domain.TaggedVLANModeNone && row["tagged_mode"] != "" && !strings.EqualFold(row["tagged_mode"], "None") {
+		return nil, fmt.Errorf("invalid tagged mode %q: must be AllowAll, BlockAll or Custom", row["tagged_mode"])
+	}
+	tagged, err := domain.ParseVLANListCSV(row["tagged_vlans"])
+	if err != nil {
+		return nil, err
+	}
+	if lagGroup > 0 && lagGroup != number {
+		// LAG member ports inherit VLAN settings from the master port.
+		nativeVLANID = 0
+		mode = domain.TaggedVLANModeNone
+		tagged = nil
+	}
+
+	port := &domain.Port{
+		Base:             domain.Base{ID: strconv.Itoa(number), ParentPath: equipment.GetPath()},
+		Disabled:         disabled,
+		Name:             row["name"],
+		PortType:         row["type"],
+		Speed:            row["speed"],
+		PoE:              row["poe"],
+		LAGGroup:         lagGroup,
+		LAGMode:          row["lag_mode"],
+		NativeVLANID:     nativeVLANID,
+		TaggedVLANMode:   mode,
+		TaggedVLANIDs:    tagged,
+		DestinationNotes: row["notes"],
+	}
+	for _, sibling := range c.GetChildren(equipment) {
+		other, ok := sibling.(*domain.Port)
+		if !ok {
+			continue
+		}
+		if other.ID == port.ID {
+			return nil, fmt.Errorf("port %s already exists on %s", port.ID, equipment.DisplayName)
+		}
+		if port.Name != "" && strings.EqualFold(other.Name, port.Name) {
+			return nil, fmt.Errorf("port name %q already exists on %s", port.Name, equipment.DisplayName)
+		}
+	}
+	return port, nil
+}
+
+// findEquipment returns the equipment named name, ignoring case.
+func findEquipment(c *domain.Catalog, name string) *domain.Equipment {
+	folder := c.GetByParentAndDisplayID(nil, domain.FolderEquipment)
+	for _, item := range c.GetChildren(folder) {
+		if equipment, ok := item.(*domain.Equipment); ok && (strings.EqualFold(equipment.DisplayName, name) || strings.EqualFold(equipment.ID, name)) {
+			return equipment
+		}
+	}
+	return nil
+}
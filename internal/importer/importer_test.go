@@ -0,0 +1,265 @@
+package importer
+
+import (
+	"net/netip"
+	"strings"
+	"testing"
+
+	"github.com/plumber-cd/ez-ipam/internal/domain"
+)
+
+func newTestCatalog() *domain.Catalog {
+	c := domain.NewCatalog()
+	c.Put(&domain.StaticFolder{Base: domain.Base{ID: domain.FolderNetworks}, Index: 0})
+	c.Put(&domain.StaticFolder{Base: domain.Base{ID: domain.FolderVLANs}, Index: 2})
+	c.Put(&domain.StaticFolder{Base: domain.Base{ID: domain.FolderEquipment}, Index: 4})
+	c.Put(&domain.StaticFolder{Base: domain.Base{ID: domain.FolderDNS}, Index: 5})
+	site := &domain.Network{Base: domain.Base{ID: "10.0.0.0/16", ParentPath: domain.FolderNetworks}, AllocationMode: domain.AllocationModeSubnets}
+	c.Put(site)
+	c.Put(&domain.Network{Base: domain.Base{ID: "10.0.1.0/24", ParentPath: site.GetPath()}, AllocationMode: domain.AllocationModeHosts})
+	c.Put(&domain.Network{Base: domain.Base{ID: "10.0.2.0/24", ParentPath: site.GetPath()}})
+	return c
+}
+
+func TestCSVIPs(t *testing.T) {
+	c := newTestCatalog()
+	input := `Name, Address, MAC
+nas, 10.0.1.10, AA-BB-CC-DD-EE-FF
+# skipped comment
+printer, 10.0.1.10,
+bad name, 10.0.1.11,
+free, 10.0.2.1,
+short
+`
+	result, err := CSV(c, TypeIPs, strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("CSV() error: %v", err)
+	}
+	if result.Rows != 5 || len(result.Added) != 1 || len(result.Errors) != 4 {
+		t.Fatalf("CSV() = %+v, want 1 of 5 rows added", result)
+	}
+	ip, ok := c.Get("Networks -> 10.0.0.0/16 -> 10.0.1.0/24 -> 10.0.1.10").(*domain.IP)
+	if !ok || ip.DisplayName != "nas" || ip.MACAddress != "aa:bb:cc:dd:ee:ff" {
+		t.Errorf("reservation = %+v, want nas in the Host Pool", ip)
+	}
+	for i, want := range []struct {
+		line int
+		text string
+	}{
+		{4, "already reserved"},
+		{5, "unsupported character"},
+		{6, "no Host Pool contains 10.0.2.1"},
+		{7, "expected 3 fields, got 1"},
+	} {
+		if got := result.Errors[i]; got.Line != want.line || !strings.Contains(got.Err.Error(), want.text) {
+			t.Errorf("Errors[%d] = %v, want line %d: %q", i, got, want.line, want.text)
+		}
+	}
+
+	for _, header := range []string{"", "address,hostname", "address,address,name", "name,mac"} {
+		if _, err := CSV(c, TypeIPs, strings.NewReader(header)); err == nil {
+			t.Errorf("CSV() with header %q: expected error", header)
+		}
+	}
+	if _, err := CSV(c, "networks", strings.NewReader("cidr")); err == nil {
+		t.Error("expected error for unknown import type")
+	}
+}
+
+func TestCSVVLANsAndPorts(t *testing.T) {
+	c := newTestCatalog()
+	c.Put(&domain.Equipment{Base: domain.Base{ID: "Switch-1", ParentPath: domain.FolderEquipment}, DisplayName: "Switch-1"})
+
+	result, err := CSV(c, TypeVLANs, strings.NewReader("id,name,description\n10,mgmt,Management\n20,iot,\n10,again,\n5000,big,\n"))
+	if err != nil {
+		t.Fatalf("CSV() error: %v", err)
+	}
+	if len(result.Added) != 2 || len(result.Errors) != 2 || c.FindVLANByID(20) == nil {
+		t.Fatalf("CSV() = %+v, want VLANs 10 and 20", result)
+	}
+
+	input := `equipment,port,type,speed,name,native_vlan,tagged_mode,tagged_vlans,lag_group,lag_mode
+switch-1,1,RJ45,1G,uplink,10,Custom,"10,20",,
+Switch-1,2,RJ45,1G,lag-a,10,AllowAll,,self,802.3ad
+Switch-1,3,RJ45,1G,lag-b,20,,,2,802.3ad
+Switch-1,1,RJ45,1G,dup,,,,,
+Switch-1,4,RJ45,1G,UPLINK,,,,,
+Switch-1,5,RJ45,1G,,30,,,,
+Switch-1,6,RJ45,1G,,,Trunk,,,
+Router,1,RJ45,1G,,,,,,
+`
+	result, err = CSV(c, TypePorts, strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("CSV() error: %v", err)
+	}
+	if len(result.Added) != 3 || len(result.Errors) != 5 {
+		t.Fatalf("CSV() = %+v, want 3 ports added", result)
+	}
+	uplink := c.Get("Equipment -> Switch-1 -> 1").(*domain.Port)
+	if uplink.TaggedVLANMode != domain.TaggedVLANModeCustom || len(uplink.TaggedVLANIDs) != 2 {
+		t.Errorf("uplink = %+v, want custom tagged VLANs 10 and 20", uplink)
+	}
+	member := c.Get("Equipment -> Switch-1 -> 3").(*domain.Port)
+	if member.LAGGroup != 2 || member.NativeVLANID != 0 {
+		t.Errorf("LAG member = %+v, want VLAN settings left to the master", member)
+	}
+}
+
+func TestCSVDNS(t *testing.T) {
+	c := newTestCatalog()
+	pool := c.Get("Networks -> 10.0.0.0/16 -> 10.0.1.0/24")
+	nas := &domain.IP{Base: domain.Base{ID: "10.0.1.10", ParentPath: pool.GetPath()}, DisplayName: "nas"}
+	gw := &domain.IP{Base: domain.Base{ID: "10.0.1.1", ParentPath: pool.GetPath()}, DisplayName: "gw"}
+	c.Put(nas)
+	c.Put(gw)
+	zone := &domain.DNSZone{Base: domain.Base{ID: "example.com", ParentPath: domain.FolderDNS}}
+	c.Put(zone)
+	c.Put(&domain.DNSRecord{Base: domain.Base{ID: "legacy.example.com", ParentPath: domain.FolderDNS}, RecordType: "A", RecordValue: "10.0.1.82"})
+
+	input := `fqdn,type,value,ttl
+www.example.com,a,10.0.1.80,60
+files.home,ALIAS,10.0.1.10 10.0.1.1,
+storage.home,HOST,nas,
+www.example.com,CNAME,web.example.com,
+mail.home,MX,mail.home,
+ghost.home,ALIAS,10.0.1.99,
+slow.home,A,10.0.1.81,forever
+Legacy.example.com,A,10.0.1.83,
+_dmarc.example.com,TXT,v=DMARC1; p=none,
+`
+	result, err := CSV(c, TypeDNS, strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("CSV() error: %v", err)
+	}
+	if len(result.Added) != 4 || len(result.Errors) != 5 {
+		t.Fatalf("CSV() = %+v, want 4 records added", result)
+	}
+	if err := result.Errors[4].Err; !strings.Contains(err.Error(), "already exists at DNS -> legacy.example.com") {
+		t.Errorf("Errors[4] = %v, want the flat record outside the zone to count as a duplicate", err)
+	}
+	www, ok := c.Get(zone.GetPath() + " -> www.example.com").(*domain.DNSRecord)
+	if !ok || www.RecordType != "A" || www.TTL != 60 {
+		t.Errorf("www = %+v, want an A record with TTL 60 in example.com", www)
+	}
+	files := c.Get("DNS -> files.home").(*domain.DNSRecord)
+	if files.ReservedIPPath != nas.GetPath() || len(files.ReservedIPPaths) != 1 || files.ReservedIPPaths[0] != gw.GetPath() {
+		t.Errorf("files = %+v, want an alias to nas and gw", files)
+	}
+	if storage := c.Get("DNS -> storage.home").(*domain.DNSRecord); storage.ReservedHost != "nas" {
+		t.Errorf("storage = %+v, want a host alias of nas", storage)
+	}
+	if c.Get(zone.GetPath()+" -> _dmarc.example.com") == nil {
+		t.Error("records with underscore labels must import")
+	}
+}
+
+func TestParseLeases(t *testing.T) {
+	dnsmasq := `1760000000 AA:BB:CC:00:00:01 10.0.1.10 nas 01:aa:bb:cc:00:00:01
+1760000000 aa:bb:cc:00:00:02 10.0.1.20 * *
+duid 00:01:00:01:2c:1f:6a:00
+1760000000 23456 fd00::20 phone 00:01:00:01
+1760000100 aa:bb:cc:00:00:03 10.0.1.20 laptop *
+`
+	dhcpd := `# The format of this file is documented in the dhcpd.leases(5) manual page.
+lease 10.0.1.10 {
+  starts 4 2025/10/09 10:00:00;
+  binding state active;
+  next binding state free;
+  hardware ethernet aa:bb:cc:00:00:01;
+  client-hostname "nas";
+}
+lease 10.0.1.30 {
+  binding state free;
+  hardware ethernet aa:bb:cc:00:00:04;
+}
+server-duid "\000\001";
+`
+	kea := `address,hwaddr,client_id,valid_lifetime,expire,subnet_id,fqdn_fwd,fqdn_rev,hostname,state,user_context,pool_id
+10.0.1.10,aa:bb:cc:00:00:01,,3600,1760000000,1,0,0,nas.home.,0,,0
+10.0.1.40,aa:bb:cc:00:00:05,,3600,1760000000,1,0,0,,2,,0
+`
+	for _, tt := range []struct {
+		format, input string
+		want          string
+	}{
+		{domain.DHCPFormatDnsmasq, dnsmasq, "10.0.1.10 aa:bb:cc:00:00:01 nas|10.0.1.20 aa:bb:cc:00:00:03 laptop|fd00::20  phone"},
+		{domain.DHCPFormatDhcpd, dhcpd, "10.0.1.10 aa:bb:cc:00:00:01 nas"},
+		{domain.DHCPFormatKea, kea, "10.0.1.10 aa:bb:cc:00:00:01 nas.home"},
+	} {
+		leases, err := ParseLeases(tt.format, strings.NewReader(tt.input))
+		if err != nil {
+			t.Fatalf("ParseLeases(%s) error: %v", tt.format, err)
+		}
+		var got []string
+		for _, lease := range leases {
+			got = append(got, lease.Address.String()+" "+lease.MAC+" "+lease.Hostname)
+		}
+		if strings.Join(got, "|") != tt.want {
+			t.Errorf("ParseLeases(%s) = %q, want %q", tt.format, got, tt.want)
+		}
+	}
+
+	for format, input := range map[string]string{
+		domain.DHCPFormatDnsmasq: "1760000000 aa:bb:cc:00:00:01 10.0.1 nas *\n",
+		domain.DHCPFormatDhcpd:   "lease 10.0.1.10 {\n  binding state active;\n",
+		domain.DHCPFormatKea:     "hwaddr,hostname\n",
+		"isc":                    "",
+	} {
+		if _, err := ParseLeases(format, strings.NewReader(input)); err == nil {
+			t.Errorf("ParseLeases(%s): expected error", format)
+		}
+	}
+}
+
+func TestReconcileLeases(t *testing.T) {
+	c := newTestCatalog()
+	pool := c.Get("Networks -> 10.0.0.0/16 -> 10.0.1.0/24")
+	other := &domain.Network{Base: domain.Base{ID: "10.0.3.0/24", ParentPath: "Networks -> 10.0.0.0/16"}, AllocationMode: domain.AllocationModeHosts}
+	c.Put(other)
+	reserve := func(parent domain.Item, addr, name, mac string) *domain.IP {
+		ip := &domain.IP{Base: domain.Base{ID: addr, ParentPath: parent.GetPath()}, DisplayName: name, MACAddress: mac}
+		c.Put(ip)
+		return ip
+	}
+	nas := reserve(pool, "10.0.1.10", "nas", "aa:bb:cc:00:00:01")
+	printer := reserve(pool, "10.0.1.11", "printer", "aa:bb:cc:00:00:02")
+	tv := reserve(pool, "10.0.1.12", "tv", "aa:bb:cc:00:00:03")
+	reserve(pool, "10.0.1.13", "switch", "")
+	reserve(other, "10.0.3.10", "nas-iot", "aa:bb:cc:00:00:01")
+	reserve(other, "10.0.3.11", "camera", "aa:bb:cc:00:00:09")
+
+	leases := []Lease{
+		{Line: 1, Address: netip.MustParseAddr("10.0.1.10"), MAC: "aa:bb:cc:00:00:01", Hostname: "nas"},
+		{Line: 2, Address: netip.MustParseAddr("10.0.1.11"), MAC: "aa:bb:cc:00:00:99", Hostname: "printer"},
+		{Line: 3, Address: netip.MustParseAddr("10.0.1.13"), MAC: "aa:bb:cc:00:00:13"},
+		{Line: 4, Address: netip.MustParseAddr("10.0.1.50"), MAC: "aa:bb:cc:00:00:03", Hostname: "tv"},
+		{Line: 5, Address: netip.MustParseAddr("10.0.1.60"), MAC: "aa:bb:cc:00:00:60", Hostname: "laptop"},
+		{Line: 6, Address: netip.MustParseAddr("10.0.1.61"), MAC: "aa:bb:cc:00:00:61"},
+		{Line: 7, Address: netip.MustParseAddr("10.0.2.5"), MAC: "aa:bb:cc:00:00:25", Hostname: "guest"},
+	}
+	report := Reconcile(c, leases)
+	var undocumented []string
+	for _, lease := range report.Undocumented {
+		undocumented = append(undocumented, lease.Address.String())
+	}
+	if strings.Join(undocumented, " ") != "10.0.1.60 10.0.1.61 10.0.2.5" {
+		t.Errorf("Undocumented = %v", undocumented)
+	}
+	if len(report.Mismatches) != 2 || report.Mismatches[0].Reservation != printer || report.Mismatches[1].Reservation != tv || report.Mismatches[1].Lease.Line != 4 {
+		t.Errorf("Mismatches = %+v, want the printer's MAC and the tv's address", report.Mismatches)
+	}
+	if len(report.Stale) != 0 {
+		t.Errorf("Stale = %v, want none: the other pool is not covered and the rest is leased", report.Stale)
+	}
+	if report := Reconcile(c, leases[1:]); len(report.Stale) != 1 || report.Stale[0] != nas {
+		t.Errorf("Stale = %v, want nas", report.Stale)
+	}
+
+	result := ReserveLeases(c, report.Undocumented, "Imported from dnsmasq.leases")
+	if len(result.Added) != 2 || len(result.Errors) != 1 || result.Errors[0].Line != 7 {
+		t.Fatalf("ReserveLeases() = %+v, want the guest outside every Host Pool to fail", result)
+	}
+	if ip, ok := c.Get(pool.GetPath() + " -> 10.0.1.61").(*domain.IP); !ok || ip.DisplayName != "dhcp-10-0-1-61" || ip.MACAddress != "aa:bb:cc:00:00:61" {
+		t.Errorf("reservation without hostname = %+v", ip)
+	}
+}
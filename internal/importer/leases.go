@@ -0,0 +1,301 @@
+package importer
+
+import (
+	"bufio"
+	"cmp"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/netip"
+	"slices"
+	"strings"
+
+	"github.com/plumber-cd/ez-ipam/internal/domain"
+)
+
+// Lease is an address a DHCP server handed out. MAC is empty for leases
+// without a hardware address, such as DHCPv6 leases of dnsmasq.
+type Lease struct {
+	Line     int
+	Address  netip.Addr
+	MAC      string
+	Hostname string
+}
+
+// ParseLeases reads the active leases of a lease file: a dnsmasq.leases
+// file, an ISC dhcpd.leases file or a Kea memfile lease CSV, named by the
+// DHCP formats of the configuration. When an address is leased more than
+// once, the last lease in the file wins. Leases are sorted by address.
+func ParseLeases(format string, r io.Reader) ([]Lease, error) {
+	var (
+		leases []Lease
+		err    error
+	)
+	switch format {
+	case domain.DHCPFormatDnsmasq:
+		leases, err = parseDnsmasqLeases(r)
+	case domain.DHCPFormatDhcpd:
+		leases, err = parseDhcpdLeases(r)
+	case domain.DHCPFormatKea:
+		leases, err = parseKeaLeases(r)
+	default:
+		return nil, fmt.Errorf("unknown lease format %q: must be one of %s", format, strings.Join(domain.DHCPFormats, ", "))
+	}
+	if err != nil {
+		return nil, err
+	}
+	latest := map[netip.Addr]Lease{}
+	for _, lease := range leases {
+		latest[lease.Address] = lease
+	}
+	leases = leases[:0]
+	for _, lease := range latest {
+		leases = append(leases, lease)
+	}
+	slices.SortFunc(leases, func(l, r Lease) int { return l.Address.Compare(r.Address) })
+	return leases, nil
+}
+
+// newLease returns the lease of addr. An unparsable MAC is dropped, and
+// "*" or an empty hostname means none.
+func newLease(line int, addr, mac, hostname string) (Lease, error) {
+	address, err := netip.ParseAddr(addr)
+	if err != nil {
+		return Lease{}, fmt.Errorf("line %d: invalid address %q", line, addr)
+	}
+	normalizedMAC, _ := domain.NormalizeMACAddress(mac)
+	hostname = strings.TrimSuffix(strings.Trim(hostname, `"`), ".")
+	if hostname == "*" {
+		hostname = ""
+	}
+	return Lease{Line: line, Address: address, MAC: normalizedMAC, Hostname: hostname}, nil
+}
+
+// parseDnsmasqLeases reads "expiry mac address hostname client-id" lines.
+// DHCPv6 leases carry an IAID instead of a MAC.
+func parseDnsmasqLeases(r io.Reader) ([]Lease, error) {
+	var leases []Lease
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || fields[0] == "duid" {
+			continue
+		}
+		if len(fields) < 4 {
+			return nil, fmt.Errorf("line %d: expected at least 4 fields, got %d", line, len(fields))
+		}
+		lease, err := newLease(line, fields[2], fields[1], fields[3])
+		if err != nil {
+			return nil, err
+		}
+		leases = append(leases, lease)
+	}
+	return leases, scanner.Err()
+}
+
+// parseDhcpdLeases reads the "lease <address> { ... }" statements, keeping
+// those whose binding state is active. Other statements are skipped.
+func parseDhcpdLeases(r io.Reader) ([]Lease, error) {
+	var (
+		leases  []Lease
+		current *Lease
+		state   string
+	)
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		if current == nil {
+			addr, ok := strings.CutPrefix(text, "lease ")
+			if !ok || !strings.HasSuffix(addr, "{") {
+				continue
+			}
+			lease, err := newLease(line, strings.TrimSpace(strings.TrimSuffix(addr, "{")), "", "")
+			if err != nil {
+				return nil, err
+			}
+			current, state = &lease, ""
+			continue
+		}
+		if text == "}" {
+			if state == "" || state == "active" {
+				leases = append(leases, *current)
+			}
+			current = nil
+			continue
+		}
+		fields := strings.Fields(strings.TrimSuffix(text, ";"))
+		switch {
+		case len(fields) == 3 && fields[0] == "hardware" && fields[1] == "ethernet":
+			current.MAC, _ = domain.NormalizeMACAddress(fields[2])
+		case len(fields) == 2 && fields[0] == "client-hostname":
+			current.Hostname = strings.TrimSuffix(strings.Trim(fields[1], `"`), ".")
+		case len(fields) == 3 && fields[0] == "binding" && fields[1] == "state":
+			state = fields[2]
+		}
+	}
+	if current != nil {
+		return nil, fmt.Errorf("line %d: lease %s is not closed", current.Line, current.Address)
+	}
+	return leases, scanner.Err()
+}
+
+// parseKeaLeases reads a Kea memfile lease CSV, keeping the leases in the
+// default state 0. Rows are appended as leases change, so later rows win.
+func parseKeaLeases(r io.Reader) ([]Lease, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	columns := map[string]int{}
+	for i, name := range header {
+		columns[strings.TrimSpace(name)] = i
+	}
+	if _, ok := columns["address"]; !ok {
+		return nil, errors.New("missing column \"address\"")
+	}
+	field := func(record []string, name string) string {
+		if i, ok := columns[name]; ok && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+	var leases []Lease
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return leases, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		line, _ := reader.FieldPos(0)
+		if state := field(record, "state"); state != "" && state != "0" {
+			continue
+		}
+		lease, err := newLease(line, field(record, "address"), field(record, "hwaddr"), field(record, "hostname"))
+		if err != nil {
+			return nil, err
+		}
+		leases = append(leases, lease)
+	}
+}
+
+// Mismatch is a lease that disagrees with a reservation: the address is
+// reserved for another MAC, or a MAC reserved in the same Host Pool got
+// another address.
+type Mismatch struct {
+	Lease       Lease
+	Reservation *domain.IP
+}
+
+// Reconciliation compares leases with the reservations.
+type Reconciliation struct {
+	// Undocumented are leases whose address and MAC match no reservation.
+	Undocumented []Lease
+	Mismatches   []Mismatch
+	// Stale are reservations with a MAC that no lease matches, in the Host
+	// Pools the leases cover. Pools served by another DHCP server are left
+	// alone.
+	Stale []*domain.IP
+}
+
+// Reconcile matches leases against the reservations by address and MAC.
+func Reconcile(catalog *domain.Catalog, leases []Lease) Reconciliation {
+	var reservations []*domain.IP
+	for _, item := range catalog.All() {
+		if ip, ok := item.(*domain.IP); ok {
+			reservations = append(reservations, ip)
+		}
+	}
+	slices.SortFunc(reservations, func(l, r *domain.IP) int {
+		return cmp.Or(netip.MustParseAddr(l.ID).Compare(netip.MustParseAddr(r.ID)), strings.Compare(l.GetPath(), r.GetPath()))
+	})
+	byAddr := map[netip.Addr][]*domain.IP{}
+	byMAC := map[string][]*domain.IP{}
+	for _, ip := range reservations {
+		if addr, err := netip.ParseAddr(ip.ID); err == nil {
+			byAddr[addr] = append(byAddr[addr], ip)
+		}
+		if mac, err := domain.NormalizeMACAddress(ip.MACAddress); err == nil && mac != "" {
+			byMAC[mac] = append(byMAC[mac], ip)
+		}
+	}
+
+	var result Reconciliation
+	matched := map[*domain.IP]bool{}
+	covered := map[string]bool{}
+	for _, lease := range leases {
+		atAddr := byAddr[lease.Address]
+		// A MAC reserved in another Host Pool is another interface of the
+		// same device, not a mismatch.
+		var withMAC []*domain.IP
+		if pool := catalog.HostPoolContaining(lease.Address); pool != nil {
+			covered[pool.GetPath()] = true
+			if lease.MAC != "" {
+				for _, ip := range byMAC[lease.MAC] {
+					if ip.GetParentPath() == pool.GetPath() && !slices.Contains(atAddr, ip) {
+						withMAC = append(withMAC, ip)
+					}
+				}
+			}
+		}
+		if len(atAddr) == 0 && len(withMAC) == 0 {
+			result.Undocumented = append(result.Undocumented, lease)
+			continue
+		}
+		reservedForMAC := false
+		for _, ip := range atAddr {
+			matched[ip] = true
+			mac, _ := domain.NormalizeMACAddress(ip.MACAddress)
+			if mac == lease.MAC {
+				reservedForMAC = true
+			} else if mac != "" && lease.MAC != "" {
+				result.Mismatches = append(result.Mismatches, Mismatch{Lease: lease, Reservation: ip})
+			}
+		}
+		for _, ip := range withMAC {
+			matched[ip] = true
+			if !reservedForMAC {
+				result.Mismatches = append(result.Mismatches, Mismatch{Lease: lease, Reservation: ip})
+			}
+		}
+	}
+	for _, ip := range reservations {
+		if ip.MACAddress != "" && !matched[ip] && covered[ip.GetParentPath()] {
+			result.Stale = append(result.Stale, ip)
+		}
+	}
+	return result
+}
+
+// ReserveLeases reserves the leases in the deepest Host Pools containing
+// them, named after their hostname or, without one, their address. Leases
+// that cannot be reserved are reported by their line in the lease file.
+func ReserveLeases(catalog *domain.Catalog, leases []Lease, description string) Result {
+	result := Result{Rows: len(leases)}
+	for _, lease := range leases {
+		name := lease.Hostname
+		if name == "" {
+			name = "dhcp-" + strings.NewReplacer(".", "-", ":", "-").Replace(lease.Address.String())
+		}
+		ip, err := newReservation(catalog, lease.Address, name, lease.MAC, description)
+		if err == nil {
+			err = catalog.Add(ip)
+		}
+		if err != nil {
+			result.Errors = append(result.Errors, RowError{Line: lease.Line, Err: err})
+			continue
+		}
+		result.Added = append(result.Added, ip)
+	}
+	return result
+}
@@ -1,9 +1,11 @@
 package store
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 
 	"github.com/plumber-cd/ez-ipam/internal/domain"
@@ -13,6 +15,7 @@ import (
 const (
 	DataDirName      = ".ez-ipam"
 	MarkdownFileName = "EZ-IPAM.md"
+	ConfigFileName   = "config.yaml"
 
 	networksDirName  = "networks"
 	ipsDirName       = "ips"
@@ -22,6 +25,11 @@ const (
 	equipmentDirName = "equipment"
 	portsDirName     = "ports"
 	dnsDirName       = "dns"
+	dnsZonesDirName  = "dns_zones"
+
+	// TemplatesDirName holds user-authored layout templates. The app only
+	// reads them; Save carries the directory over unchanged.
+	TemplatesDirName = "templates"
 )
 
 // Load reads all YAML files from the data directory and returns a populated Catalog.
@@ -57,7 +65,7 @@ func Load(dir string) (*domain.Catalog, error) {
 	catalog.Put(&domain.StaticFolder{
 		Base:        domain.Base{ID: domain.FolderDNS},
 		Index:       5,
-		Description: "Manage DNS records and IP aliases here.",
+		Description: "Manage DNS zones, records and IP aliases here.",
 	})
 
 	dataDir := filepath.Join(dir, DataDirName)
@@ -152,6 +160,13 @@ func Load(dir string) (*domain.Catalog, error) {
 		}
 		return p, nil
 	})
+	loadDir(dnsZonesDirName, func(bytes []byte) (domain.Item, error) {
+		z := &domain.DNSZone{}
+		if err := yaml.Unmarshal(bytes, z); err != nil {
+			return nil, err
+		}
+		return z, nil
+	})
 	loadDir(dnsDirName, func(bytes []byte) (domain.Item, error) {
 		r := &domain.DNSRecord{}
 		if err := yaml.Unmarshal(bytes, r); err != nil {
@@ -164,15 +179,41 @@ func Load(dir string) (*domain.Catalog, error) {
 		return nil, loadErr
 	}
 
-	// Normalize and validate all loaded items.
+	configBytes, err := os.ReadFile(filepath.Join(dataDir, ConfigFileName))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("read %s: %w", ConfigFileName, err)
+	}
+	if err == nil {
+		if err := yaml.Unmarshal(configBytes, &catalog.Config); err != nil {
+			return nil, fmt.Errorf("unmarshal %s: %w", ConfigFileName, err)
+		}
+		if err := catalog.Config.Validate(); err != nil {
+			return nil, fmt.Errorf("validate %s: %w", ConfigFileName, err)
+		}
+	}
+
+	templates, err := loadTemplates(filepath.Join(dataDir, TemplatesDirName))
+	if err != nil {
+		return nil, err
+	}
+	catalog.Templates = templates
+
+	// Normalize and validate all loaded items. DNS data saved before it was
+	// checked is kept and reported, so older catalogs still open.
 	for _, item := range catalog.All() {
 		if z, ok := item.(*domain.Zone); ok {
 			z.Normalize()
 		}
 		if err := item.Validate(catalog); err != nil {
+			var dataErr *domain.RecordDataError
+			if errors.As(err, &dataErr) {
+				catalog.LoadWarnings = append(catalog.LoadWarnings, fmt.Sprintf("%s: %v", item.GetPath(), err))
+				continue
+			}
 			return nil, fmt.Errorf("validate %s: %w", item.GetPath(), err)
 		}
 	}
+	slices.Sort(catalog.LoadWarnings)
 
 	return catalog, nil
 }
@@ -191,16 +232,24 @@ func Save(dir string, catalog *domain.Catalog) error {
 	equipmentTmpDir := filepath.Join(dataTmpDir, equipmentDirName)
 	portsTmpDir := filepath.Join(dataTmpDir, portsDirName)
 	dnsTmpDir := filepath.Join(dataTmpDir, dnsDirName)
+	dnsZonesTmpDir := filepath.Join(dataTmpDir, dnsZonesDirName)
 
 	if err := os.RemoveAll(dataTmpDir); err != nil {
 		return fmt.Errorf("remove tmp dir: %w", err)
 	}
-	for _, d := range []string{networksTmpDir, ipsTmpDir, vlansTmpDir, ssidsTmpDir, zonesTmpDir, equipmentTmpDir, portsTmpDir, dnsTmpDir} {
+	for _, d := range []string{networksTmpDir, ipsTmpDir, vlansTmpDir, ssidsTmpDir, zonesTmpDir, equipmentTmpDir, portsTmpDir, dnsTmpDir, dnsZonesTmpDir} {
 		if err := os.MkdirAll(d, 0755); err != nil {
 			return fmt.Errorf("create %s: %w", d, err)
 		}
 	}
 
+	// Only persist settings that differ from the defaults.
+	if !catalog.Config.IsZero() {
+		if err := writeYAML(filepath.Join(dataTmpDir, ConfigFileName), catalog.Config); err != nil {
+			return err
+		}
+	}
+
 	for _, item := range catalog.All() {
 		switch m := item.(type) {
 		case *domain.StaticFolder:
@@ -250,12 +299,24 @@ func Save(dir string, catalog *domain.Catalog) error {
 				return err
 			}
 		case *domain.DNSRecord:
-			if err := writeYAML(filepath.Join(dnsTmpDir, safeFileNameSegment(m.ID)+".yaml"), m); err != nil {
+			fileName := safeFileNameSegment(m.ID)
+			if zone := catalog.ZoneOf(m); zone != nil {
+				fileName = safeFileNameSegment(zone.ID) + "_" + fileName
+			}
+			if err := writeYAML(filepath.Join(dnsTmpDir, fileName+".yaml"), m); err != nil {
+				return err
+			}
+		case *domain.DNSZone:
+			if err := writeYAML(filepath.Join(dnsZonesTmpDir, safeFileNameSegment(m.ID)+".yaml"), m); err != nil {
 				return err
 			}
 		}
 	}
 
+	if err := copyDir(filepath.Join(dataDir, TemplatesDirName), filepath.Join(dataTmpDir, TemplatesDirName)); err != nil {
+		return err
+	}
+
 	if err := os.RemoveAll(dataOldDir); err != nil {
 		return fmt.Errorf("remove old dir: %w", err)
 	}
@@ -279,6 +340,76 @@ func Save(dir string, catalog *domain.Catalog) error {
 	return nil
 }
 
+// loadTemplates reads and validates every layout template in dir, ordered by
+// name. A template without a name is named after its file.
+func loadTemplates(dir string) ([]*domain.LayoutTemplate, error) {
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("read %s directory: %w", dir, err)
+	}
+	var templates []*domain.LayoutTemplate
+	seen := map[string]string{}
+	for _, f := range files {
+		ext := filepath.Ext(f.Name())
+		if f.IsDir() || (ext != ".yaml" && ext != ".yml") {
+			continue
+		}
+		bytes, err := os.ReadFile(filepath.Join(dir, f.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("read %s: %w", f.Name(), err)
+		}
+		t := &domain.LayoutTemplate{}
+		if err := yaml.UnmarshalStrict(bytes, t); err != nil {
+			return nil, fmt.Errorf("unmarshal %s: %w", f.Name(), err)
+		}
+		if strings.TrimSpace(t.Name) == "" {
+			t.Name = strings.TrimSuffix(f.Name(), ext)
+		}
+		if err := t.Validate(); err != nil {
+			return nil, fmt.Errorf("validate %s: %w", f.Name(), err)
+		}
+		if other, ok := seen[t.Name]; ok {
+			return nil, fmt.Errorf("validate %s: template %s is already defined in %s", f.Name(), t.Name, other)
+		}
+		seen[t.Name] = f.Name()
+		templates = append(templates, t)
+	}
+	slices.SortFunc(templates, func(l, r *domain.LayoutTemplate) int {
+		return strings.Compare(l.Name, r.Name)
+	})
+	return templates, nil
+}
+
+// copyDir copies the regular files of src into dst. A missing src is not an error.
+func copyDir(src, dst string) error {
+	files, err := os.ReadDir(src)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("read %s directory: %w", src, err)
+	}
+	if err := os.MkdirAll(dst, 0755); err != nil {
+		return fmt.Errorf("create %s: %w", dst, err)
+	}
+	for _, f := range files {
+		if !f.Type().IsRegular() {
+			continue
+		}
+		bytes, err := os.ReadFile(filepath.Join(src, f.Name()))
+		if err != nil {
+			return fmt.Errorf("read %s: %w", f.Name(), err)
+		}
+		if err := os.WriteFile(filepath.Join(dst, f.Name()), bytes, 0644); err != nil {
+			return fmt.Errorf("write %s: %w", f.Name(), err)
+		}
+	}
+	return nil
+}
+
 func writeYAML(fileName string, v interface{}) error {
 	bytes, err := yaml.Marshal(v)
 	if err != nil {
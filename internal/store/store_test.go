@@ -3,6 +3,7 @@ package store
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/plumber-cd/ez-ipam/internal/domain"
@@ -102,6 +103,21 @@ func TestSaveAndLoad(t *testing.T) {
 	}
 	catalog.Put(port)
 
+	// Add a DNS zone with a record.
+	dnsZone := &domain.DNSZone{
+		Base:        domain.Base{ID: "example.com", ParentPath: "DNS"},
+		TTL:         600,
+		NameServers: []string{"ns1.example.com"},
+	}
+	catalog.Put(dnsZone)
+	record := &domain.DNSRecord{
+		Base:        domain.Base{ID: "www.example.com", ParentPath: dnsZone.GetPath()},
+		RecordType:  "A",
+		RecordValue: "10.0.0.1",
+		TTL:         60,
+	}
+	catalog.Put(record)
+
 	// Save.
 	if err := Save(dir, catalog); err != nil {
 		t.Fatalf("Save() error: %v", err)
@@ -185,6 +201,16 @@ func TestSaveAndLoad(t *testing.T) {
 	if len(portChildren) != 1 {
 		t.Fatalf("expected 1 port, got %d", len(portChildren))
 	}
+
+	// Verify DNS zone and its record loaded.
+	loadedZone, ok := loaded.Get(dnsZone.GetPath()).(*domain.DNSZone)
+	if !ok || loadedZone.TTL != 600 || len(loadedZone.NameServers) != 1 {
+		t.Fatalf("DNS zone not loaded: %+v", loadedZone)
+	}
+	loadedRecord, ok := loaded.Get(record.GetPath()).(*domain.DNSRecord)
+	if !ok || loadedRecord.TTL != 60 {
+		t.Fatalf("DNS record not loaded: %+v", loadedRecord)
+	}
 }
 
 func TestLoadEmptyDir(t *testing.T) {
@@ -204,13 +230,39 @@ func TestLoadEmptyDir(t *testing.T) {
 
 	// Verify directories were created.
 	dataDir := filepath.Join(dir, DataDirName)
-	for _, subDir := range []string{networksDirName, ipsDirName, vlansDirName, ssidsDirName, zonesDirName, equipmentDirName, portsDirName, dnsDirName} {
+	for _, subDir := range []string{networksDirName, ipsDirName, vlansDirName, ssidsDirName, zonesDirName, equipmentDirName, portsDirName, dnsDirName, dnsZonesDirName} {
 		if _, err := os.Stat(filepath.Join(dataDir, subDir)); err != nil {
 			t.Errorf("expected %s directory to be created: %v", subDir, err)
 		}
 	}
 }
 
+func TestLoadWarnsAboutStoredDNSConflicts(t *testing.T) {
+	dir := t.TempDir()
+	catalog, err := Load(dir)
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	// Saved before CNAMEs had to be alone at their name.
+	catalog.Put(&domain.DNSRecord{Base: domain.Base{ID: "www.home", ParentPath: domain.FolderDNS}, RecordType: "CNAME", RecordValue: "web.home"})
+	catalog.Put(&domain.DNSRecord{Base: domain.Base{ID: "WWW.home", ParentPath: domain.FolderDNS}, RecordType: "TXT", RecordValue: "legacy"})
+	if err := Save(dir, catalog); err != nil {
+		t.Fatalf("Save() error: %v", err)
+	}
+
+	loaded, err := Load(dir)
+	if err != nil {
+		t.Fatalf("Load() must accept stored DNS conflicts, got %v", err)
+	}
+	if len(loaded.LoadWarnings) != 2 || !strings.HasPrefix(loaded.LoadWarnings[0], "DNS -> WWW.home: ") ||
+		!strings.Contains(loaded.LoadWarnings[1], "must be the only record") {
+		t.Errorf("LoadWarnings = %v, want both conflicting records reported", loaded.LoadWarnings)
+	}
+	if loaded.Get("DNS -> WWW.home") == nil {
+		t.Error("conflicting records must still be loaded")
+	}
+}
+
 func TestSaveAtomicRollback(t *testing.T) {
 	dir := t.TempDir()
 
@@ -249,3 +301,90 @@ func TestSaveAtomicRollback(t *testing.T) {
 		t.Error("tmp directory should be removed after save")
 	}
 }
+
+func TestConfigSaveAndLoad(t *testing.T) {
+	dir := t.TempDir()
+
+	catalog, err := Load(dir)
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	if err := Save(dir, catalog); err != nil {
+		t.Fatalf("Save() error: %v", err)
+	}
+	configPath := filepath.Join(dir, DataDirName, ConfigFileName)
+	if _, err := os.Stat(configPath); !os.IsNotExist(err) {
+		t.Fatalf("expected no %s for default config, stat error: %v", ConfigFileName, err)
+	}
+
+	catalog.Config.PoolWarnThresholdPercent = 75
+	if err := Save(dir, catalog); err != nil {
+		t.Fatalf("Save() error: %v", err)
+	}
+	loaded, err := Load(dir)
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	if loaded.Config.PoolWarnThreshold() != 75 {
+		t.Errorf("PoolWarnThreshold() = %d, want 75", loaded.Config.PoolWarnThreshold())
+	}
+
+	catalog.Config.DHCPExports = []domain.ExportTarget{{Format: domain.DHCPFormatKea, Path: "dhcp/kea.json"}}
+	if err := Save(dir, catalog); err != nil {
+		t.Fatalf("Save() error: %v", err)
+	}
+	if loaded, err = Load(dir); err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	if len(loaded.Config.DHCPExports) != 1 || loaded.Config.DHCPExports[0].Path != "dhcp/kea.json" {
+		t.Errorf("DHCPExports = %+v", loaded.Config.DHCPExports)
+	}
+
+	if err := os.WriteFile(configPath, []byte("pool_warn_threshold_percent: 150\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(dir); err == nil {
+		t.Fatal("expected Load() to reject an out-of-range threshold")
+	}
+}
+
+func TestTemplatesLoadAndSurviveSave(t *testing.T) {
+	dir := t.TempDir()
+	templatesDir := filepath.Join(dir, DataDirName, TemplatesDirName)
+	if err := os.MkdirAll(templatesDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	site := "# Standard branch layout\nprefix_len: 23\nsubnets:\n" +
+		"  - name: mgmt\n    prefix_len: 26\n    offset: 0\n    gateway_offset: 1\n" +
+		"  - name: users\n    prefix_len: 24\n    offset: 1\n"
+	if err := os.WriteFile(filepath.Join(templatesDir, "branch.yaml"), []byte(site), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	catalog, err := Load(dir)
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	if len(catalog.Templates) != 1 || catalog.Template("branch") == nil || len(catalog.Template("branch").Subnets) != 2 {
+		t.Fatalf("templates = %+v", catalog.Templates)
+	}
+
+	if err := Save(dir, catalog); err != nil {
+		t.Fatalf("Save() error: %v", err)
+	}
+	saved, err := os.ReadFile(filepath.Join(templatesDir, "branch.yaml"))
+	if err != nil {
+		t.Fatalf("template should survive Save(): %v", err)
+	}
+	if string(saved) != site {
+		t.Errorf("template rewritten on Save():\n%s", saved)
+	}
+
+	overlapping := "prefix_len: 24\nsubnets:\n  - {name: a, prefix_len: 25, offset: 0}\n  - {name: b, prefix_len: 26, offset: 1}\n"
+	if err := os.WriteFile(filepath.Join(templatesDir, "bad.yaml"), []byte(overlapping), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(dir); err == nil || !strings.Contains(err.Error(), "overlap") {
+		t.Fatalf("expected overlap error, got %v", err)
+	}
+}
@@ -0,0 +1,256 @@
+package ui
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+
+	"github.com/plumber-cd/ez-ipam/internal/domain"
+)
+
+const (
+	addressMapPageName = "*address_map*"
+
+	addressMapColumns = 64
+	addressMapRows    = 4
+)
+
+// addressMapVLANColors is the palette used when the map is colored by VLAN.
+var addressMapVLANColors = []string{"aqua", "fuchsia", "orange", "lime", "purple", "teal", "olive", "pink"}
+
+// showAddressMap opens a full-screen proportional map of a Subnet Container's address space.
+func (a *App) showAddressMap(container *domain.Network) {
+	blocks, err := a.Catalog.AddressMap(container)
+	if err != nil {
+		a.setStatus("Error rendering address map: " + err.Error())
+		return
+	}
+	total := new(big.Int)
+	for _, block := range blocks {
+		total.Add(total, block.Size)
+	}
+
+	colorByVLAN := false
+	strip := tview.NewTextView().SetDynamicColors(true).SetWrap(false)
+	strip.SetBorder(true).SetTitle(fmt.Sprintf("Address Map: %s (%s)", container.ID, container.DisplayName))
+	legend := tview.NewTextView().SetDynamicColors(true)
+
+	table := tview.NewTable().SetSelectable(true, false).SetFixed(1, 0)
+	table.SetBorder(true).SetTitle("Blocks")
+	for col, header := range []string{"CIDR", "Mode", "Name", "VLAN", "Share", "Reserved"} {
+		table.SetCell(0, col, tview.NewTableCell(header).SetSelectable(false).SetAttributes(tcell.AttrBold))
+	}
+	for i, block := range blocks {
+		mode, name, vlan, reserved := "Free", "", "", ""
+		if n := block.Network; n != nil {
+			switch n.AllocationMode {
+			case domain.AllocationModeSubnets:
+				mode = "Container"
+			case domain.AllocationModeHosts:
+				mode = "Pool"
+				reserved = fmt.Sprintf("%d (%.0f%%)", block.Reserved, block.Density()*100)
+			}
+			name = n.DisplayName
+			if n.VLANID > 0 {
+				vlan = strconv.Itoa(n.VLANID)
+			}
+		}
+		share, _ := new(big.Float).Quo(new(big.Float).SetInt(block.Size), new(big.Float).SetInt(total)).Float64()
+		for col, text := range []string{block.CIDR, mode, name, vlan, fmt.Sprintf("%.1f%%", share*100), reserved} {
+			table.SetCell(i+1, col, tview.NewTableCell(tview.Escape(text)).SetExpansion(1))
+		}
+	}
+
+	redraw := func() {
+		row, _ := table.GetSelection()
+		strip.SetText(renderAddressMapStrip(blocks, total, row-1, colorByVLAN))
+		scheme := "[blue]█[-] Container  [green]░▒▓█[-] Pool by reservations"
+		if colorByVLAN {
+			scheme = "[white]█[-] No VLAN  [aqua]█[fuchsia]█[orange]█[-] by VLAN ID"
+		}
+		legend.SetText(" " + scheme + "  [gray]·[-] Free  [yellow]█[-] Selected\n" +
+			" Enter: open | a/A: allocate | s: split | c: mode/VLAN colors | Esc: close")
+	}
+	table.SetSelectionChangedFunc(func(row, column int) { redraw() })
+
+	table.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		row, _ := table.GetSelection()
+		var block *domain.AddressBlock
+		if row >= 1 && row <= len(blocks) {
+			block = &blocks[row-1]
+		}
+
+		switch event.Key() {
+		case tcell.KeyEscape, tcell.KeyBS, tcell.KeyBackspace2:
+			a.dismissAddressMap()
+			return nil
+		case tcell.KeyCtrlU:
+			return tcell.NewEventKey(tcell.KeyPgUp, 0, tcell.ModNone)
+		case tcell.KeyCtrlD:
+			return tcell.NewEventKey(tcell.KeyPgDn, 0, tcell.ModNone)
+		case tcell.KeyEnter:
+			if block != nil {
+				a.selectAddressMapBlock(container, *block, nil)
+			}
+			return nil
+		case tcell.KeyRune:
+			switch event.Rune() {
+			case 'q':
+				a.dismissAddressMap()
+				return nil
+			case 'c':
+				colorByVLAN = !colorByVLAN
+				redraw()
+				return nil
+			case 'a', 'A', 's':
+				if block != nil {
+					a.selectAddressMapBlock(container, *block, event)
+				}
+				return nil
+			}
+		}
+		return event
+	})
+
+	table.Select(1, 0)
+	redraw()
+
+	page := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(strip, addressMapRows+2, 0, false).
+		AddItem(legend, 2, 0, false).
+		AddItem(table, 0, 1, true)
+
+	a.Pages.RemovePage(addressMapPageName)
+	a.Pages.AddPage(addressMapPageName, page, true, true)
+	a.TviewApp.SetFocus(table)
+}
+
+func (a *App) dismissAddressMap() {
+	a.Pages.RemovePage(addressMapPageName)
+	a.Pages.SwitchToPage(mainPageName)
+	a.TviewApp.SetFocus(a.NavPanel)
+}
+
+// selectAddressMapBlock closes the map and moves the nav panel to the block.
+// Allocated blocks are opened; unallocated ones are focused, and event (if any)
+// is then replayed against them so allocate/split dialogs open right away.
+// Gaps resolve to the implicit unallocated network covering them.
+func (a *App) selectAddressMapBlock(container *domain.Network, block domain.AddressBlock, event *tcell.EventKey) {
+	n := block.Network
+	if n == nil {
+		implicit, ok := a.Catalog.Lookup(container.GetPath() + " -> " + block.CIDR).(*domain.Network)
+		if !ok {
+			a.setStatus(fmt.Sprintf("%s is not covered by any child network.", block.CIDR))
+			return
+		}
+		n = implicit
+	}
+	a.dismissAddressMap()
+
+	if event == nil && n.AllocationMode != domain.AllocationModeUnallocated {
+		a.openNetwork(n)
+		return
+	}
+	a.focusNetwork(n)
+	if event != nil {
+		a.networkFocusKeyPress(n, event)
+	}
+}
+
+// openNetwork navigates into n, as if it had been selected in the nav panel.
+func (a *App) openNetwork(n *domain.Network) {
+	if a.treeMode {
+		a.treeExpanded[n.GetPath()] = true
+		a.reloadTree(n)
+		return
+	}
+	a.CurrentItem = n
+	a.ReloadMenu(nil)
+	a.onItemSelected(n)
+	a.UpdateKeysLine()
+}
+
+// focusNetwork navigates to the parent of n and highlights n.
+func (a *App) focusNetwork(n *domain.Network) {
+	if a.treeMode {
+		a.reloadTree(n)
+		return
+	}
+	a.CurrentItem = a.Catalog.Get(n.GetParentPath())
+	a.ReloadMenu(n)
+	if a.CurrentItem != nil {
+		a.onItemSelected(a.CurrentItem)
+	}
+	a.CurrentFocus = n
+	a.onItemChanged(n)
+	a.UpdateKeysLine()
+}
+
+// renderAddressMapStrip draws the container as a grid of cells, each covering
+// an equal share of its address space and colored after the block it starts in.
+// The selected block is highlighted in every cell it touches.
+func renderAddressMapStrip(blocks []domain.AddressBlock, total *big.Int, selected int, colorByVLAN bool) string {
+	cells := int64(addressMapColumns * addressMapRows)
+	if total.IsInt64() && total.Int64() < cells {
+		cells = total.Int64()
+	}
+	cellSize := new(big.Int).Quo(total, big.NewInt(cells))
+
+	var sb strings.Builder
+	blockIndex := 0
+	for i := range cells {
+		if i > 0 && i%addressMapColumns == 0 {
+			sb.WriteString("\n")
+		}
+		start := new(big.Int).Mul(cellSize, big.NewInt(i))
+		end := new(big.Int).Add(start, cellSize)
+		for blockIndex < len(blocks)-1 && new(big.Int).Add(blocks[blockIndex].Offset, blocks[blockIndex].Size).Cmp(start) <= 0 {
+			blockIndex++
+		}
+		color, glyph := addressMapCellStyle(blocks[blockIndex], colorByVLAN)
+		if selected >= 0 && selected < len(blocks) {
+			sel := blocks[selected]
+			selEnd := new(big.Int).Add(sel.Offset, sel.Size)
+			if sel.Offset.Cmp(end) < 0 && selEnd.Cmp(start) > 0 {
+				color = "yellow"
+			}
+		}
+		fmt.Fprintf(&sb, "[%s]%s", color, glyph)
+	}
+	sb.WriteString("[-]")
+	return sb.String()
+}
+
+func addressMapCellStyle(block domain.AddressBlock, colorByVLAN bool) (string, string) {
+	n := block.Network
+	if n == nil || n.AllocationMode == domain.AllocationModeUnallocated {
+		return "gray", "·"
+	}
+
+	glyph := "█"
+	if n.AllocationMode == domain.AllocationModeHosts {
+		switch density := block.Density(); {
+		case density == 0:
+			glyph = "░"
+		case density < 0.5:
+			glyph = "▒"
+		case density < 1:
+			glyph = "▓"
+		}
+	}
+
+	switch {
+	case colorByVLAN && n.VLANID > 0:
+		return addressMapVLANColors[n.VLANID%len(addressMapVLANColors)], glyph
+	case colorByVLAN:
+		return "white", glyph
+	case n.AllocationMode == domain.AllocationModeHosts:
+		return "green", glyph
+	default:
+		return "blue", glyph
+	}
+}
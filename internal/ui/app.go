@@ -4,7 +4,6 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
-	"path/filepath"
 	"slices"
 	"strings"
 
@@ -31,15 +30,30 @@ const (
 	NoneVLANOption        = "<none>"
 	DNSModeRecord         = "Record"
 	DNSModeAlias          = "Alias"
+	DNSModeHost           = "Host"
 )
 
 var (
 	GlobalKeys        = []string{"<q> Quit", "<ctrl+s> Save"}
 	LagModeOptions    = []string{LagModeDisabledOption, "802.3ad"}
 	TaggedModeOptions = []string{TaggedModeNoneOption, "AllowAll", "BlockAll", "Custom"}
-	DNSModeOptions    = []string{DNSModeRecord, DNSModeAlias}
+	DNSModeOptions    = []string{DNSModeRecord, DNSModeAlias, DNSModeHost}
 )
 
+// dnsRecordFields are the form fields of each DNS record type, in the order
+// their values make up the record value.
+var dnsRecordFields = map[string][]string{
+	"A":     {"Address"},
+	"AAAA":  {"Address"},
+	"CNAME": {"Target"},
+	"MX":    {"Priority", "Mail Server"},
+	"TXT":   {"Text"},
+	"SRV":   {"Priority", "Weight", "Port", "Target"},
+	"CAA":   {"Flags", "Tag", "Value"},
+	"NS":    {"Name Server"},
+	"PTR":   {"Target"},
+}
+
 // portDialogValues captures port form field values during dialog construction and rebuild.
 type portDialogValues struct {
 	PortNumber       string
@@ -77,16 +91,54 @@ type networkAllocDialogValues struct {
 	Description    string
 	VLANID         string // numeric VLAN ID as string, or "" for none
 	ChildPrefixLen string // only for subnets mode
+	Template       string // layout template name, only for subnets mode
+	Gateway        string // only for hosts mode
+	ExcludedRanges string // comma-separated, only for hosts mode
+	DHCPRanges     string // comma-separated, only for hosts mode
+}
+
+// dhcpOptionsDialogValues captures DHCP options form field values.
+type dhcpOptionsDialogValues struct {
+	Router        string
+	DNSServers    string // comma-separated
+	DomainName    string
+	SearchDomains string // comma-separated
+	NTPServers    string // comma-separated
+	LeaseTime     string
+	Custom        string // code=value pairs, comma-separated
+}
+
+// hostPoolSettings holds the parsed host-pool fields of networkAllocDialogValues.
+type hostPoolSettings struct {
+	Gateway        string
+	ExcludedRanges []string
+	DHCPRanges     []string
+}
+
+func (s hostPoolSettings) applyTo(n *domain.Network) {
+	n.Gateway = s.Gateway
+	n.ExcludedRanges = s.ExcludedRanges
+	n.DHCPRanges = s.DHCPRanges
 }
 
 // dnsRecordDialogValues captures DNS record form values during dialog construction.
 type dnsRecordDialogValues struct {
-	FQDN           string
-	Mode           string
-	RecordType     string
-	RecordValue    string
-	ReservedIPPath string
-	Description    string
+	FQDN        string
+	Mode        string
+	RecordType  string
+	RecordValue string
+	Alias       dnsAlias
+	TTL         string
+	Description string
+}
+
+// dnsZoneDialogValues captures DNS zone form values during dialog construction.
+type dnsZoneDialogValues struct {
+	Domain      string
+	TTL         string
+	Contact     string
+	NameServers string // comma-separated
+	Description string
 }
 
 // App holds all UI state for the EZ-IPAM application.
@@ -99,7 +151,9 @@ type App struct {
 
 	// Layout widgets.
 	PositionLine *tview.TextView
+	NavPages     *tview.Pages
 	NavPanel     *tview.List
+	NavTree      *tview.TreeView
 	DetailsPanel *tview.TextView
 	StatusLine   *tview.TextView
 	KeysLine     *tview.TextView
@@ -117,6 +171,14 @@ type App struct {
 	// and only act on the double-click.
 	mouseSelectArmed bool
 
+	// treeMode shows the Networks hierarchy as an expandable tree instead of the flat list.
+	treeMode     bool
+	treeExpanded map[string]bool
+
+	// usage computes the utilization labels of one menu or tree rebuild, so
+	// every row does not walk the catalog again; nil outside a rebuild.
+	usage *domain.UtilizationIndex
+
 	// Quit dialog reference.
 	quitDialog *tview.Modal
 
@@ -144,6 +206,9 @@ func New(dir string) (*App, error) {
 	a.Catalog = catalog
 	a.setupLayout()
 	a.ReloadMenu(nil)
+	if len(catalog.LoadWarnings) > 0 {
+		a.setStatus("Warning: " + strings.Join(catalog.LoadWarnings, "; "))
+	}
 
 	return a, nil
 }
@@ -166,24 +231,13 @@ func (a *App) setStatus(text string) {
 	a.StatusLine.SetText(text)
 }
 
-// Save persists data to YAML files and renders the markdown report.
+// Save persists data to YAML files and regenerates the markdown report and
+// exports.
 func (a *App) Save() {
-	if err := store.Save(a.WorkDir, a.Catalog); err != nil {
-		a.setStatus("Error saving data: " + err.Error())
+	if err := export.Save(a.WorkDir, a.Catalog); err != nil {
+		a.setStatus("Error " + err.Error())
 		return
 	}
-
-	md, err := export.RenderMarkdown(a.Catalog)
-	if err != nil {
-		a.setStatus("Error rendering markdown: " + err.Error())
-		return
-	}
-	mdPath := filepath.Join(a.WorkDir, store.MarkdownFileName)
-	if err := os.WriteFile(mdPath, []byte(md), 0644); err != nil {
-		a.setStatus("Error writing markdown: " + err.Error())
-		return
-	}
-
 	a.setStatus("Saved to .ez-ipam/ and EZ-IPAM.md")
 }
 
@@ -235,15 +289,21 @@ func (a *App) openInExternalEditor(currentText string) (string, error) {
 
 // ReloadMenu rebuilds the navigation list, optionally preserving focus on focusedItem.
 func (a *App) ReloadMenu(focusedItem domain.Item) {
+	a.usage = a.Catalog.NewUtilizationIndex()
+	defer func() { a.usage = nil }()
+	if a.treeMode {
+		a.reloadTree(focusedItem)
+		return
+	}
 	a.NavPanel.Clear()
 
-	newMenuItems := a.Catalog.GetChildren(a.CurrentItem)
+	newMenuItems := a.Catalog.ChildrenWithFreeSpace(a.CurrentItem)
 	fromIndex := -1
 	for i, item := range newMenuItems {
 		if focusedItem != nil && focusedItem.DisplayID() == item.DisplayID() {
 			fromIndex = i
 		}
-		a.NavPanel.AddItem(item.DisplayID(), item.GetPath(), 0, nil)
+		a.NavPanel.AddItem(a.menuLabel(item), item.GetPath(), 0, nil)
 	}
 
 	if fromIndex >= 0 {
@@ -251,6 +311,44 @@ func (a *App) ReloadMenu(focusedItem domain.Item) {
 	}
 }
 
+// menuLabel returns the nav label for item. Allocated networks get their
+// utilization appended, and nearly exhausted pools are highlighted.
+func (a *App) menuLabel(item domain.Item) string {
+	label := tview.Escape(item.DisplayID())
+	if usage, exhausted := a.networkUsageLabel(item); usage != "" {
+		label += " " + usage
+		if exhausted {
+			label = "[red]" + label + "[-]"
+		}
+	}
+	return label
+}
+
+// networkUsageLabel returns a short utilization label for allocated networks:
+// the allocated share for Subnet Containers and the reserved share for Host Pools.
+func (a *App) networkUsageLabel(item domain.Item) (string, bool) {
+	n, ok := item.(*domain.Network)
+	if !ok || n.AllocationMode == domain.AllocationModeUnallocated {
+		return "", false
+	}
+	usage := a.usage
+	if usage == nil {
+		usage = a.Catalog.NewUtilizationIndex()
+	}
+	u, err := usage.Utilization(n)
+	if err != nil {
+		return "", false
+	}
+	if n.AllocationMode == domain.AllocationModeHosts {
+		exhausted := a.Catalog.IsNearlyExhausted(n, u)
+		if exhausted {
+			return fmt.Sprintf("%.0f%%!", u.ReservedPercent()), true
+		}
+		return fmt.Sprintf("%.0f%%", u.ReservedPercent()), false
+	}
+	return fmt.Sprintf("%.0f%%", u.AllocatedPercent()), false
+}
+
 // UpdateKeysLine refreshes the keyboard shortcuts help line.
 func (a *App) UpdateKeysLine() {
 	if a.KeysLine == nil {
@@ -287,7 +385,8 @@ func (a *App) showHelpPopup() {
 	content.WriteString("- l / Right Arrow / Enter: Open or select\n")
 	content.WriteString("- Backspace: Go up one level\n")
 	content.WriteString("- Ctrl+U: Page up\n")
-	content.WriteString("- Ctrl+D: Page down\n\n")
+	content.WriteString("- Ctrl+D: Page down\n")
+	content.WriteString("- t: Toggle Networks tree view\n\n")
 	content.WriteString("Global\n")
 	content.WriteString("- q: Quit (with confirmation)\n")
 	content.WriteString("- Ctrl+S: Save\n")
@@ -335,6 +434,12 @@ func (a *App) dismissHelpPopup() {
 	a.TviewApp.SetFocus(a.NavPanel)
 }
 
+func (a *App) showQuitDialog() {
+	a.Pages.ShowPage(quitPageName)
+	a.quitDialog.SetFocus(1)
+	a.TviewApp.SetFocus(a.quitDialog)
+}
+
 // resizeStatusLine adjusts the status panel height to fit its text content.
 func (a *App) resizeStatusLine() {
 	if a.StatusLine == nil || a.DetailsFlex == nil {
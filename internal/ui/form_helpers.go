@@ -2,6 +2,7 @@ package ui
 
 import (
 	"fmt"
+	"net/netip"
 	"slices"
 	"strconv"
 	"strings"
@@ -364,6 +365,133 @@ func parseLAGGroupFromDropdownOption(option string) string {
 }
 
 // getZoneNames returns all zone display names from the catalog.
+// parseHostPoolFormValues parses the gateway, excluded ranges and DHCP ranges
+// entered for the Host Pool n, accepting the shorthands of domain.ParseAddressRange.
+func parseHostPoolFormValues(n *domain.Network, vals networkAllocDialogValues) (hostPoolSettings, error) {
+	var settings hostPoolSettings
+	prefix, err := netip.ParsePrefix(n.ID)
+	if err != nil {
+		return settings, err
+	}
+	if gateway := strings.TrimSpace(vals.Gateway); gateway != "" {
+		r, err := domain.ParseAddressRange(prefix, gateway)
+		if err != nil || r.Start != r.End {
+			return settings, fmt.Errorf("invalid gateway %q", gateway)
+		}
+		settings.Gateway = r.Start.String()
+	}
+	if settings.ExcludedRanges, err = domain.ParseAddressRanges(prefix, vals.ExcludedRanges); err != nil {
+		return settings, err
+	}
+	if settings.DHCPRanges, err = domain.ParseAddressRanges(prefix, vals.DHCPRanges); err != nil {
+		return settings, fmt.Errorf("DHCP ranges: %w", err)
+	}
+	return settings, nil
+}
+
+// parseDHCPOptionsFormValues parses the DHCP options entered for the Host Pool
+// n. The router accepts the same shorthands as the gateway. It returns nil
+// when every field is empty.
+func parseDHCPOptionsFormValues(n *domain.Network, vals dhcpOptionsDialogValues) (*domain.DHCPOptions, error) {
+	options := &domain.DHCPOptions{
+		DNSServers:    splitListField(vals.DNSServers),
+		DomainName:    strings.TrimSpace(vals.DomainName),
+		SearchDomains: splitListField(vals.SearchDomains),
+		NTPServers:    splitListField(vals.NTPServers),
+		LeaseTime:     strings.TrimSpace(vals.LeaseTime),
+	}
+	if router := strings.TrimSpace(vals.Router); router != "" {
+		prefix, err := netip.ParsePrefix(n.ID)
+		if err != nil {
+			return nil, err
+		}
+		r, err := domain.ParseAddressRange(prefix, router)
+		if err != nil || r.Start != r.End {
+			return nil, fmt.Errorf("invalid router %q", router)
+		}
+		options.Router = r.Start.String()
+	}
+	custom, err := domain.ParseDHCPCustomOptions(vals.Custom)
+	if err != nil {
+		return nil, err
+	}
+	options.Custom = custom
+	if options.IsZero() {
+		return nil, nil
+	}
+	return options, nil
+}
+
+// splitRecordValue splits a record value into the form fields of its type.
+// The last field takes the rest of the value, and a quoted CAA value is
+// shown without its quotes.
+func splitRecordValue(recordType, value string) []string {
+	labels := dnsRecordFields[recordType]
+	values := make([]string, len(labels))
+	rest := strings.TrimSpace(value)
+	for i := range labels {
+		if i == len(labels)-1 {
+			values[i] = rest
+			break
+		}
+		values[i], rest, _ = strings.Cut(rest, " ")
+		rest = strings.TrimSpace(rest)
+	}
+	if recordType == "CAA" && len(values[2]) >= 2 && strings.HasPrefix(values[2], `"`) && strings.HasSuffix(values[2], `"`) {
+		values[2] = values[2][1 : len(values[2])-1]
+	}
+	return values
+}
+
+// captureRecordValue joins the typed form fields of recordType into a record
+// value. CAA values are quoted.
+func captureRecordValue(form *tview.Form, recordType string) string {
+	var values []string
+	for _, label := range dnsRecordFields[recordType] {
+		value := strings.TrimSpace(getSearchableDropdownValue(form, label, ""))
+		if recordType == "CAA" && label == "Value" && value != "" && !strings.HasPrefix(value, `"`) {
+			value = `"` + value + `"`
+		}
+		if value != "" {
+			values = append(values, value)
+		}
+	}
+	return strings.Join(values, " ")
+}
+
+// splitListField splits a comma- or space-separated form field into its values.
+func splitListField(text string) []string {
+	return strings.FieldsFunc(text, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\t' || r == '\n'
+	})
+}
+
+// formatOptionalTTL formats a TTL for a form field; 0, the default, is empty.
+func formatOptionalTTL(ttl int) string {
+	if ttl == 0 {
+		return ""
+	}
+	return strconv.Itoa(ttl)
+}
+
+// getTemplateDropdownOptions lists the layout templates made for the size of
+// the focused network.
+func (a *App) getTemplateDropdownOptions() []string {
+	n, ok := a.CurrentFocus.(*domain.Network)
+	if !ok {
+		return nil
+	}
+	prefix, err := netip.ParsePrefix(n.ID)
+	if err != nil {
+		return nil
+	}
+	var options []string
+	for _, t := range a.Catalog.TemplatesFor(prefix.Bits()) {
+		options = append(options, t.Name)
+	}
+	return options
+}
+
 func (a *App) getZoneNames() []string {
 	zonesFolder := a.Catalog.GetByParentAndDisplayID(nil, domain.FolderZones)
 	if zonesFolder == nil {
@@ -532,3 +660,16 @@ func findReservedIPDropdownOption(options, paths []string, reservedIPPath string
 	}
 	return ""
 }
+
+// selectedReservedIPPath returns the path of the reservation selected in the
+// reserved IP dropdown labeled label, or "" when none is.
+func (a *App) selectedReservedIPPath(form *tview.Form, label string) string {
+	options, paths := a.getReservedIPDropdownOptions()
+	selected := getSearchableDropdownValue(form, label, "")
+	for i, option := range options {
+		if option == selected && i < len(paths) {
+			return paths[i]
+		}
+	}
+	return ""
+}
@@ -0,0 +1,79 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+
+	"github.com/plumber-cd/ez-ipam/internal/domain"
+)
+
+const freeSpacePageName = "*free_space*"
+
+// showFreeSpaceReport opens a popup listing every free block under root.
+func (a *App) showFreeSpaceReport(root domain.Item) {
+	blocks, err := a.Catalog.FreeSpace(root)
+	if err != nil {
+		a.setStatus("Error computing free space: " + err.Error())
+		return
+	}
+
+	bySize := false
+	report := tview.NewTextView().
+		SetScrollable(true).
+		SetWrap(false)
+	render := func() {
+		order := "address"
+		if bySize {
+			domain.SortFreeBlocksBySize(blocks)
+			order = "size"
+		} else {
+			domain.SortFreeBlocksByAddress(blocks)
+		}
+		var content strings.Builder
+		fmt.Fprintf(&content, "Free space under %s\n", root.GetPath())
+		fmt.Fprintf(&content, "%d blocks, sorted by %s (o: toggle sort)\n\n", len(blocks), order)
+		if len(blocks) == 0 {
+			content.WriteString("No free space.\n")
+		}
+		for _, block := range blocks {
+			fmt.Fprintf(&content, "%-20s %s\n", block.CIDR, block.ParentPath)
+		}
+		report.SetText(content.String())
+		report.ScrollToBeginning()
+	}
+	render()
+
+	report.SetBorder(true).SetTitle("Free Space (scroll: Up/Down, PgUp/PgDn)")
+	report.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Key() {
+		case tcell.KeyEscape, tcell.KeyEnter, tcell.KeyBS, tcell.KeyBackspace2:
+			a.dismissFreeSpaceReport()
+			return nil
+		case tcell.KeyRune:
+			switch event.Rune() {
+			case 'o':
+				bySize = !bySize
+				render()
+				return nil
+			case 'q':
+				a.dismissFreeSpaceReport()
+				return nil
+			}
+		}
+		return event
+	})
+
+	a.Pages.RemovePage(freeSpacePageName)
+	a.Pages.AddPage(freeSpacePageName, a.createDialogPage(report, 76, 20), true, true)
+	a.Pages.ShowPage(freeSpacePageName)
+	a.TviewApp.SetFocus(report)
+}
+
+func (a *App) dismissFreeSpaceReport() {
+	a.Pages.RemovePage(freeSpacePageName)
+	a.Pages.SwitchToPage(mainPageName)
+	a.TviewApp.SetFocus(a.NavPanel)
+}
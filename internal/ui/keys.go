@@ -20,6 +20,8 @@ func (a *App) onMenuKeyPress(menuItem domain.Item, event *tcell.EventKey) *tcell
 		return a.networkMenuKeyPress(v, event)
 	case *domain.Equipment:
 		return a.equipmentMenuKeyPress(v, event)
+	case *domain.DNSZone:
+		return a.dnsZoneMenuKeyPress(event)
 	}
 	return event
 }
@@ -43,6 +45,8 @@ func (a *App) onFocusKeyPress(focusItem domain.Item, event *tcell.EventKey) *tce
 		return a.portFocusKeyPress(v, event)
 	case *domain.DNSRecord:
 		return a.dnsRecordFocusKeyPress(v, event)
+	case *domain.DNSZone:
+		return a.dnsZoneFocusKeyPress(v, event)
 	}
 	return event
 }
@@ -55,9 +59,13 @@ func (a *App) staticFolderMenuKeyPress(sf *domain.StaticFolder, event *tcell.Eve
 	}
 	switch sf.ID {
 	case domain.FolderNetworks:
-		if event.Rune() == 'n' {
+		switch event.Rune() {
+		case 'n':
 			a.showDialogByName("*new_network*")
 			return nil
+		case 'F':
+			a.showFreeSpaceReport(sf)
+			return nil
 		}
 	case domain.FolderVLANs:
 		if event.Rune() == 'v' {
@@ -84,11 +92,13 @@ func (a *App) staticFolderMenuKeyPress(sf *domain.StaticFolder, event *tcell.Eve
 			return nil
 		}
 	case domain.FolderDNS:
-		if event.Rune() == 'r' {
-			a.showDNSRecordDialog("*add_dns_record*", "Add DNS Record", dnsRecordDialogValues{
-				Mode: DNSModeRecord,
-			}, true, "", func(vals dnsRecordDialogValues) {
-				a.AddDNSRecord(vals.FQDN, vals.RecordType, vals.RecordValue, vals.ReservedIPPath, vals.Description)
+		switch event.Rune() {
+		case 'r':
+			a.showAddDNSRecordDialog()
+			return nil
+		case 'z':
+			a.showDNSZoneDialog("*add_dns_zone*", "Add DNS Zone", dnsZoneDialogValues{}, true, func(vals dnsZoneDialogValues) {
+				a.AddDNSZone(vals.Domain, vals.TTL, vals.Contact, vals.NameServers, vals.Description)
 			})
 			return nil
 		}
@@ -125,7 +135,7 @@ func (a *App) networkFocusKeyPress(n *domain.Network, event *tcell.EventKey) *tc
 		}
 		a.showNetworkAllocDialog("*allocate_network_subnets_mode*",
 			fmt.Sprintf("Allocate as Subnet Container for %s", n.ID),
-			networkAllocDialogValues{}, true,
+			networkAllocDialogValues{}, domain.AllocationModeSubnets, true,
 			func(vals networkAllocDialogValues) {
 				vlanID, err := domain.ParseOptionalVLANID(vals.VLANID)
 				if err != nil {
@@ -141,7 +151,14 @@ func (a *App) networkFocusKeyPress(n *domain.Network, event *tcell.EventKey) *tc
 						return
 					}
 				}
-				a.AllocateNetworkInSubnetsMode(vals.Name, vals.Description, subnetsPrefixInt, vlanID)
+				var template *domain.LayoutTemplate
+				if name := strings.TrimSpace(vals.Template); name != "" && name != NoneVLANOption {
+					if template = a.Catalog.Template(name); template == nil {
+						a.setStatus("Template not found: " + name)
+						return
+					}
+				}
+				a.AllocateNetworkInSubnetsMode(vals.Name, vals.Description, subnetsPrefixInt, vlanID, template)
 			},
 		)
 		return nil
@@ -152,14 +169,19 @@ func (a *App) networkFocusKeyPress(n *domain.Network, event *tcell.EventKey) *tc
 		}
 		a.showNetworkAllocDialog("*allocate_network_hosts_mode*",
 			fmt.Sprintf("Allocate as Host Pool for %s", n.ID),
-			networkAllocDialogValues{}, false,
+			networkAllocDialogValues{}, domain.AllocationModeHosts, false,
 			func(vals networkAllocDialogValues) {
 				vlanID, err := domain.ParseOptionalVLANID(vals.VLANID)
 				if err != nil {
 					a.setStatus("Invalid VLAN ID: " + err.Error())
 					return
 				}
-				a.AllocateNetworkInHostsMode(vals.Name, vals.Description, vlanID)
+				settings, err := parseHostPoolFormValues(n, vals)
+				if err != nil {
+					a.setStatus("Error allocating network: " + err.Error())
+					return
+				}
+				a.AllocateNetworkInHostsMode(vals.Name, vals.Description, vlanID, settings)
 			},
 		)
 		return nil
@@ -174,15 +196,27 @@ func (a *App) networkFocusKeyPress(n *domain.Network, event *tcell.EventKey) *tc
 		}
 		a.showNetworkAllocDialog("*update_network_allocation*",
 			fmt.Sprintf("Update Metadata for %s", n.ID),
-			networkAllocDialogValues{Name: n.DisplayName, Description: n.Description, VLANID: vlanIDStr},
-			false,
+			networkAllocDialogValues{
+				Name:           n.DisplayName,
+				Description:    n.Description,
+				VLANID:         vlanIDStr,
+				Gateway:        n.Gateway,
+				ExcludedRanges: strings.Join(n.ExcludedRanges, ", "),
+				DHCPRanges:     strings.Join(n.DHCPRanges, ", "),
+			},
+			n.AllocationMode, false,
 			func(vals networkAllocDialogValues) {
 				vlanID, err := domain.ParseOptionalVLANID(vals.VLANID)
 				if err != nil {
 					a.setStatus("Invalid VLAN ID: " + err.Error())
 					return
 				}
-				a.UpdateNetworkAllocation(vals.Name, vals.Description, vlanID)
+				settings, err := parseHostPoolFormValues(n, vals)
+				if err != nil {
+					a.setStatus("Error updating network allocation: " + err.Error())
+					return
+				}
+				a.UpdateNetworkAllocation(vals.Name, vals.Description, vlanID, settings)
 			},
 		)
 		return nil
@@ -226,6 +260,62 @@ func (a *App) networkFocusKeyPress(n *domain.Network, event *tcell.EventKey) *tc
 		}
 		a.showSummarizeDialog(candidates, fromIdx, toIdx, parentDisplayID)
 		return nil
+	case 'f':
+		if n.AllocationMode != domain.AllocationModeSubnets {
+			a.setStatus("Free space report is available only for Subnet Containers.")
+			return nil
+		}
+		a.showFreeSpaceReport(n)
+		return nil
+	case 'm':
+		if n.AllocationMode != domain.AllocationModeSubnets {
+			a.setStatus("Address map is available only for Subnet Containers.")
+			return nil
+		}
+		a.showAddressMap(n)
+		return nil
+	case 'g':
+		if n.AllocationMode == domain.AllocationModeUnallocated {
+			a.setStatus("Resize is available only for allocated networks; split or summarize instead.")
+			return nil
+		}
+		a.showResizeNetworkDialog(n)
+		return nil
+	case 'M':
+		if n.AllocationMode == domain.AllocationModeUnallocated {
+			a.setStatus("Move is available only for allocated networks.")
+			return nil
+		}
+		a.showMoveNetworkDialog(n)
+		return nil
+	case 'N':
+		if n.AllocationMode == domain.AllocationModeUnallocated {
+			a.setStatus("Renumber is available only for allocated networks.")
+			return nil
+		}
+		a.showRenumberDialog(n, "")
+		return nil
+	case 'c':
+		if n.AllocationMode == domain.AllocationModeUnallocated {
+			a.setStatus("Clone is available only for allocated networks.")
+			return nil
+		}
+		a.showCloneNetworkDialog(n)
+		return nil
+	case 'o':
+		if n.AllocationMode != domain.AllocationModeHosts {
+			a.setStatus("DHCP options are available only for Host Pools.")
+			return nil
+		}
+		a.showDHCPOptionsDialog(n)
+		return nil
+	case 'P':
+		if n.AllocationMode != domain.AllocationModeSubnets {
+			a.setStatus("Subnet planner is available only for Subnet Containers.")
+			return nil
+		}
+		a.showSubnetPlannerDialog(n, "")
+		return nil
 	case 'd':
 		if n.AllocationMode == domain.AllocationModeUnallocated {
 			return event
@@ -264,13 +354,20 @@ func (a *App) ipFocusKeyPress(ip *domain.IP, event *tcell.EventKey) *tcell.Event
 		records := a.findDNSRecordsByReservedIPPath(ip.GetPath())
 		a.pendingDNSDeletesOnUnreserve = nil
 		confirmText := fmt.Sprintf("Unreserve %s?", ip.DisplayID())
-		if len(records) > 0 {
-			lines := make([]string, 0, len(records))
-			for _, record := range records {
-				a.pendingDNSDeletesOnUnreserve = append(a.pendingDNSDeletesOnUnreserve, record.GetPath())
-				lines = append(lines, "- "+record.ID)
+		var deleted, updated []string
+		for _, record := range records {
+			a.pendingDNSDeletesOnUnreserve = append(a.pendingDNSDeletesOnUnreserve, record.GetPath())
+			if len(record.AliasTargetPaths()) > 1 {
+				updated = append(updated, "- "+record.ID)
+			} else {
+				deleted = append(deleted, "- "+record.ID)
 			}
-			confirmText = fmt.Sprintf("Unreserve %s?\n\nThe following DNS records will also be deleted:\n%s", ip.DisplayID(), strings.Join(lines, "\n"))
+		}
+		if len(deleted) > 0 {
+			confirmText += fmt.Sprintf("\n\nThe following DNS records will also be deleted:\n%s", strings.Join(deleted, "\n"))
+		}
+		if len(updated) > 0 {
+			confirmText += fmt.Sprintf("\n\nThe following DNS aliases will no longer point to it:\n%s", strings.Join(updated, "\n"))
 		}
 		a.showModalByNameWithText("*unreserve_ip*", confirmText)
 		return nil
@@ -569,18 +666,21 @@ func (a *App) dnsRecordFocusKeyPress(record *domain.DNSRecord, event *tcell.Even
 	switch event.Rune() {
 	case 'u':
 		mode := DNSModeRecord
-		if strings.TrimSpace(record.ReservedIPPath) != "" {
+		if strings.TrimSpace(record.ReservedHost) != "" {
+			mode = DNSModeHost
+		} else if record.IsAlias() {
 			mode = DNSModeAlias
 		}
 		a.showDNSRecordDialog("*update_dns_record*", fmt.Sprintf("Update DNS Record %s", record.ID), dnsRecordDialogValues{
-			FQDN:           record.ID,
-			Mode:           mode,
-			RecordType:     record.RecordType,
-			RecordValue:    record.RecordValue,
-			ReservedIPPath: record.ReservedIPPath,
-			Description:    record.Description,
+			FQDN:        record.ID,
+			Mode:        mode,
+			RecordType:  record.RecordType,
+			RecordValue: record.RecordValue,
+			Alias:       dnsAliasOf(record),
+			TTL:         formatOptionalTTL(record.TTL),
+			Description: record.Description,
 		}, false, "", func(vals dnsRecordDialogValues) {
-			a.UpdateDNSRecord(vals.RecordType, vals.RecordValue, vals.ReservedIPPath, vals.Description)
+			a.UpdateDNSRecord(vals.RecordType, vals.RecordValue, vals.Alias, vals.TTL, vals.Description)
 		})
 		return nil
 	case 'D':
@@ -590,6 +690,55 @@ func (a *App) dnsRecordFocusKeyPress(record *domain.DNSRecord, event *tcell.Even
 	return event
 }
 
+func (a *App) showAddDNSRecordDialog() {
+	a.showDNSRecordDialog("*add_dns_record*", "Add DNS Record", dnsRecordDialogValues{
+		Mode: DNSModeRecord,
+	}, true, "", func(vals dnsRecordDialogValues) {
+		a.AddDNSRecord(vals.FQDN, vals.RecordType, vals.RecordValue, vals.Alias, vals.TTL, vals.Description)
+	})
+}
+
+// ---------- DNS zone menu/focus keys ----------
+
+func (a *App) dnsZoneMenuKeyPress(event *tcell.EventKey) *tcell.EventKey {
+	if event.Key() == tcell.KeyRune && event.Rune() == 'r' {
+		a.showAddDNSRecordDialog()
+		return nil
+	}
+	return event
+}
+
+func (a *App) dnsZoneFocusKeyPress(zone *domain.DNSZone, event *tcell.EventKey) *tcell.EventKey {
+	if event.Key() != tcell.KeyRune {
+		return event
+	}
+	switch event.Rune() {
+	case 'u':
+		a.showDNSZoneDialog("*update_dns_zone*", fmt.Sprintf("Update DNS Zone %s", zone.ID), dnsZoneDialogValues{
+			Domain:      zone.ID,
+			TTL:         formatOptionalTTL(zone.TTL),
+			Contact:     zone.Contact,
+			NameServers: strings.Join(zone.NameServers, ", "),
+			Description: zone.Description,
+		}, false, func(vals dnsZoneDialogValues) {
+			a.UpdateDNSZone(vals.TTL, vals.Contact, vals.NameServers, vals.Description)
+		})
+		return nil
+	case 'D':
+		confirmText := fmt.Sprintf("Delete DNS zone %s?", zone.ID)
+		var deleted []string
+		for _, child := range a.Catalog.GetChildren(zone) {
+			deleted = append(deleted, "- "+child.RawID())
+		}
+		if len(deleted) > 0 {
+			confirmText += fmt.Sprintf("\n\nThe following DNS records will also be deleted:\n%s", strings.Join(deleted, "\n"))
+		}
+		a.showModalByNameWithText("*delete_dns_zone*", confirmText)
+		return nil
+	}
+	return event
+}
+
 // ---------- Dialog/modal helpers ----------
 
 func (a *App) showDialogByName(pageName string) {
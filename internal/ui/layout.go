@@ -1,6 +1,8 @@
 package ui
 
 import (
+	"fmt"
+	"net/netip"
 	"slices"
 	"strconv"
 	"strings"
@@ -27,7 +29,14 @@ func (a *App) setupLayout() {
 	a.NavPanel = tview.NewList()
 	a.NavPanel.ShowSecondaryText(false)
 	a.NavPanel.SetBorder(true).SetTitle("Menu")
-	middleFlex.AddItem(a.NavPanel, 0, 1, false)
+
+	a.NavTree = tview.NewTreeView()
+	a.NavTree.SetBorder(true).SetTitle("Networks Tree")
+
+	a.NavPages = tview.NewPages()
+	a.NavPages.AddPage(navListPageName, a.NavPanel, true, true)
+	a.NavPages.AddPage(navTreePageName, a.NavTree, true, false)
+	middleFlex.AddItem(a.NavPages, 0, 1, false)
 
 	a.DetailsFlex = tview.NewFlex().SetDirection(tview.FlexRow)
 	middleFlex.AddItem(a.DetailsFlex, 0, 2, false)
@@ -58,6 +67,14 @@ func (a *App) setupLayout() {
 	a.DetailsPanel.SetFocusFunc(func() { a.TviewApp.SetFocus(a.NavPanel) })
 	a.StatusLine.SetFocusFunc(func() { a.TviewApp.SetFocus(a.NavPanel) })
 	a.KeysLine.SetFocusFunc(func() { a.TviewApp.SetFocus(a.NavPanel) })
+	// In tree mode the tree stands in for the nav panel, so dialogs returning
+	// focus to the nav panel land on the tree instead.
+	a.NavPanel.SetFocusFunc(func() {
+		if a.treeMode {
+			a.TviewApp.SetFocus(a.NavTree)
+		}
+	})
+	a.setupTree()
 
 	// Mouse capture: single click only highlights, double click navigates.
 	a.NavPanel.SetMouseCapture(func(action tview.MouseAction, event *tcell.EventMouse) (tview.MouseAction, *tcell.EventMouse) {
@@ -73,7 +90,7 @@ func (a *App) setupLayout() {
 
 	// Navigation changed callback.
 	a.NavPanel.SetChangedFunc(func(index int, mainText, secondaryText string, shortcut rune) {
-		selected := a.Catalog.GetByParentAndDisplayID(a.CurrentItem, mainText)
+		selected := a.Catalog.Lookup(secondaryText)
 		if selected == nil {
 			return
 		}
@@ -89,7 +106,7 @@ func (a *App) setupLayout() {
 			return
 		}
 
-		selected := a.Catalog.GetByParentAndDisplayID(a.CurrentItem, mainText)
+		selected := a.Catalog.Lookup(secondaryText)
 		if selected == nil {
 			return
 		}
@@ -145,29 +162,13 @@ func (a *App) setupLayout() {
 				return tcell.NewEventKey(tcell.KeyUp, tcell.RuneUArrow, tcell.ModNone)
 			case 'l':
 				return tcell.NewEventKey(tcell.KeyRight, tcell.RuneRArrow, tcell.ModNone)
-			case 'q':
-				a.Pages.ShowPage(quitPageName)
-				a.quitDialog.SetFocus(1)
-				a.TviewApp.SetFocus(a.quitDialog)
-				return nil
-			case '?':
-				a.showHelpPopup()
+			case 't':
+				a.toggleTreeMode()
 				return nil
 			}
 		}
 
-		if a.CurrentItem != nil {
-			if e := a.onMenuKeyPress(a.CurrentItem, event); e != event {
-				return e
-			}
-		}
-		if a.CurrentFocus != nil {
-			if e := a.onFocusKeyPress(a.CurrentFocus, event); e != event {
-				return e
-			}
-		}
-
-		return event
+		return a.handleNavKeys(event)
 	})
 
 	// ---- All dialogs ----
@@ -214,17 +215,21 @@ func (a *App) setupLayout() {
 	makeFormDialog("*split_network*", "Split Network", func(form *tview.Form) {
 		form.AddInputField("New Prefix Length", "", FormFieldWidth, nil, nil).
 			AddButton("Save", func() {
-				newPrefix := getAndClearTextFromInputField(form, "New Prefix Length")
-				newPrefix = strings.TrimLeft(newPrefix, "/")
+				newPrefix := strings.TrimSpace(getAndClearTextFromInputField(form, "New Prefix Length"))
 				if newPrefix == "" {
 					a.SplitNetwork(0)
-				} else {
-					newPrefixInt, err := strconv.Atoi(newPrefix)
+				} else if focusedNetwork, ok := a.CurrentFocus.(*domain.Network); ok {
+					// A list of sizes ("/25 /26 /26" or "100h 50h") splits into mixed prefix lengths.
+					sizes, err := domain.ParseSplitSizes(focusedNetwork.ID, newPrefix)
 					if err != nil {
-						a.setStatus("Invalid prefix length. Enter a number larger than the current prefix: " + err.Error())
+						a.setStatus("Invalid prefix length. Enter one or more numbers larger than the current prefix: " + err.Error())
 						return
 					}
-					a.SplitNetwork(newPrefixInt)
+					if len(sizes) == 1 {
+						a.SplitNetwork(sizes[0])
+					} else {
+						a.SplitNetworkSizes(sizes)
+					}
 				}
 				a.Pages.SwitchToPage(mainPageName)
 				a.TviewApp.SetFocus(a.NavPanel)
@@ -408,9 +413,7 @@ func (a *App) setupLayout() {
 		}
 		switch event.Key() {
 		case tcell.KeyCtrlC:
-			a.Pages.ShowPage(quitPageName)
-			a.quitDialog.SetFocus(1)
-			a.TviewApp.SetFocus(a.quitDialog)
+			a.showQuitDialog()
 			return nil
 		case tcell.KeyCtrlS:
 			a.Save()
@@ -425,6 +428,33 @@ func (a *App) setupLayout() {
 	a.TviewApp.SetFocus(a.NavPanel)
 }
 
+// handleNavKeys handles global and item-specific keys shared by the list and tree views.
+func (a *App) handleNavKeys(event *tcell.EventKey) *tcell.EventKey {
+	if event.Key() == tcell.KeyRune {
+		switch event.Rune() {
+		case 'q':
+			a.showQuitDialog()
+			return nil
+		case '?':
+			a.showHelpPopup()
+			return nil
+		}
+	}
+
+	if a.CurrentItem != nil {
+		if e := a.onMenuKeyPress(a.CurrentItem, event); e != event {
+			return e
+		}
+	}
+	if a.CurrentFocus != nil {
+		if e := a.onFocusKeyPress(a.CurrentFocus, event); e != event {
+			return e
+		}
+	}
+
+	return event
+}
+
 func (a *App) setupModals() {
 	makeModal := func(pageName, text string, onYes func()) {
 		modal := tview.NewModal().SetText(text).AddButtons([]string{"Yes", "No"})
@@ -454,6 +484,7 @@ func (a *App) setupModals() {
 	makeModal("*deallocate_network*", "Deallocate this network and remove its subnets?", func() { a.DeallocateNetwork() })
 	makeModal("*delete_network*", "Delete this top-level network and all of its subnets?", func() { a.DeleteNetwork() })
 	makeModal("*delete_dns_record*", "Delete this DNS record?", func() { a.DeleteDNSRecord() })
+	makeModal("*delete_dns_zone*", "Delete this DNS zone and its records?", func() { a.DeleteDNSZone() })
 }
 
 // mouseBlocker returns a box that absorbs mouse events (prevents clicking through dialog overlays).
@@ -763,8 +794,9 @@ func (a *App) showZoneDialog(pageName, title string, vals zoneDialogValues, onSa
 	a.TviewApp.SetFocus(form)
 }
 
-// showNetworkAllocDialog creates a fresh network allocation dialog with a VLAN dropdown.
-func (a *App) showNetworkAllocDialog(pageName, title string, vals networkAllocDialogValues, showChildPrefix bool, onSave func(networkAllocDialogValues)) {
+// showNetworkAllocDialog creates a fresh network allocation dialog with a VLAN
+// dropdown and the fields specific to mode.
+func (a *App) showNetworkAllocDialog(pageName, title string, vals networkAllocDialogValues, mode domain.AllocationMode, showChildPrefix bool, onSave func(networkAllocDialogValues)) {
 	a.Pages.RemovePage(pageName)
 
 	form := tview.NewForm().SetButtonsAlign(tview.AlignCenter)
@@ -777,6 +809,20 @@ func (a *App) showNetworkAllocDialog(pageName, title string, vals networkAllocDi
 	form.AddFormItem(newSearchableDropdown("VLAN ID", vlanOptions, vlanCurrent, true, nil))
 	if showChildPrefix {
 		form.AddInputField("Child Prefix Len", vals.ChildPrefixLen, FormFieldWidth, nil, nil)
+		if templates := a.getTemplateDropdownOptions(); len(templates) > 0 {
+			form.AddFormItem(newSearchableDropdown("Template", templates, vals.Template, true, nil))
+		}
+	}
+	if mode == domain.AllocationModeHosts {
+		form.AddInputField("Gateway", vals.Gateway, FormFieldWidth, nil, nil)
+		form.AddInputField("Excluded Ranges", vals.ExcludedRanges, FormFieldWidth, nil, nil)
+		if input, ok := form.GetFormItemByLabel("Excluded Ranges").(*tview.InputField); ok {
+			input.SetPlaceholder(".1-.10, .250-.254")
+		}
+		form.AddInputField("DHCP Ranges", vals.DHCPRanges, FormFieldWidth, nil, nil)
+		if input, ok := form.GetFormItemByLabel("DHCP Ranges").(*tview.InputField); ok {
+			input.SetPlaceholder(".100-.199")
+		}
 	}
 
 	cancel := func() { a.dismissDialog(pageName) }
@@ -789,6 +835,14 @@ func (a *App) showNetworkAllocDialog(pageName, title string, vals networkAllocDi
 		}
 		if showChildPrefix {
 			result.ChildPrefixLen = getTextFromInputFieldIfPresent(form, "Child Prefix Len")
+			if _, ok := getFormItemByLabelIfPresent(form, "Template"); ok {
+				result.Template = getSearchableDropdownValue(form, "Template", "")
+			}
+		}
+		if mode == domain.AllocationModeHosts {
+			result.Gateway = getTextFromInputFieldIfPresent(form, "Gateway")
+			result.ExcludedRanges = getTextFromInputFieldIfPresent(form, "Excluded Ranges")
+			result.DHCPRanges = getTextFromInputFieldIfPresent(form, "DHCP Ranges")
 		}
 		onSave(result)
 		a.dismissDialog(pageName)
@@ -803,6 +857,214 @@ func (a *App) showNetworkAllocDialog(pageName, title string, vals networkAllocDi
 	a.TviewApp.SetFocus(form)
 }
 
+// showDHCPOptionsDialog edits the DHCP options of the Host Pool n. List fields
+// take comma-separated values and an empty form clears the options.
+func (a *App) showDHCPOptionsDialog(n *domain.Network) {
+	const pageName = "*dhcp_options*"
+	a.Pages.RemovePage(pageName)
+
+	vals := dhcpOptionsDialogValues{}
+	if o := n.DHCPOptions; o != nil {
+		vals = dhcpOptionsDialogValues{
+			Router:        o.Router,
+			DNSServers:    strings.Join(o.DNSServers, ", "),
+			DomainName:    o.DomainName,
+			SearchDomains: strings.Join(o.SearchDomains, ", "),
+			NTPServers:    strings.Join(o.NTPServers, ", "),
+			LeaseTime:     o.LeaseTime,
+			Custom:        domain.FormatDHCPCustomOptions(o.Custom),
+		}
+	}
+
+	form := tview.NewForm().SetButtonsAlign(tview.AlignCenter)
+	form.AddInputField("Router", vals.Router, FormFieldWidth, nil, nil)
+	form.AddInputField("DNS Servers", vals.DNSServers, FormFieldWidth, nil, nil)
+	form.AddInputField("Domain Name", vals.DomainName, FormFieldWidth, nil, nil)
+	form.AddInputField("Search Domains", vals.SearchDomains, FormFieldWidth, nil, nil)
+	form.AddInputField("NTP Servers", vals.NTPServers, FormFieldWidth, nil, nil)
+	form.AddInputField("Lease Time", vals.LeaseTime, FormFieldWidth, nil, nil)
+	form.AddInputField("Custom Options", vals.Custom, FormFieldWidth, nil, nil)
+	for label, placeholder := range map[string]string{
+		"Router":         n.Gateway,
+		"Lease Time":     "12h",
+		"Custom Options": "66=tftp.example.com, 150=10.0.0.5",
+	} {
+		if input, ok := form.GetFormItemByLabel(label).(*tview.InputField); ok {
+			input.SetPlaceholder(placeholder)
+		}
+	}
+
+	cancel := func() { a.dismissDialog(pageName) }
+	form.AddButton("Save", func() {
+		result := dhcpOptionsDialogValues{
+			Router:        getTextFromInputFieldIfPresent(form, "Router"),
+			DNSServers:    getTextFromInputFieldIfPresent(form, "DNS Servers"),
+			DomainName:    getTextFromInputFieldIfPresent(form, "Domain Name"),
+			SearchDomains: getTextFromInputFieldIfPresent(form, "Search Domains"),
+			NTPServers:    getTextFromInputFieldIfPresent(form, "NTP Servers"),
+			LeaseTime:     getTextFromInputFieldIfPresent(form, "Lease Time"),
+			Custom:        getTextFromInputFieldIfPresent(form, "Custom Options"),
+		}
+		a.dismissDialog(pageName)
+		options, err := parseDHCPOptionsFormValues(n, result)
+		if err != nil {
+			a.setStatus("Error updating DHCP options: " + err.Error())
+			return
+		}
+		a.UpdateDHCPOptions(options)
+	})
+	form.AddButton("Cancel", cancel)
+
+	form.SetBorder(true).SetTitle("DHCP Options for " + n.ID)
+	a.wireDialogFormKeys(form, cancel)
+	a.Pages.AddPage(pageName, a.createDialogPage(form, computeFormDialogWidth(form), computeFormDialogHeight(form)), true, false)
+	a.Pages.ShowPage(pageName)
+	form.SetFocus(0)
+	a.TviewApp.SetFocus(form)
+}
+
+// showResizeNetworkDialog asks for the new prefix length of an allocated network.
+func (a *App) showResizeNetworkDialog(n *domain.Network) {
+	const pageName = "*resize_network*"
+	a.Pages.RemovePage(pageName)
+
+	current := ""
+	if prefix, err := netip.ParsePrefix(n.ID); err == nil {
+		current = fmt.Sprintf("/%d", prefix.Bits())
+	}
+
+	form := tview.NewForm().SetButtonsAlign(tview.AlignCenter)
+	form.AddInputField("New Prefix Length", current, FormFieldWidth, nil, nil)
+
+	cancel := func() { a.dismissDialog(pageName) }
+	form.AddButton("Resize", func() {
+		newPrefix := strings.TrimLeft(strings.TrimSpace(getTextFromInputFieldIfPresent(form, "New Prefix Length")), "/")
+		a.dismissDialog(pageName)
+		newBits, err := strconv.Atoi(newPrefix)
+		if err != nil {
+			a.setStatus("Invalid prefix length: " + err.Error())
+			return
+		}
+		a.ResizeNetwork(newBits)
+	})
+	form.AddButton("Cancel", cancel)
+
+	form.SetBorder(true).SetTitle("Resize " + n.ID)
+	a.wireDialogFormKeys(form, cancel)
+	a.Pages.AddPage(pageName, a.createDialogPage(form, computeFormDialogWidth(form), computeFormDialogHeight(form)), true, false)
+	a.Pages.ShowPage(pageName)
+	form.SetFocus(0)
+	a.TviewApp.SetFocus(form)
+}
+
+// showMoveNetworkDialog asks for the new parent of an allocated network and,
+// optionally, the CIDR it should take there.
+func (a *App) showMoveNetworkDialog(n *domain.Network) {
+	const pageName = "*move_network*"
+	targets := a.Catalog.MoveTargets(n)
+	if len(targets) == 0 {
+		a.setStatus("No other Subnet Container has room for " + n.ID)
+		return
+	}
+	a.Pages.RemovePage(pageName)
+	options := make([]string, 0, len(targets))
+	for _, target := range targets {
+		options = append(options, target.GetPath())
+	}
+
+	form := tview.NewForm().SetButtonsAlign(tview.AlignCenter)
+	form.AddFormItem(newSearchableDropdown("New Parent", options, options[0], false, nil))
+	form.AddInputField("New CIDR", "", FormFieldWidth, nil, nil)
+	if input, ok := form.GetFormItemByLabel("New CIDR").(*tview.InputField); ok {
+		input.SetPlaceholder("blank: keep " + n.ID + " or first free block")
+	}
+
+	cancel := func() { a.dismissDialog(pageName) }
+	form.AddButton("Move", func() {
+		selected := strings.TrimSpace(getSearchableDropdownValue(form, "New Parent", ""))
+		newCIDR := strings.TrimSpace(form.GetFormItemByLabel("New CIDR").(*tview.InputField).GetText())
+		a.dismissDialog(pageName)
+		idx := slices.Index(options, selected)
+		if idx < 0 {
+			a.setStatus("Error moving network: select a new parent")
+			return
+		}
+		a.MoveNetwork(targets[idx], newCIDR)
+	})
+	form.AddButton("Cancel", cancel)
+
+	form.SetBorder(true).SetTitle("Move " + n.ID)
+	a.wireDialogFormKeys(form, cancel)
+	a.Pages.AddPage(pageName, a.createDialogPage(form, computeFormDialogWidth(form), computeFormDialogHeight(form)), true, false)
+	a.Pages.ShowPage(pageName)
+	form.SetFocus(0)
+	a.TviewApp.SetFocus(form)
+}
+
+// showCloneNetworkDialog asks where to clone an allocated network subtree and
+// what to copy besides its structure, names and descriptions.
+func (a *App) showCloneNetworkDialog(n *domain.Network) {
+	const pageName = "*clone_network*"
+	targets := a.Catalog.CloneTargets(n)
+	if len(targets) == 0 {
+		a.setStatus("No Subnet Container has room for a copy of " + n.ID)
+		return
+	}
+	options := make([]string, 0, len(targets))
+	for _, target := range targets {
+		options = append(options, target.GetPath())
+	}
+	current := options[0]
+	if slices.Contains(options, n.GetParentPath()) {
+		current = n.GetParentPath()
+	}
+	a.Pages.RemovePage(pageName)
+
+	form := tview.NewForm().SetButtonsAlign(tview.AlignCenter)
+	form.AddFormItem(newSearchableDropdown("Target Parent", options, current, false, nil))
+	form.AddInputField("New CIDR", "", FormFieldWidth, nil, nil)
+	if input, ok := form.GetFormItemByLabel("New CIDR").(*tview.InputField); ok {
+		input.SetPlaceholder("blank: first free block")
+	}
+	form.AddInputField("Placeholders", "", FormFieldWidth, nil, nil)
+	if input, ok := form.GetFormItemByLabel("Placeholders").(*tview.InputField); ok {
+		input.SetPlaceholder("site=ams replaces {site} in names")
+	}
+	form.AddCheckbox("Copy VLAN IDs", false, nil)
+	form.AddCheckbox("Copy Reservations", false, nil)
+
+	cancel := func() { a.dismissDialog(pageName) }
+	form.AddButton("Clone", func() {
+		selected := strings.TrimSpace(getSearchableDropdownValue(form, "Target Parent", ""))
+		newCIDR := strings.TrimSpace(getTextFromInputFieldIfPresent(form, "New CIDR"))
+		placeholders := getTextFromInputFieldIfPresent(form, "Placeholders")
+		opts := domain.CloneOptions{
+			VLANs:        getCheckboxValueIfPresent(form, "Copy VLAN IDs", false),
+			Reservations: getCheckboxValueIfPresent(form, "Copy Reservations", false),
+		}
+		a.dismissDialog(pageName)
+		idx := slices.Index(options, selected)
+		if idx < 0 {
+			a.setStatus("Error cloning network: select a target parent")
+			return
+		}
+		var err error
+		if opts.Placeholders, err = domain.ParsePlaceholders(placeholders); err != nil {
+			a.setStatus("Error cloning network: " + err.Error())
+			return
+		}
+		a.CloneNetwork(targets[idx], newCIDR, opts)
+	})
+	form.AddButton("Cancel", cancel)
+
+	form.SetBorder(true).SetTitle("Clone " + n.ID)
+	a.wireDialogFormKeys(form, cancel)
+	a.Pages.AddPage(pageName, a.createDialogPage(form, computeFormDialogWidth(form), computeFormDialogHeight(form)), true, false)
+	a.Pages.ShowPage(pageName)
+	form.SetFocus(0)
+	a.TviewApp.SetFocus(form)
+}
+
 // showDNSRecordDialog creates a fresh DNS add/update dialog.
 // focusLabel preserves focus across dialog rebuilds.
 func (a *App) showDNSRecordDialog(pageName, title string, vals dnsRecordDialogValues, allowFQDNEdit bool, focusLabel string, onSave func(dnsRecordDialogValues)) {
@@ -823,13 +1085,33 @@ func (a *App) showDNSRecordDialog(pageName, title string, vals dnsRecordDialogVa
 	modeItem := getFormItemByLabel(form, "Mode")
 	modeDropdown := modeItem.(*tview.DropDown)
 
-	if vals.Mode == DNSModeAlias {
+	switch vals.Mode {
+	case DNSModeAlias:
 		options, paths := a.getReservedIPDropdownOptions()
-		current := findReservedIPDropdownOption(options, paths, vals.ReservedIPPath)
-		form.AddFormItem(newSearchableDropdown("Reserved IP", options, current, false, nil))
-	} else {
-		form.AddInputField("Record Type", vals.RecordType, FormFieldWidth, nil, nil)
-		form.AddInputField("Record Value", vals.RecordValue, FormFieldWidth, nil, nil)
+		var also string
+		if len(vals.Alias.ReservedIPPaths) > 1 {
+			also = findReservedIPDropdownOption(options, paths, vals.Alias.ReservedIPPaths[1])
+		}
+		form.AddFormItem(newSearchableDropdown("Reserved IP", options, findReservedIPDropdownOption(options, paths, vals.Alias.first()), false, nil))
+		form.AddFormItem(newSearchableDropdown("Also Reserved IP", options, also, true, nil))
+	case DNSModeHost:
+		form.AddFormItem(newSearchableDropdown("Host", a.Catalog.ReservedHostnames(), vals.Alias.Host, false, nil))
+	default:
+		typeIndex := max(0, slices.Index(domain.DNSRecordTypes, strings.ToUpper(vals.RecordType)))
+		vals.RecordType = domain.DNSRecordTypes[typeIndex]
+		form.AddDropDown("Record Type", domain.DNSRecordTypes, typeIndex, nil)
+		values := splitRecordValue(vals.RecordType, vals.RecordValue)
+		for i, label := range dnsRecordFields[vals.RecordType] {
+			if vals.RecordType == "CAA" && label == "Tag" {
+				form.AddDropDown(label, domain.CAATags, max(0, slices.Index(domain.CAATags, values[i])), nil)
+				continue
+			}
+			form.AddInputField(label, values[i], FormFieldWidth, nil, nil)
+		}
+	}
+	form.AddInputField("TTL", vals.TTL, FormFieldWidth, nil, nil)
+	if input, ok := form.GetFormItemByLabel("TTL").(*tview.InputField); ok {
+		input.SetPlaceholder("zone default")
 	}
 	form.AddFormItem(newHintedTextArea("Description", vals.Description, FormFieldWidth, 3, descriptionHint))
 
@@ -838,23 +1120,15 @@ func (a *App) showDNSRecordDialog(pageName, title string, vals dnsRecordDialogVa
 		result := dnsRecordDialogValues{
 			FQDN:        strings.TrimSpace(getTextFromInputFieldIfPresent(form, "FQDN")),
 			Mode:        vals.Mode,
-			RecordType:  strings.TrimSpace(getTextFromInputFieldIfPresent(form, "Record Type")),
-			RecordValue: strings.TrimSpace(getTextFromInputFieldIfPresent(form, "Record Value")),
+			RecordType:  vals.RecordType,
+			RecordValue: captureRecordValue(form, vals.RecordType),
+			TTL:         getTextFromInputFieldIfPresent(form, "TTL"),
 			Description: getTextFromTextAreaIfPresent(form, "Description"),
 		}
-		if result.Mode == DNSModeAlias {
-			options, paths := a.getReservedIPDropdownOptions()
-			selected := getSearchableDropdownValue(form, "Reserved IP", "")
-			for i, option := range options {
-				if option == selected && i < len(paths) {
-					result.ReservedIPPath = paths[i]
-					break
-				}
-			}
+		if result.Mode != DNSModeRecord {
+			result.Alias = a.captureDNSAlias(form, vals)
 			result.RecordType = ""
 			result.RecordValue = ""
-		} else {
-			result.ReservedIPPath = ""
 		}
 		if !allowFQDNEdit {
 			result.FQDN = vals.FQDN
@@ -870,21 +1144,30 @@ func (a *App) showDNSRecordDialog(pageName, title string, vals dnsRecordDialogVa
 		newVals := vals
 		newVals.FQDN = getTextFromInputFieldIfPresent(form, "FQDN")
 		newVals.Mode = option
-		newVals.RecordType = getTextFromInputFieldIfPresent(form, "Record Type")
-		newVals.RecordValue = getTextFromInputFieldIfPresent(form, "Record Value")
-		newVals.Description = getTextFromTextAreaIfPresent(form, "Description")
-		if option == DNSModeAlias {
-			options, paths := a.getReservedIPDropdownOptions()
-			selected := getSearchableDropdownValue(form, "Reserved IP", "")
-			for i := range options {
-				if options[i] == selected && i < len(paths) {
-					newVals.ReservedIPPath = paths[i]
-					break
-				}
-			}
+		if vals.Mode == DNSModeRecord {
+			newVals.RecordValue = captureRecordValue(form, vals.RecordType)
+		} else {
+			newVals.Alias = a.captureDNSAlias(form, vals)
 		}
+		newVals.TTL = getTextFromInputFieldIfPresent(form, "TTL")
+		newVals.Description = getTextFromTextAreaIfPresent(form, "Description")
 		a.showDNSRecordDialog(pageName, title, newVals, allowFQDNEdit, "Mode", onSave)
 	})
+	if typeItem, ok := getFormItemByLabelIfPresent(form, "Record Type"); ok {
+		typeItem.(*tview.DropDown).SetSelectedFunc(func(option string, _ int) {
+			newVals := vals
+			newVals.FQDN = getTextFromInputFieldIfPresent(form, "FQDN")
+			newVals.RecordType = option
+			// Values carry over only between types with the same fields.
+			newVals.RecordValue = ""
+			if slices.Equal(dnsRecordFields[option], dnsRecordFields[vals.RecordType]) {
+				newVals.RecordValue = captureRecordValue(form, vals.RecordType)
+			}
+			newVals.TTL = getTextFromInputFieldIfPresent(form, "TTL")
+			newVals.Description = getTextFromTextAreaIfPresent(form, "Description")
+			a.showDNSRecordDialog(pageName, title, newVals, allowFQDNEdit, "Record Type", onSave)
+		})
+	}
 
 	form.SetBorder(true).SetTitle(title)
 	a.wireDialogFormKeys(form, cancel)
@@ -900,6 +1183,76 @@ func (a *App) showDNSRecordDialog(pageName, title string, vals dnsRecordDialogVa
 	a.TviewApp.SetFocus(form)
 }
 
+// showDNSZoneDialog creates a fresh DNS zone add/update dialog. The domain
+// of an existing zone cannot change.
+func (a *App) showDNSZoneDialog(pageName, title string, vals dnsZoneDialogValues, allowDomainEdit bool, onSave func(dnsZoneDialogValues)) {
+	a.Pages.RemovePage(pageName)
+
+	form := tview.NewForm().SetButtonsAlign(tview.AlignCenter)
+	if allowDomainEdit {
+		form.AddInputField("Domain", vals.Domain, FormFieldWidth, nil, nil)
+	}
+	form.AddInputField("Default TTL", vals.TTL, FormFieldWidth, nil, nil)
+	form.AddInputField("SOA Contact", vals.Contact, FormFieldWidth, nil, nil)
+	form.AddInputField("Name Servers", vals.NameServers, FormFieldWidth, nil, nil)
+	form.AddFormItem(newHintedTextArea("Description", vals.Description, FormFieldWidth, 3, descriptionHint))
+	for label, placeholder := range map[string]string{
+		"Default TTL":  strconv.Itoa(a.Catalog.Config.DNSDefaults().TTL),
+		"SOA Contact":  "hostmaster@example.com",
+		"Name Servers": "ns1.example.com, ns2.example.com",
+	} {
+		if input, ok := form.GetFormItemByLabel(label).(*tview.InputField); ok {
+			input.SetPlaceholder(placeholder)
+		}
+	}
+
+	cancel := func() { a.dismissDialog(pageName) }
+	form.AddButton("Save", func() {
+		result := dnsZoneDialogValues{
+			Domain:      vals.Domain,
+			TTL:         getTextFromInputFieldIfPresent(form, "Default TTL"),
+			Contact:     getTextFromInputFieldIfPresent(form, "SOA Contact"),
+			NameServers: getTextFromInputFieldIfPresent(form, "Name Servers"),
+			Description: getTextFromTextAreaIfPresent(form, "Description"),
+		}
+		if allowDomainEdit {
+			result.Domain = getTextFromInputFieldIfPresent(form, "Domain")
+		}
+		a.dismissDialog(pageName)
+		onSave(result)
+	})
+	form.AddButton("Cancel", cancel)
+
+	form.SetBorder(true).SetTitle(title)
+	a.wireDialogFormKeys(form, cancel)
+	a.Pages.AddPage(pageName, a.createDialogPage(form, computeFormDialogWidth(form), computeFormDialogHeight(form)), true, false)
+	a.Pages.ShowPage(pageName)
+	form.SetFocus(0)
+	a.TviewApp.SetFocus(form)
+}
+
+// captureDNSAlias reads the alias targets of the Alias or Host mode form.
+// Reservations beyond the two the form shows are kept as they were.
+func (a *App) captureDNSAlias(form *tview.Form, vals dnsRecordDialogValues) dnsAlias {
+	if vals.Mode == DNSModeHost {
+		return dnsAlias{Host: getSearchableDropdownValue(form, "Host", "")}
+	}
+	var alias dnsAlias
+	for _, label := range []string{"Reserved IP", "Also Reserved IP"} {
+		if path := a.selectedReservedIPPath(form, label); path != "" && !slices.Contains(alias.ReservedIPPaths, path) {
+			alias.ReservedIPPaths = append(alias.ReservedIPPaths, path)
+		}
+	}
+	if len(vals.Alias.ReservedIPPaths) > 2 {
+		for _, path := range vals.Alias.ReservedIPPaths[2:] {
+			if !slices.Contains(alias.ReservedIPPaths, path) {
+				alias.ReservedIPPaths = append(alias.ReservedIPPaths, path)
+			}
+		}
+	}
+	return alias
+}
+
 // showSummarizeDialog creates a summarize dialog for the given candidates.
 func (a *App) showSummarizeDialog(candidates []*domain.Network, fromIndex, toIndex int, parentDisplayID string) {
 	const pageName = "*summarize_network*"
@@ -1,10 +1,11 @@
 package ui
 
 import (
-	"encoding/hex"
 	"fmt"
+	"math/big"
 	"net"
 	"net/netip"
+	"slices"
 	"strconv"
 	"strings"
 
@@ -35,6 +36,9 @@ func (a *App) AddNewNetwork(cidr string) {
 	a.setStatus("Added new network: " + cidr)
 }
 
+// SplitNetwork splits the focused network into subnets of prefix length
+// newPrefix. Only the focused network is stored; its subnets are listed in
+// its place until one of them is allocated.
 func (a *App) SplitNetwork(newPrefix int) {
 	focusedNetwork, ok := a.CurrentFocus.(*domain.Network)
 	if !ok {
@@ -42,12 +46,32 @@ func (a *App) SplitNetwork(newPrefix int) {
 		return
 	}
 
+	if err := focusedNetwork.Split(a.Catalog, newPrefix); err != nil {
+		a.setStatus("Error splitting network: " + err.Error())
+		return
+	}
+
+	a.ReloadMenu(focusedNetwork)
+
+	count := new(big.Int).Lsh(big.NewInt(1), uint(focusedNetwork.SplitBits-netip.MustParsePrefix(focusedNetwork.ID).Bits()))
+	a.setStatus("Split network " + focusedNetwork.GetPath() + " into " + count.String() + " subnets")
+}
+
+// SplitNetworkSizes splits the focused network into subnets of mixed prefix
+// lengths, replacing it with the subnets.
+func (a *App) SplitNetworkSizes(sizes []int) {
+	focusedNetwork, ok := a.CurrentFocus.(*domain.Network)
+	if !ok {
+		a.setStatus("Error: SplitNetwork requires a network to be focused")
+		return
+	}
+
 	if focusedNetwork.AllocationMode != domain.AllocationModeUnallocated {
 		a.setStatus("Error: cannot split an allocated network")
 		return
 	}
 
-	newNetworks, err := domain.SplitNetwork(focusedNetwork.ID, newPrefix)
+	newNetworks, err := domain.SplitNetworkSizes(focusedNetwork.ID, sizes)
 	if err != nil {
 		a.setStatus("Error splitting network: " + err.Error())
 		return
@@ -59,6 +83,11 @@ func (a *App) SplitNetwork(newPrefix int) {
 		return
 	}
 
+	if err := a.Catalog.Materialize(focusedNetwork); err != nil {
+		a.setStatus("Error splitting network: " + err.Error())
+		return
+	}
+
 	newMenuItems := []*domain.Network{}
 	for _, newNet := range newNetworks {
 		newMenuItem := &domain.Network{
@@ -131,20 +160,18 @@ func (a *App) SummarizeNetworkSelection(candidates []*domain.Network, fromIndex,
 		return
 	}
 
-	newMenuItem := &domain.Network{
-		Base: domain.Base{
-			ID:         newNetwork,
-			ParentPath: parentPath,
-		},
+	parent := a.Catalog.Get(parentPath)
+	if parent == nil {
+		a.setStatus("Error summarizing network: parent not found")
+		return
 	}
-	if err := a.validateNewNetworkIgnoring(newMenuItem, selected); err != nil {
+	// Carving the summary replaces the selected networks, including split
+	// networks the selection was listed from.
+	newMenuItem, err := a.Catalog.CarveFreeBlock(parent, newNetwork)
+	if err != nil {
 		a.setStatus("Error validating summarized network: " + err.Error())
 		return
 	}
-
-	for _, old := range selected {
-		a.Catalog.Delete(old)
-	}
 	if err := a.Catalog.Add(newMenuItem); err != nil {
 		a.setStatus("Error adding summarized network: " + err.Error())
 		return
@@ -154,7 +181,10 @@ func (a *App) SummarizeNetworkSelection(candidates []*domain.Network, fromIndex,
 	a.setStatus("Summarized networks into " + newMenuItem.DisplayID())
 }
 
-func (a *App) AllocateNetworkInSubnetsMode(displayName, description string, subnetsPrefix int, vlanID int) {
+// AllocateNetworkInSubnetsMode allocates the focused network as a Subnet
+// Container. Its children are either laid out by template or, when template
+// is nil, split at subnetsPrefix.
+func (a *App) AllocateNetworkInSubnetsMode(displayName, description string, subnetsPrefix int, vlanID int, template *domain.LayoutTemplate) {
 	focusedNetwork, ok := a.CurrentFocus.(*domain.Network)
 	if !ok {
 		a.setStatus("Error: AllocateNetworkInSubnetsMode requires a network to be focused")
@@ -176,27 +206,49 @@ func (a *App) AllocateNetworkInSubnetsMode(displayName, description string, subn
 		return
 	}
 
-	newNetworks, err := domain.SplitNetwork(focusedNetwork.ID, subnetsPrefix)
-	if err != nil {
+	if template != nil {
+		if subnetsPrefix != 0 {
+			a.setStatus("Error allocating network: use either a child prefix length or a template")
+			return
+		}
+	} else if _, err := domain.SplitNetwork(focusedNetwork.ID, subnetsPrefix); err != nil {
 		a.setStatus("Error splitting network: " + err.Error())
 		return
 	}
 
+	if err := a.Catalog.Materialize(focusedNetwork); err != nil {
+		a.setStatus("Error allocating network: " + err.Error())
+		return
+	}
+
 	// Apply the modification.
 	focusedNetwork.AllocationMode = domain.AllocationModeSubnets
 	focusedNetwork.DisplayName = displayName
 	focusedNetwork.Description = description
 	focusedNetwork.VLANID = vlanID
 
-	for _, newNet := range newNetworks {
-		newMenuItem := &domain.Network{
+	if template != nil {
+		if err := a.Catalog.ApplyTemplate(focusedNetwork, template); err != nil {
+			focusedNetwork.AllocationMode = domain.AllocationModeUnallocated
+			focusedNetwork.DisplayName = ""
+			focusedNetwork.Description = ""
+			focusedNetwork.VLANID = 0
+			a.setStatus("Error applying template: " + err.Error())
+			return
+		}
+	}
+
+	if template == nil {
+		// The container's space starts out as one split network, listed as
+		// its subnets; each subnet is stored once it is allocated.
+		children := &domain.Network{
 			Base: domain.Base{
-				ID:         newNet,
+				ID:         focusedNetwork.ID,
 				ParentPath: focusedNetwork.GetPath(),
 			},
 		}
-		if err := a.Catalog.Add(newMenuItem); err != nil {
-			a.setStatus("Error adding subnet: " + err.Error())
+		if err := children.Split(a.Catalog, subnetsPrefix); err != nil {
+			a.setStatus("Error adding subnets: " + err.Error())
 			return
 		}
 	}
@@ -205,7 +257,20 @@ func (a *App) AllocateNetworkInSubnetsMode(displayName, description string, subn
 	a.setStatus("Allocated network: " + focusedNetwork.GetPath())
 }
 
-func (a *App) AllocateNetworkInHostsMode(displayName, description string, vlanID int) {
+// ApplySubnetPlan allocates every planned subnet inside container. Nothing is
+// changed when any planned subnet is invalid.
+func (a *App) ApplySubnetPlan(container *domain.Network, plan []domain.PlannedSubnet) {
+	if err := a.Catalog.ApplySubnetPlan(container, plan); err != nil {
+		a.setStatus("Error applying subnet plan: " + err.Error())
+		return
+	}
+
+	a.CurrentFocus = container
+	a.ReloadMenu(container)
+	a.setStatus(fmt.Sprintf("Planned %d subnets in %s", len(plan), container.GetPath()))
+}
+
+func (a *App) AllocateNetworkInHostsMode(displayName, description string, vlanID int, settings hostPoolSettings) {
 	focusedNetwork, ok := a.CurrentFocus.(*domain.Network)
 	if !ok {
 		a.setStatus("Error: AllocateNetworkInHostsMode requires a network")
@@ -221,21 +286,27 @@ func (a *App) AllocateNetworkInHostsMode(displayName, description string, vlanID
 	testCopy.DisplayName = displayName
 	testCopy.Description = description
 	testCopy.VLANID = vlanID
+	settings.applyTo(&testCopy)
 	if err := a.validateNetworkUpdate(&testCopy); err != nil {
 		a.setStatus("Error allocating network: " + err.Error())
 		return
 	}
+	if err := a.Catalog.Materialize(focusedNetwork); err != nil {
+		a.setStatus("Error allocating network: " + err.Error())
+		return
+	}
 
 	focusedNetwork.AllocationMode = domain.AllocationModeHosts
 	focusedNetwork.DisplayName = displayName
 	focusedNetwork.Description = description
 	focusedNetwork.VLANID = vlanID
+	settings.applyTo(focusedNetwork)
 
 	a.ReloadMenu(focusedNetwork)
 	a.setStatus("Allocated network: " + focusedNetwork.GetPath())
 }
 
-func (a *App) UpdateNetworkAllocation(displayName, description string, vlanID int) {
+func (a *App) UpdateNetworkAllocation(displayName, description string, vlanID int, settings hostPoolSettings) {
 	focusedNetwork, ok := a.CurrentFocus.(*domain.Network)
 	if !ok {
 		a.setStatus("Error: UpdateNetworkAllocation requires a network")
@@ -250,6 +321,7 @@ func (a *App) UpdateNetworkAllocation(displayName, description string, vlanID in
 	testCopy.DisplayName = displayName
 	testCopy.Description = description
 	testCopy.VLANID = vlanID
+	settings.applyTo(&testCopy)
 	if err := a.validateNetworkUpdate(&testCopy); err != nil {
 		a.setStatus("Error updating network allocation: " + err.Error())
 		return
@@ -258,11 +330,139 @@ func (a *App) UpdateNetworkAllocation(displayName, description string, vlanID in
 	focusedNetwork.DisplayName = displayName
 	focusedNetwork.Description = description
 	focusedNetwork.VLANID = vlanID
+	settings.applyTo(focusedNetwork)
 
 	a.ReloadMenu(focusedNetwork)
 	a.setStatus("Allocated network updated: " + focusedNetwork.GetPath())
 }
 
+// UpdateDHCPOptions replaces the DHCP options of the focused Host Pool; nil
+// clears them.
+func (a *App) UpdateDHCPOptions(options *domain.DHCPOptions) {
+	focusedNetwork, ok := a.CurrentFocus.(*domain.Network)
+	if !ok {
+		a.setStatus("Error: UpdateDHCPOptions requires a network")
+		return
+	}
+
+	testCopy := *focusedNetwork
+	testCopy.DHCPOptions = options
+	if err := a.validateNetworkUpdate(&testCopy); err != nil {
+		a.setStatus("Error updating DHCP options: " + err.Error())
+		return
+	}
+
+	focusedNetwork.DHCPOptions = options
+
+	a.ReloadMenu(focusedNetwork)
+	a.setStatus("Updated DHCP options: " + focusedNetwork.GetPath())
+}
+
+// ResizeNetwork grows or shrinks the focused allocated network to newBits.
+func (a *App) ResizeNetwork(newBits int) {
+	focusedNetwork, ok := a.CurrentFocus.(*domain.Network)
+	if !ok {
+		a.setStatus("Error: ResizeNetwork requires a network")
+		return
+	}
+	prefix, err := netip.ParsePrefix(focusedNetwork.ID)
+	if err != nil {
+		a.setStatus("Error resizing network: " + err.Error())
+		return
+	}
+
+	oldPath := focusedNetwork.GetPath()
+	switch {
+	case newBits < prefix.Bits():
+		err = focusedNetwork.Grow(a.Catalog, newBits)
+	case newBits > prefix.Bits():
+		err = focusedNetwork.Shrink(a.Catalog, newBits)
+	default:
+		a.setStatus("Network " + focusedNetwork.ID + " already has that size")
+		return
+	}
+	if err != nil {
+		a.setStatus("Error resizing network: " + err.Error())
+		return
+	}
+
+	a.ReloadMenu(focusedNetwork)
+	a.setStatus("Resized network " + oldPath + " to " + focusedNetwork.ID)
+}
+
+// MoveNetwork re-parents the focused network and its subtree under newParent
+// at newCIDR. An empty newCIDR uses the placement the catalog suggests.
+func (a *App) MoveNetwork(newParent domain.Item, newCIDR string) {
+	focusedNetwork, ok := a.CurrentFocus.(*domain.Network)
+	if !ok {
+		a.setStatus("Error: MoveNetwork requires a network")
+		return
+	}
+
+	if newCIDR == "" {
+		placement, err := a.Catalog.MovePlacement(focusedNetwork, newParent)
+		if err != nil {
+			a.setStatus("Error moving network: " + err.Error())
+			return
+		}
+		newCIDR = placement
+	}
+	oldPath := focusedNetwork.GetPath()
+	if err := focusedNetwork.Move(a.Catalog, newParent, newCIDR); err != nil {
+		a.setStatus("Error moving network: " + err.Error())
+		return
+	}
+
+	a.focusNetwork(focusedNetwork)
+	a.setStatus("Moved network " + oldPath + " to " + focusedNetwork.GetPath())
+}
+
+// CloneNetwork copies the focused network subtree under newParent at newCIDR.
+// An empty newCIDR uses the first free block of the same size.
+func (a *App) CloneNetwork(newParent domain.Item, newCIDR string, opts domain.CloneOptions) {
+	focusedNetwork, ok := a.CurrentFocus.(*domain.Network)
+	if !ok {
+		a.setStatus("Error: CloneNetwork requires a network")
+		return
+	}
+
+	if newCIDR == "" {
+		placement, err := a.Catalog.MovePlacement(focusedNetwork, newParent)
+		if err != nil {
+			a.setStatus("Error cloning network: " + err.Error())
+			return
+		}
+		newCIDR = placement
+	}
+	clone, err := focusedNetwork.Clone(a.Catalog, newParent, newCIDR, opts)
+	if err != nil {
+		a.setStatus("Error cloning network: " + err.Error())
+		return
+	}
+
+	a.focusNetwork(clone)
+	a.setStatus("Cloned network " + focusedNetwork.ID + " to " + clone.GetPath())
+}
+
+// RenumberNetwork moves the focused network and its subtree to newCIDR,
+// rewriting reservations and DNS records that point into it.
+func (a *App) RenumberNetwork(newCIDR string) {
+	focusedNetwork, ok := a.CurrentFocus.(*domain.Network)
+	if !ok {
+		a.setStatus("Error: RenumberNetwork requires a network")
+		return
+	}
+
+	oldID := focusedNetwork.ID
+	if err := focusedNetwork.Renumber(a.Catalog, newCIDR); err != nil {
+		a.setStatus("Error renumbering network: " + err.Error())
+		return
+	}
+
+	a.focusNetwork(focusedNetwork)
+	a.setStatus("Renumbered network " + oldID + " to " + focusedNetwork.ID)
+}
+
 func (a *App) DeallocateNetwork() {
 	focusedNetwork, ok := a.CurrentFocus.(*domain.Network)
 	if !ok {
@@ -309,33 +509,13 @@ func (a *App) DeleteNetwork() {
 
 // ---------- IP operations ----------
 
-func normalizeMACAddress(input string) (string, error) {
-	input = strings.TrimSpace(input)
-	if input == "" {
-		return "", nil
-	}
-	hwAddr, err := net.ParseMAC(input)
-	if err != nil {
-		return "", err
-	}
-	if len(hwAddr) < 6 {
-		return "", fmt.Errorf("MAC address %q is too short", input)
-	}
-	hwAddr = hwAddr[:6]
-	parts := make([]string, 0, len(hwAddr))
-	for _, b := range hwAddr {
-		parts = append(parts, hex.EncodeToString([]byte{b}))
-	}
-	return strings.ToLower(strings.Join(parts, ":")), nil
-}
-
 func (a *App) ReserveIP(address, displayName, macAddress, description string) {
 	parent, ok := a.CurrentItem.(*domain.Network)
 	if !ok {
 		a.setStatus("Error: ReserveIP requires a network as current menu item")
 		return
 	}
-	normalizedMAC, err := normalizeMACAddress(macAddress)
+	normalizedMAC, err := domain.NormalizeMACAddress(macAddress)
 	if err != nil {
 		a.setStatus("Error reserving IP: invalid MAC address: " + err.Error())
 		return
@@ -362,6 +542,10 @@ func (a *App) ReserveIP(address, displayName, macAddress, description string) {
 	}
 	a.ReloadMenu(reserved)
 
+	if warning := reserved.DHCPWarning(a.Catalog); warning != "" {
+		a.setStatus("Reserved IP: " + reserved.GetPath() + " (warning: " + warning + ")")
+		return
+	}
 	a.setStatus("Reserved IP: " + reserved.GetPath())
 }
 
@@ -371,7 +555,7 @@ func (a *App) UpdateIPReservation(displayName, macAddress, description string) {
 		a.setStatus("Error: UpdateIPReservation requires an IP to be focused")
 		return
 	}
-	normalizedMAC, err := normalizeMACAddress(macAddress)
+	normalizedMAC, err := domain.NormalizeMACAddress(macAddress)
 	if err != nil {
 		a.setStatus("Error updating IP reservation: invalid MAC address: " + err.Error())
 		return
@@ -414,6 +598,12 @@ func (a *App) UnreserveIP() {
 		recordsToDelete = a.findDNSRecordsByReservedIPPath(focusedIP.GetPath())
 	}
 	for _, record := range recordsToDelete {
+		// Aliases pointing at further reservations only lose this one.
+		if paths := record.AliasTargetPaths(); len(paths) > 1 {
+			paths = slices.DeleteFunc(paths, func(path string) bool { return path == focusedIP.GetPath() })
+			dnsAlias{ReservedIPPaths: paths}.applyTo(record)
+			continue
+		}
 		a.Catalog.Delete(record)
 	}
 	a.pendingDNSDeletesOnUnreserve = nil
@@ -430,7 +620,7 @@ func (a *App) findDNSRecordsByReservedIPPath(reservedIPPath string) []*domain.DN
 		if !ok {
 			continue
 		}
-		if strings.TrimSpace(record.ReservedIPPath) == strings.TrimSpace(reservedIPPath) {
+		if slices.Contains(record.AliasTargetPaths(), strings.TrimSpace(reservedIPPath)) {
 			records = append(records, record)
 		}
 	}
@@ -439,11 +629,46 @@ func (a *App) findDNSRecordsByReservedIPPath(reservedIPPath string) []*domain.DN
 
 // ---------- DNS operations ----------
 
-func (a *App) AddDNSRecord(fqdn, recordType, recordValue, reservedIPPath, description string) {
+// dnsAlias is what an alias points to: reservations, or every reservation
+// of a hostname.
+type dnsAlias struct {
+	ReservedIPPaths []string
+	Host            string
+}
+
+func dnsAliasOf(record *domain.DNSRecord) dnsAlias {
+	return dnsAlias{ReservedIPPaths: record.AliasTargetPaths(), Host: strings.TrimSpace(record.ReservedHost)}
+}
+
+func (alias dnsAlias) first() string {
+	if len(alias.ReservedIPPaths) == 0 {
+		return ""
+	}
+	return alias.ReservedIPPaths[0]
+}
+
+// applyTo sets the alias fields of record.
+func (alias dnsAlias) applyTo(record *domain.DNSRecord) {
+	record.ReservedIPPath = strings.TrimSpace(alias.first())
+	record.ReservedIPPaths = nil
+	if len(alias.ReservedIPPaths) > 1 {
+		for _, path := range alias.ReservedIPPaths[1:] {
+			record.ReservedIPPaths = append(record.ReservedIPPaths, strings.TrimSpace(path))
+		}
+	}
+	record.ReservedHost = strings.TrimSpace(alias.Host)
+}
+
+func (a *App) AddDNSRecord(fqdn, recordType, recordValue string, alias dnsAlias, ttl, description string) {
 	parent := a.CurrentItem
-	sf, ok := parent.(*domain.StaticFolder)
-	if !ok || sf.ID != domain.FolderDNS {
-		a.setStatus("Error: AddDNSRecord requires DNS folder as current menu item")
+	_, inZone := parent.(*domain.DNSZone)
+	if sf, ok := parent.(*domain.StaticFolder); !inZone && (!ok || sf.ID != domain.FolderDNS) {
+		a.setStatus("Error: AddDNSRecord requires DNS folder or a DNS zone as current menu item")
+		return
+	}
+	parsedTTL, err := domain.ParseTTL(ttl)
+	if err != nil {
+		a.setStatus("Error adding DNS record: " + err.Error())
 		return
 	}
 
@@ -452,25 +677,21 @@ func (a *App) AddDNSRecord(fqdn, recordType, recordValue, reservedIPPath, descri
 			ID:         strings.TrimSpace(fqdn),
 			ParentPath: parent.GetPath(),
 		},
-		RecordType:     strings.TrimSpace(recordType),
-		RecordValue:    strings.TrimSpace(recordValue),
-		ReservedIPPath: strings.TrimSpace(reservedIPPath),
-		Description:    strings.TrimSpace(description),
+		RecordType:  strings.TrimSpace(recordType),
+		RecordValue: strings.TrimSpace(recordValue),
+		TTL:         parsedTTL,
+		Description: strings.TrimSpace(description),
 	}
+	alias.applyTo(dnsRecord)
 
 	if err := dnsRecord.Validate(a.Catalog); err != nil {
 		a.setStatus("Error adding DNS record: " + err.Error())
 		return
 	}
-	for _, sibling := range a.Catalog.GetChildren(parent) {
-		other, ok := sibling.(*domain.DNSRecord)
-		if !ok {
-			continue
-		}
-		if strings.EqualFold(other.ID, dnsRecord.ID) {
-			a.setStatus("Error adding DNS record: FQDN already exists")
-			return
-		}
+	// The same FQDN flat under DNS and nested in a zone would be exported twice.
+	if existing := a.Catalog.DNSRecordNamed(dnsRecord.ID); existing != nil {
+		a.setStatus("Error adding DNS record: FQDN already exists at " + existing.GetPath())
+		return
 	}
 	if err := a.Catalog.Add(dnsRecord); err != nil {
 		a.setStatus("Error adding DNS record: " + err.Error())
@@ -480,25 +701,33 @@ func (a *App) AddDNSRecord(fqdn, recordType, recordValue, reservedIPPath, descri
 	a.setStatus("Added DNS record: " + dnsRecord.GetPath())
 }
 
-func (a *App) UpdateDNSRecord(recordType, recordValue, reservedIPPath, description string) {
+func (a *App) UpdateDNSRecord(recordType, recordValue string, alias dnsAlias, ttl, description string) {
 	focusedRecord, ok := a.CurrentFocus.(*domain.DNSRecord)
 	if !ok {
 		a.setStatus("Error: UpdateDNSRecord requires a DNS record to be focused")
 		return
 	}
+	parsedTTL, err := domain.ParseTTL(ttl)
+	if err != nil {
+		a.setStatus("Error updating DNS record: " + err.Error())
+		return
+	}
 
 	oldRecordType := focusedRecord.RecordType
 	oldRecordValue := focusedRecord.RecordValue
-	oldReservedIPPath := focusedRecord.ReservedIPPath
+	oldAlias := dnsAlias{ReservedIPPaths: append([]string{focusedRecord.ReservedIPPath}, focusedRecord.ReservedIPPaths...), Host: focusedRecord.ReservedHost}
+	oldTTL := focusedRecord.TTL
 	oldDescription := focusedRecord.Description
 	focusedRecord.RecordType = strings.TrimSpace(recordType)
 	focusedRecord.RecordValue = strings.TrimSpace(recordValue)
-	focusedRecord.ReservedIPPath = strings.TrimSpace(reservedIPPath)
+	alias.applyTo(focusedRecord)
+	focusedRecord.TTL = parsedTTL
 	focusedRecord.Description = strings.TrimSpace(description)
 	if err := focusedRecord.Validate(a.Catalog); err != nil {
 		focusedRecord.RecordType = oldRecordType
 		focusedRecord.RecordValue = oldRecordValue
-		focusedRecord.ReservedIPPath = oldReservedIPPath
+		oldAlias.applyTo(focusedRecord)
+		focusedRecord.TTL = oldTTL
 		focusedRecord.Description = oldDescription
 		a.setStatus("Error updating DNS record: " + err.Error())
 		return
@@ -508,6 +737,86 @@ func (a *App) UpdateDNSRecord(recordType, recordValue, reservedIPPath, descripti
 	a.setStatus("Updated DNS record: " + focusedRecord.GetPath())
 }
 
+func (a *App) AddDNSZone(zoneName, ttl, contact, nameServers, description string) {
+	parent := a.CurrentItem
+	sf, ok := parent.(*domain.StaticFolder)
+	if !ok || sf.ID != domain.FolderDNS {
+		a.setStatus("Error: AddDNSZone requires DNS folder as current menu item")
+		return
+	}
+	parsedTTL, err := domain.ParseTTL(ttl)
+	if err != nil {
+		a.setStatus("Error adding DNS zone: " + err.Error())
+		return
+	}
+
+	zone := &domain.DNSZone{
+		Base: domain.Base{
+			ID:         strings.ToLower(strings.TrimSuffix(strings.TrimSpace(zoneName), ".")),
+			ParentPath: parent.GetPath(),
+		},
+		TTL:         parsedTTL,
+		Contact:     strings.TrimSpace(contact),
+		NameServers: splitListField(nameServers),
+		Description: strings.TrimSpace(description),
+	}
+	if err := zone.Validate(a.Catalog); err != nil {
+		a.setStatus("Error adding DNS zone: " + err.Error())
+		return
+	}
+	for _, sibling := range a.Catalog.GetChildren(parent) {
+		if strings.EqualFold(sibling.RawID(), zone.ID) {
+			a.setStatus("Error adding DNS zone: " + zone.ID + " already exists")
+			return
+		}
+	}
+	if err := a.Catalog.Add(zone); err != nil {
+		a.setStatus("Error adding DNS zone: " + err.Error())
+		return
+	}
+	a.ReloadMenu(zone)
+	a.setStatus("Added DNS zone: " + zone.GetPath())
+}
+
+func (a *App) UpdateDNSZone(ttl, contact, nameServers, description string) {
+	focusedZone, ok := a.CurrentFocus.(*domain.DNSZone)
+	if !ok {
+		a.setStatus("Error: UpdateDNSZone requires a DNS zone to be focused")
+		return
+	}
+	parsedTTL, err := domain.ParseTTL(ttl)
+	if err != nil {
+		a.setStatus("Error updating DNS zone: " + err.Error())
+		return
+	}
+
+	candidate := *focusedZone
+	candidate.TTL = parsedTTL
+	candidate.Contact = strings.TrimSpace(contact)
+	candidate.NameServers = splitListField(nameServers)
+	candidate.Description = strings.TrimSpace(description)
+	if err := candidate.Validate(a.Catalog); err != nil {
+		a.setStatus("Error updating DNS zone: " + err.Error())
+		return
+	}
+	*focusedZone = candidate
+
+	a.ReloadMenu(focusedZone)
+	a.setStatus("Updated DNS zone: " + focusedZone.GetPath())
+}
+
+func (a *App) DeleteDNSZone() {
+	focusedZone, ok := a.CurrentFocus.(*domain.DNSZone)
+	if !ok {
+		a.setStatus("Error: DeleteDNSZone requires a DNS zone to be focused")
+		return
+	}
+
+	a.Catalog.Delete(focusedZone)
+	a.ReloadMenu(focusedZone)
+	a.setStatus("Deleted DNS zone: " + focusedZone.GetPath())
+}
+
 func (a *App) DeleteDNSRecord() {
 	focusedRecord, ok := a.CurrentFocus.(*domain.DNSRecord)
 	if !ok {
@@ -0,0 +1,92 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+
+	"github.com/plumber-cd/ez-ipam/internal/domain"
+)
+
+const (
+	subnetPlannerPageName = "*subnet_planner*"
+	subnetPlanPageName    = "*subnet_plan*"
+	subnetPlannerHint     = "One per line: [2x] name /24|hosts [vlan] [container]"
+)
+
+// showSubnetPlannerDialog asks for the subnets to lay out inside container.
+// requirements pre-fills the text area, e.g. when coming back from a preview.
+func (a *App) showSubnetPlannerDialog(container *domain.Network, requirements string) {
+	a.Pages.RemovePage(subnetPlannerPageName)
+
+	form := tview.NewForm().SetButtonsAlign(tview.AlignCenter)
+	form.AddFormItem(newHintedTextArea("Requirements", requirements, FormFieldWidth, 8, subnetPlannerHint))
+
+	cancel := func() { a.dismissDialog(subnetPlannerPageName) }
+	form.AddButton("Preview", func() {
+		text := getTextFromTextAreaIfPresent(form, "Requirements")
+		a.dismissDialog(subnetPlannerPageName)
+		reqs, err := domain.ParseSubnetRequirements(text)
+		if err != nil {
+			a.setStatus("Error planning subnets: " + err.Error())
+			return
+		}
+		plan, err := a.Catalog.PlanSubnets(container, reqs)
+		if err != nil {
+			a.setStatus("Error planning subnets: " + err.Error())
+			return
+		}
+		a.showSubnetPlanPreview(container, plan, text)
+	})
+	form.AddButton("Cancel", cancel)
+
+	form.SetBorder(true).SetTitle("Plan Subnets in " + container.ID)
+	a.wireDialogFormKeys(form, cancel)
+	a.Pages.AddPage(subnetPlannerPageName, a.createDialogPage(form, computeFormDialogWidth(form), computeFormDialogHeight(form)), true, false)
+	a.Pages.ShowPage(subnetPlannerPageName)
+	form.SetFocus(0)
+	a.TviewApp.SetFocus(form)
+}
+
+// showSubnetPlanPreview lists the planned placements and applies them on Enter.
+// Backspace returns to the requirements so they can be adjusted.
+func (a *App) showSubnetPlanPreview(container *domain.Network, plan []domain.PlannedSubnet, requirements string) {
+	var content strings.Builder
+	fmt.Fprintf(&content, "%d subnets in %s (Enter: apply, Backspace: edit, Esc: cancel)\n\n", len(plan), container.ID)
+	for _, p := range plan {
+		mode := "Host Pool"
+		if p.Container {
+			mode = "Subnet Container"
+		}
+		fmt.Fprintf(&content, "%-20s %-16s %-16s VLAN %s\n", p.CIDR, p.Name, mode, a.Catalog.RenderVLANID(p.VLANID))
+	}
+
+	preview := tview.NewTextView().
+		SetText(content.String()).
+		SetScrollable(true).
+		SetWrap(false)
+	preview.SetBorder(true).SetTitle("Subnet Plan Preview")
+	preview.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Key() {
+		case tcell.KeyEnter:
+			a.dismissDialog(subnetPlanPageName)
+			a.ApplySubnetPlan(container, plan)
+			return nil
+		case tcell.KeyBS, tcell.KeyBackspace2:
+			a.dismissDialog(subnetPlanPageName)
+			a.showSubnetPlannerDialog(container, requirements)
+			return nil
+		case tcell.KeyEscape:
+			a.dismissDialog(subnetPlanPageName)
+			return nil
+		}
+		return event
+	})
+
+	a.Pages.RemovePage(subnetPlanPageName)
+	a.Pages.AddPage(subnetPlanPageName, a.createDialogPage(preview, 76, min(len(plan)+5, 20)), true, true)
+	a.Pages.ShowPage(subnetPlanPageName)
+	a.TviewApp.SetFocus(preview)
+}
@@ -29,6 +29,8 @@ func (a *App) onItemChanged(item domain.Item) {
 		a.renderPort(v)
 	case *domain.DNSRecord:
 		a.renderDNSRecord(v)
+	case *domain.DNSZone:
+		a.renderDNSZone(v)
 	default:
 		a.DetailsPanel.Clear()
 		a.CurrentFocusKeys = nil
@@ -51,6 +53,8 @@ func (a *App) onItemSelected(item domain.Item) {
 		}
 	case *domain.Equipment:
 		a.CurrentMenuItemKeys = []string{"<p> New Port"}
+	case *domain.DNSZone:
+		a.CurrentMenuItemKeys = []string{"<r> New Record"}
 	default:
 		a.CurrentMenuItemKeys = []string{}
 	}
@@ -67,7 +71,7 @@ func (a *App) onItemDone(item domain.Item) {
 func (a *App) staticFolderMenuKeys(sf *domain.StaticFolder) []string {
 	switch sf.ID {
 	case domain.FolderNetworks:
-		return []string{"<n> New Network"}
+		return []string{"<n> New Network", "<F> Free Space"}
 	case domain.FolderVLANs:
 		return []string{"<v> New VLAN"}
 	case domain.FolderSSIDs:
@@ -77,7 +81,7 @@ func (a *App) staticFolderMenuKeys(sf *domain.StaticFolder) []string {
 	case domain.FolderEquipment:
 		return []string{"<e> New Equipment"}
 	case domain.FolderDNS:
-		return []string{"<r> New Record"}
+		return []string{"<r> New Record", "<z> New Zone"}
 	default:
 		return nil
 	}
@@ -105,6 +109,16 @@ func (a *App) renderNetwork(n *domain.Network) {
 		a.CurrentFocusKeys = []string{
 			"<u> Update Metadata",
 			"<d> Deallocate",
+			"<g> Resize",
+			"<M> Move",
+			"<N> Renumber",
+			"<c> Clone",
+		}
+		if n.AllocationMode == domain.AllocationModeSubnets {
+			a.CurrentFocusKeys = append(a.CurrentFocusKeys, "<m> Address Map", "<f> Free Space", "<P> Plan Subnets")
+		}
+		if n.AllocationMode == domain.AllocationModeHosts {
+			a.CurrentFocusKeys = append(a.CurrentFocusKeys, "<o> DHCP Options")
 		}
 	} else {
 		a.CurrentFocusKeys = []string{
@@ -127,14 +141,18 @@ func (a *App) renderIP(ip *domain.IP) {
 	if description == "" {
 		description = "<none>"
 	}
-	a.DetailsPanel.SetText(fmt.Sprintf(
+	details := fmt.Sprintf(
 		"IP Address           : %s\nDisplay Name         : %s\nMAC Address          : %s\nDescription          : %s\nParent Network       : %s\n",
 		ip.ID,
 		ip.DisplayName,
 		renderedOrNone(strings.TrimSpace(ip.MACAddress)),
 		description,
 		ip.GetParentPath(),
-	))
+	)
+	if warning := ip.DHCPWarning(a.Catalog); warning != "" {
+		details += fmt.Sprintf("Warning              : %s\n", warning)
+	}
+	a.DetailsPanel.SetText(details)
 	a.CurrentFocusKeys = []string{
 		"<u> Update Reservation",
 		"<R> Unreserve",
@@ -144,22 +162,30 @@ func (a *App) renderIP(ip *domain.IP) {
 func (a *App) renderDNSRecord(record *domain.DNSRecord) {
 	details := new(strings.Builder)
 	fmt.Fprintf(details, "FQDN                 : %s\n", record.ID)
-	if strings.TrimSpace(record.ReservedIPPath) != "" {
-		aliasValue := "<missing>"
-		item := a.Catalog.Get(record.ReservedIPPath)
-		if ip, ok := item.(*domain.IP); ok {
+	if record.IsAlias() {
+		values := []string{}
+		for _, ip := range a.Catalog.AliasTargets(record) {
 			if strings.TrimSpace(ip.MACAddress) != "" {
-				aliasValue = fmt.Sprintf("%s (%s %s)", ip.ID, ip.DisplayName, ip.MACAddress)
+				values = append(values, fmt.Sprintf("%s (%s %s)", ip.ID, ip.DisplayName, ip.MACAddress))
 			} else {
-				aliasValue = fmt.Sprintf("%s (%s)", ip.ID, ip.DisplayName)
+				values = append(values, fmt.Sprintf("%s (%s)", ip.ID, ip.DisplayName))
 			}
 		}
-		fmt.Fprintf(details, "Type                 : Alias\n")
+		if len(values) < len(record.AliasTargetPaths()) || len(values) == 0 {
+			values = append(values, "<missing>")
+		}
+		aliasValue := strings.Join(values, ", ")
+		if host := strings.TrimSpace(record.ReservedHost); host != "" {
+			fmt.Fprintf(details, "Type                 : Alias (host %s)\n", host)
+		} else {
+			fmt.Fprintf(details, "Type                 : Alias\n")
+		}
 		fmt.Fprintf(details, "Value                : %s\n", aliasValue)
 	} else {
 		fmt.Fprintf(details, "Type                 : %s\n", renderedOrNone(strings.TrimSpace(record.RecordType)))
 		fmt.Fprintf(details, "Value                : %s\n", renderedOrNone(strings.TrimSpace(record.RecordValue)))
 	}
+	fmt.Fprintf(details, "TTL                  : %s\n", renderedOrNone(formatOptionalTTL(record.TTL)))
 	fmt.Fprintf(details, "Description          : %s\n", renderedOrNone(strings.TrimSpace(record.Description)))
 
 	a.DetailsPanel.Clear()
@@ -170,6 +196,23 @@ func (a *App) renderDNSRecord(record *domain.DNSRecord) {
 	}
 }
 
+func (a *App) renderDNSZone(zone *domain.DNSZone) {
+	details := new(strings.Builder)
+	fmt.Fprintf(details, "Zone                 : %s\n", zone.ID)
+	fmt.Fprintf(details, "Default TTL          : %s\n", renderedOrNone(formatOptionalTTL(zone.TTL)))
+	fmt.Fprintf(details, "SOA Contact          : %s\n", renderedOrNone(strings.TrimSpace(zone.Contact)))
+	fmt.Fprintf(details, "Name Servers         : %s\n", renderedOrNone(strings.Join(zone.NameServers, ", ")))
+	fmt.Fprintf(details, "Records              : %d\n", len(a.Catalog.GetChildren(zone)))
+	fmt.Fprintf(details, "Description          : %s\n", renderedOrNone(strings.TrimSpace(zone.Description)))
+
+	a.DetailsPanel.Clear()
+	a.DetailsPanel.SetText(details.String())
+	a.CurrentFocusKeys = []string{
+		"<u> Update Zone",
+		"<D> Delete Zone",
+	}
+}
+
 func (a *App) renderVLAN(v *domain.VLAN) {
 	details := new(strings.Builder)
 	fmt.Fprintf(details, "VLAN ID              : %s\n", v.ID)
@@ -421,7 +464,7 @@ func (a *App) hasAnySummarizableRange(network *domain.Network) bool {
 func (a *App) getUnallocatedSiblingNetworks(network *domain.Network) []*domain.Network {
 	parent := a.Catalog.Get(network.GetParentPath())
 	unallocated := []*domain.Network{}
-	for _, sibling := range a.Catalog.GetChildren(parent) {
+	for _, sibling := range a.Catalog.ChildrenWithFreeSpace(parent) {
 		n, ok := sibling.(*domain.Network)
 		if !ok {
 			continue
@@ -0,0 +1,87 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+
+	"github.com/plumber-cd/ez-ipam/internal/domain"
+)
+
+const (
+	renumberNetworkPageName = "*renumber_network*"
+	renumberPreviewPageName = "*renumber_preview*"
+)
+
+// showRenumberDialog asks for the new CIDR of an allocated network.
+// newCIDR pre-fills the field, e.g. when coming back from a preview.
+func (a *App) showRenumberDialog(n *domain.Network, newCIDR string) {
+	a.Pages.RemovePage(renumberNetworkPageName)
+
+	form := tview.NewForm().SetButtonsAlign(tview.AlignCenter)
+	form.AddInputField("New CIDR", newCIDR, FormFieldWidth, nil, nil)
+
+	cancel := func() { a.dismissDialog(renumberNetworkPageName) }
+	form.AddButton("Preview", func() {
+		text := strings.TrimSpace(form.GetFormItemByLabel("New CIDR").(*tview.InputField).GetText())
+		a.dismissDialog(renumberNetworkPageName)
+		changes, err := a.Catalog.PlanRenumber(n, text)
+		if err != nil {
+			a.setStatus("Error renumbering network: " + err.Error())
+			return
+		}
+		a.showRenumberPreview(n, changes, text)
+	})
+	form.AddButton("Cancel", cancel)
+
+	form.SetBorder(true).SetTitle("Renumber " + n.ID)
+	a.wireDialogFormKeys(form, cancel)
+	a.Pages.AddPage(renumberNetworkPageName, a.createDialogPage(form, computeFormDialogWidth(form), computeFormDialogHeight(form)), true, false)
+	a.Pages.ShowPage(renumberNetworkPageName)
+	form.SetFocus(0)
+	a.TviewApp.SetFocus(form)
+}
+
+// showRenumberPreview lists every old → new rewrite and applies them on Enter.
+// Backspace returns to the CIDR input so it can be adjusted.
+func (a *App) showRenumberPreview(n *domain.Network, changes []domain.AddressChange, newCIDR string) {
+	var content strings.Builder
+	fmt.Fprintf(&content, "%d changes (Enter: apply, Backspace: edit, Esc: cancel)\n\n", len(changes))
+	dnsPrefix := domain.FolderDNS + " -> "
+	for _, change := range changes {
+		label := ""
+		if record, ok := strings.CutPrefix(change.Path, dnsPrefix); ok {
+			label = "DNS " + record
+		}
+		fmt.Fprintf(&content, "%-26s → %-26s %s\n", change.Old, change.New, label)
+	}
+
+	preview := tview.NewTextView().
+		SetText(content.String()).
+		SetScrollable(true).
+		SetWrap(false)
+	preview.SetBorder(true).SetTitle("Renumber " + n.ID + " to " + newCIDR)
+	preview.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Key() {
+		case tcell.KeyEnter:
+			a.dismissDialog(renumberPreviewPageName)
+			a.RenumberNetwork(newCIDR)
+			return nil
+		case tcell.KeyBS, tcell.KeyBackspace2:
+			a.dismissDialog(renumberPreviewPageName)
+			a.showRenumberDialog(n, newCIDR)
+			return nil
+		case tcell.KeyEscape:
+			a.dismissDialog(renumberPreviewPageName)
+			return nil
+		}
+		return event
+	})
+
+	a.Pages.RemovePage(renumberPreviewPageName)
+	a.Pages.AddPage(renumberPreviewPageName, a.createDialogPage(preview, 76, min(len(changes)+5, 20)), true, true)
+	a.Pages.ShowPage(renumberPreviewPageName)
+	a.TviewApp.SetFocus(preview)
+}
@@ -0,0 +1,283 @@
+package ui
+
+import (
+	"fmt"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+
+	"github.com/plumber-cd/ez-ipam/internal/domain"
+)
+
+const (
+	navListPageName = "*nav_list*"
+	navTreePageName = "*nav_tree*"
+)
+
+// isInNetworksHierarchy reports whether item is the Networks folder or lives below it.
+func (a *App) isInNetworksHierarchy(item domain.Item) bool {
+	switch v := item.(type) {
+	case *domain.StaticFolder:
+		return v.ID == domain.FolderNetworks
+	case *domain.Network, *domain.IP:
+		return true
+	}
+	return false
+}
+
+// toggleTreeMode switches the nav panel between the flat list and the Networks tree.
+func (a *App) toggleTreeMode() {
+	if a.treeMode {
+		a.exitTreeMode()
+		return
+	}
+	if a.CurrentItem == nil || !a.isInNetworksHierarchy(a.CurrentItem) {
+		a.setStatus("Tree view is available only inside Networks.")
+		return
+	}
+	a.enterTreeMode()
+}
+
+func (a *App) enterTreeMode() {
+	a.treeMode = true
+	a.treeExpanded = map[string]bool{}
+	focus := a.CurrentFocus
+	if focus == nil || !a.isInNetworksHierarchy(focus) {
+		focus = a.CurrentItem
+	}
+	a.reloadTree(focus)
+	a.NavPages.SwitchToPage(navTreePageName)
+	a.TviewApp.SetFocus(a.NavTree)
+	a.setStatus("Tree view: Enter/Space expand or collapse, h/l collapse/expand, t back to list.")
+}
+
+func (a *App) exitTreeMode() {
+	a.treeMode = false
+	focus := a.CurrentFocus
+	if focus != nil {
+		a.CurrentItem = a.Catalog.Get(focus.GetParentPath())
+	}
+	a.NavPages.SwitchToPage(navListPageName)
+	a.ReloadMenu(focus)
+	if a.CurrentItem == nil {
+		a.PositionLine.Clear()
+		a.PositionLine.SetText("Home")
+		a.CurrentMenuItemKeys = []string{}
+	} else {
+		a.onItemSelected(a.CurrentItem)
+	}
+	a.UpdateKeysLine()
+	a.TviewApp.SetFocus(a.NavPanel)
+}
+
+// reloadTree rebuilds the Networks tree, keeping expansion state and selecting focusedItem.
+func (a *App) reloadTree(focusedItem domain.Item) {
+	if a.usage == nil {
+		a.usage = a.Catalog.NewUtilizationIndex()
+		defer func() { a.usage = nil }()
+	}
+	// Expand every ancestor of the focused item so it is visible in the tree.
+	if focusedItem != nil {
+		for path := focusedItem.GetParentPath(); path != ""; {
+			a.treeExpanded[path] = true
+			parent := a.Catalog.Get(path)
+			if parent == nil {
+				break
+			}
+			path = parent.GetParentPath()
+		}
+	}
+
+	networksFolder := a.Catalog.GetByParentAndDisplayID(nil, domain.FolderNetworks)
+	if networksFolder == nil {
+		return
+	}
+	a.treeExpanded[networksFolder.GetPath()] = true
+	root := a.buildTreeNode(networksFolder)
+	a.NavTree.SetRoot(root)
+
+	nodes := map[string]*tview.TreeNode{}
+	root.Walk(func(node, _ *tview.TreeNode) bool {
+		if path, ok := node.GetReference().(string); ok {
+			nodes[path] = node
+		}
+		return true
+	})
+
+	// Fall back to the nearest surviving ancestor when the item itself is gone (e.g. after split).
+	current := root
+	if focusedItem != nil {
+		path, parentPath := focusedItem.GetPath(), focusedItem.GetParentPath()
+		for path != "" {
+			if node, ok := nodes[path]; ok {
+				current = node
+				break
+			}
+			path = parentPath
+			parent := a.Catalog.Get(path)
+			if parent == nil {
+				break
+			}
+			parentPath = parent.GetParentPath()
+		}
+	}
+	a.NavTree.SetCurrentNode(current)
+	a.onTreeNodeChanged(current)
+}
+
+func (a *App) buildTreeNode(item domain.Item) *tview.TreeNode {
+	node := tview.NewTreeNode(tview.Escape(a.treeNodeLabel(item))).SetReference(item.GetPath())
+	if n, ok := item.(*domain.Network); ok && n.AllocationMode == domain.AllocationModeUnallocated {
+		node.SetColor(tcell.ColorGray)
+	}
+	if _, exhausted := a.networkUsageLabel(item); exhausted {
+		node.SetColor(tcell.ColorRed)
+	}
+	// Children are built on first expansion so a reload only walks the
+	// expanded part of the hierarchy.
+	if a.treeExpanded[item.GetPath()] {
+		a.addTreeChildren(node, item)
+	}
+	node.SetExpanded(a.treeExpanded[item.GetPath()])
+	node.SetSelectedFunc(func() {
+		a.setTreeNodeExpanded(node, !node.IsExpanded())
+	})
+	return node
+}
+
+func (a *App) addTreeChildren(node *tview.TreeNode, item domain.Item) {
+	for _, child := range a.Catalog.ChildrenWithFreeSpace(item) {
+		node.AddChild(a.buildTreeNode(child))
+	}
+}
+
+func (a *App) setTreeNodeExpanded(node *tview.TreeNode, expanded bool) {
+	path, ok := node.GetReference().(string)
+	if !ok {
+		return
+	}
+	if expanded && len(node.GetChildren()) == 0 {
+		if item := a.Catalog.Lookup(path); item != nil {
+			a.usage = a.Catalog.NewUtilizationIndex()
+			a.addTreeChildren(node, item)
+			a.usage = nil
+		}
+	}
+	node.SetExpanded(expanded)
+	a.treeExpanded[path] = expanded
+}
+
+// treeNodeLabel renders a node label with allocation mode, name and utilization inline.
+func (a *App) treeNodeLabel(item domain.Item) string {
+	n, ok := item.(*domain.Network)
+	if !ok {
+		return item.DisplayID()
+	}
+	usage, _ := a.networkUsageLabel(n)
+	switch n.AllocationMode {
+	case domain.AllocationModeSubnets:
+		return fmt.Sprintf("%s [Container] %s (%s)", n.ID, n.DisplayName, usage)
+	case domain.AllocationModeHosts:
+		return fmt.Sprintf("%s [Pool] %s (%s)", n.ID, n.DisplayName, usage)
+	default:
+		return fmt.Sprintf("%s [Free]", n.ID)
+	}
+}
+
+// treeMenuItem returns the item that acts as the "current menu" for a tree node.
+// Host pools and the Networks folder are their own menu so that menu keys
+// (reserve IP, new network) apply to the highlighted node.
+func (a *App) treeMenuItem(item domain.Item) domain.Item {
+	switch v := item.(type) {
+	case *domain.StaticFolder:
+		return v
+	case *domain.Network:
+		if v.AllocationMode == domain.AllocationModeHosts {
+			return v
+		}
+	}
+	return a.Catalog.Get(item.GetParentPath())
+}
+
+func (a *App) onTreeNodeChanged(node *tview.TreeNode) {
+	path, ok := node.GetReference().(string)
+	if !ok {
+		return
+	}
+	item := a.Catalog.Lookup(path)
+	if item == nil {
+		return
+	}
+	a.CurrentFocus = item
+	a.CurrentItem = a.treeMenuItem(item)
+	if a.CurrentItem != nil {
+		a.onItemSelected(a.CurrentItem)
+	}
+	a.PositionLine.Clear()
+	a.PositionLine.SetText(item.GetPath())
+	a.onItemChanged(item)
+	a.UpdateKeysLine()
+}
+
+// setupTree wires the tree view callbacks and key handling.
+func (a *App) setupTree() {
+	a.NavTree.SetChangedFunc(func(node *tview.TreeNode) {
+		a.onTreeNodeChanged(node)
+	})
+
+	a.NavTree.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		node := a.NavTree.GetCurrentNode()
+		collapse := func() {
+			if node == nil {
+				return
+			}
+			if node.IsExpanded() && len(node.GetChildren()) > 0 {
+				a.setTreeNodeExpanded(node, false)
+				return
+			}
+			// Already collapsed: jump to the parent node.
+			path := a.NavTree.GetPath(node)
+			if len(path) > 1 {
+				a.NavTree.SetCurrentNode(path[len(path)-2])
+				a.onTreeNodeChanged(path[len(path)-2])
+			}
+		}
+		expand := func() {
+			if node != nil {
+				a.setTreeNodeExpanded(node, true)
+			}
+		}
+
+		switch event.Key() {
+		case tcell.KeyBS, tcell.KeyBackspace2, tcell.KeyLeft:
+			collapse()
+			return nil
+		case tcell.KeyRight:
+			expand()
+			return nil
+		case tcell.KeyEscape:
+			a.exitTreeMode()
+			return nil
+		case tcell.KeyCtrlU:
+			return tcell.NewEventKey(tcell.KeyPgUp, 0, tcell.ModNone)
+		case tcell.KeyCtrlD:
+			return tcell.NewEventKey(tcell.KeyPgDn, 0, tcell.ModNone)
+		case tcell.KeyRune:
+			switch event.Rune() {
+			case 'h':
+				collapse()
+				return nil
+			case 'l':
+				expand()
+				return nil
+			case 't':
+				a.exitTreeMode()
+				return nil
+			case 'j', 'k':
+				return event
+			}
+		}
+
+		return a.handleNavKeys(event)
+	})
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 
+	"github.com/plumber-cd/ez-ipam/internal/cli"
 	"github.com/plumber-cd/ez-ipam/internal/ui"
 )
 
@@ -14,6 +15,14 @@ func main() {
 		os.Exit(1)
 	}
 
+	if len(os.Args) > 1 {
+		if err := cli.Run(currentDir, os.Args[1:], os.Stdout, os.Stderr); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	application, err := ui.New(currentDir)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Failed to initialize application: %v\n", err)